@@ -8,6 +8,7 @@ import (
 
 	"github.com/dsbezerra/amenic-lambda/src/contracts"
 	"github.com/dsbezerra/amenic-lambda/src/lib/messagequeue"
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
@@ -120,7 +121,7 @@ type CommandService struct {
 func (rs *Service) ServeCommands(r *gin.Engine) {
 	s := &CommandService{rs.data, rs.emitter}
 
-	commands := r.Group("/commands")
+	commands := r.Group("/commands", rest.AdminAuth(rs.data))
 	commands.GET("/", s.GetAll)
 	commands.POST("/", s.RunCommand)
 }
@@ -0,0 +1,31 @@
+package rest
+
+import (
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/gin-gonic/gin"
+)
+
+// ServeNotifications ...
+func (rs *Service) ServeNotifications(r *gin.Engine) {
+	s := &CommandService{rs.data, rs.emitter}
+
+	// Sending notifications only needs the notifications:send scope.
+	notifications := r.Group("/notifications", rest.ScopeAuth(rs.data, models.ScopeNotificationsSend))
+	notifications.POST("/send", s.SendNotifications)
+}
+
+// SendNotifications dispatches the check of opening movies, which notifies
+// users about the movies opening this week.
+func (s *CommandService) SendNotifications(c *gin.Context) {
+	cmd := models.Command{Name: models.CommandCheckOpeningMovies}
+	if running.isRunning(cmd) {
+		apiutil.SendSuccessOrError(c, "command already running", nil)
+		return
+	}
+
+	go run(s, cmd)
+
+	apiutil.SendSuccess(c, "command started")
+}
@@ -18,8 +18,9 @@ func ServeAPI(r *gin.Engine, data persistence.DataAccessLayer, emitter messagequ
 	s := &Service{data, emitter}
 
 	// Apply default middlewares
-	r.Use(rest.Init(), rest.AdminAuth(data))
+	r.Use(rest.Init())
 
 	// AdminService routes.
 	s.ServeCommands(r)
+	s.ServeNotifications(r)
 }
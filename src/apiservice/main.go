@@ -13,6 +13,7 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	helmet "github.com/danielkov/gin-helmet"
@@ -27,7 +28,10 @@ import (
 	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares"
 	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/mongolayer"
+	"github.com/dsbezerra/amenic-lambda/src/lib/ratelimit"
+	"github.com/dsbezerra/amenic-lambda/src/lib/tokens"
 	"github.com/gin-gonic/gin"
 )
 
@@ -35,6 +39,21 @@ const (
 	ServiceName = "API"
 )
 
+// defaultRateLimits are the rate limit rules of API routes. Aggregation
+// endpoints are the most expensive ones, so they have lower limits.
+var defaultRateLimits = ratelimit.Rules{
+	{UserType: models.UserTypeAdmin, Limit: ratelimit.PerMinute(1200)},
+	{UserType: models.UserTypeClient, Limit: ratelimit.PerMinute(300)},
+	{UserType: models.UserTypeClient, Platform: "web", Limit: ratelimit.PerMinute(120)},
+	{UserType: models.UserTypeClient, Route: "/v2/graphql", Limit: ratelimit.PerMinute(60)},
+	{UserType: models.UserTypeClient, Route: "/v2/movies/now_playing", Limit: ratelimit.PerMinute(120)},
+	{UserType: models.UserTypeClient, Route: "/v2/schedules", Limit: ratelimit.PerMinute(120)},
+	{UserType: models.UserTypeClient, Route: "/v2/sessions", Limit: ratelimit.PerMinute(120)},
+}
+
+// defaultRateLimitIP is the rate limit of requests by IP.
+var defaultRateLimitIP = ratelimit.PerMinute(600)
+
 // Context ...
 type Context struct {
 	Service string
@@ -45,18 +64,32 @@ type Context struct {
 var appCtx *Context
 var ginLambda *ginadapter.GinLambda
 
-// StaticHandler is used to serve .json files from amenic-static bucket
-func StaticHandler(ctx context.Context, proxy string) (events.APIGatewayProxyResponse, error) {
+// StaticHandler is used to serve .json files from amenic-static bucket. Requests
+// with the ETag of the file in If-None-Match get 304 Not Modified.
+func StaticHandler(ctx context.Context, req events.APIGatewayProxyRequest, proxy string) (events.APIGatewayProxyResponse, error) {
 	sess, err := session.NewSession(&aws.Config{Region: aws.String("sa-east-1")})
 	if err != nil {
 		return events.APIGatewayProxyResponse{Body: "Internal Server Error", StatusCode: 500}, nil
 	}
 	svc := s3.New(sess)
-	resp, err := svc.GetObject(&s3.GetObjectInput{
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(os.Getenv("STATIC_BUCKET_NAME")),
 		Key:    aws.String(proxy),
-	})
+	}
+	if etag := requestHeader(req, "If-None-Match"); etag != "" {
+		input.IfNoneMatch = aws.String(etag)
+	}
+	resp, err := svc.GetObject(input)
 	if err != nil {
+		if rerr, ok := err.(awserr.RequestFailure); ok && rerr.StatusCode() == http.StatusNotModified {
+			return events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotModified,
+				Headers: map[string]string{
+					"Cache-Control": middlewares.StaticCacheControl,
+					"Etag":          requestHeader(req, "If-None-Match"),
+				},
+			}, nil
+		}
 		return events.APIGatewayProxyResponse{Body: "Internal Server Error", StatusCode: 500}, nil
 	}
 
@@ -67,7 +100,8 @@ func StaticHandler(ctx context.Context, proxy string) (events.APIGatewayProxyRes
 
 	headers := map[string]string{
 		"Content-Type":   "application/json",
-		"Last-Modified":  resp.LastModified.String(),
+		"Cache-Control":  middlewares.StaticCacheControl,
+		"Last-Modified":  resp.LastModified.UTC().Format(http.TimeFormat),
 		"Etag":           *resp.ETag,
 		"Content-Length": fmt.Sprintf("%d", *resp.ContentLength),
 	}
@@ -79,13 +113,24 @@ func StaticHandler(ctx context.Context, proxy string) (events.APIGatewayProxyRes
 	}, nil
 }
 
+// requestHeader returns the value of a request header. API Gateway keeps the
+// case sent by clients.
+func requestHeader(req events.APIGatewayProxyRequest, name string) string {
+	for k, v := range req.Headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
 // Handler ...
 func Handler(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	// Check for static urls here (for now only json files are treated like static files)
 	if proxy := req.PathParameters["proxy"]; proxy == "" {
 		return events.APIGatewayProxyResponse{Body: "Hello!", StatusCode: 200}, nil
 	} else if strings.HasSuffix(proxy, ".json") {
-		return StaticHandler(ctx, proxy)
+		return StaticHandler(ctx, req, proxy)
 	}
 
 	if ginLambda == nil {
@@ -155,6 +200,7 @@ func (ctx *Context) buildRouter() *gin.Engine {
 	} else {
 		router.Use(rest.Init())
 	}
+	router.Use(ctx.rateLimit())
 
 	// Setup route handlers.
 	if !ctx.Config.AWSLambda {
@@ -163,12 +209,39 @@ func (ctx *Context) buildRouter() *gin.Engine {
 		})
 	}
 
+	rest.KeyData = ctx.Data
+	if ctx.Config.JWTAlgorithm != config.JWTAlgorithmHS256 {
+		rest.Keys = tokens.NewManager(ctx.Data, tokens.Options{
+			Algorithm: ctx.Config.JWTAlgorithm,
+			Rotation:  ctx.Config.JWTKeyRotation,
+			Secret:    ctx.Config.JWTKeySecret,
+		})
+	}
 	v1.AddRoutes(router, ctx.Data)
-	v2.AddRoutes(router, ctx.Data)
+	v2.AddRoutes(router, ctx.Data, ctx.Config.CacheMaxAge)
 
 	return router
 }
 
+// rateLimit builds the rate limit middleware from the configuration.
+func (ctx *Context) rateLimit() gin.HandlerFunc {
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if ctx.Config.RateLimitStore == config.RateLimitStoreMongo {
+		store = ratelimit.NewDataStore(ctx.Data)
+	}
+	opts := middlewares.RateLimitOptions{
+		Store:    store,
+		Rules:    defaultRateLimits.Merge(ctx.Config.RateLimits),
+		Default:  ratelimit.PerMinute(300),
+		IP:       defaultRateLimitIP,
+		Identify: rest.Identify(ctx.Data),
+	}
+	if !ctx.Config.RateLimitIP.IsZero() {
+		opts.IP = ctx.Config.RateLimitIP
+	}
+	return middlewares.RateLimit(opts)
+}
+
 // Mode ...
 func (ctx *Context) Mode() string {
 	return os.Getenv("AMENIC_MODE")
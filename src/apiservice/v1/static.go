@@ -47,7 +47,6 @@ const (
 // normal   - an ordinary movie session.
 // premiere - movie's sessions in the same day as release.
 // preview  - movie's sessions before its release.
-//
 type SessionType uint
 
 const (
@@ -267,20 +266,12 @@ func createStaticNowPlaying(data persistence.DataAccessLayer) (*StaticFile, erro
 		//
 		// 6 september 2018
 		sessionType := SessionTypeNormal
-		if customSessionType && movie.ReleaseDate != nil {
-			maxDate := movie.ReleaseDate.AddDate(0, 0, 7)
-
-			for _, session := range movie.Sessions {
-				if session.StartTime.Before(*movie.ReleaseDate) && now.Before(*movie.ReleaseDate) {
-					sessionType = SessionTypePreview
-					break
-				} else {
-					// If session is exactly in the premiere week let's set this as premiere.
-					if now.After(*movie.ReleaseDate) && now.Before(maxDate) {
-						sessionType = SessionTypePremiere
-						// NOTE(diego): Not breaking here because sessions may be in any order.
-					}
-				}
+		if customSessionType {
+			switch models.GetSessionsType(movie.Sessions, &movie) {
+			case models.SessionTypePreview:
+				sessionType = SessionTypePreview
+			case models.SessionTypePremiere:
+				sessionType = SessionTypePremiere
 			}
 		}
 
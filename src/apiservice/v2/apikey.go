@@ -0,0 +1,247 @@
+package v2
+
+import (
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKeyService ...
+type APIKeyService struct {
+	data persistence.DataAccessLayer
+}
+
+// APIKeyBody is the body of requests creating API keys.
+type APIKeyBody struct {
+	Name      string     `json:"name"`
+	UserType  string     `json:"user_type"`
+	Platform  string     `json:"platform"`
+	Platforms []string   `json:"platforms"`
+	Role      string     `json:"role"`
+	Scopes    []string   `json:"scopes"`
+	Owner     string     `json:"owner"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ScopesBody is the body of requests changing the scopes of API keys.
+type ScopesBody struct {
+	Role   string   `json:"role"`
+	Scopes []string `json:"scopes"`
+}
+
+// ExpireBody is the body of requests expiring API keys.
+type ExpireBody struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// NewAPIKey is an API key with its plain text key. Keys are only shown when
+// created or rotated.
+type NewAPIKey struct {
+	*models.APIKey
+	Key string `json:"key"`
+}
+
+// ServeAPIKeys ...
+func (r *RESTService) ServeAPIKeys(rg *gin.RouterGroup) {
+	s := &APIKeyService{r.data}
+
+	admin := rg.Group("/apikeys", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}), ValidateRequest())
+	admin.GET("", s.GetAll)
+	admin.POST("", s.Create)
+	admin.GET("/apikey/:id", s.Get)
+	admin.POST("/apikey/:id/rotate", s.Rotate)
+	admin.POST("/apikey/:id/expire", s.Expire)
+	admin.POST("/apikey/:id/revoke", s.Revoke)
+	admin.POST("/apikey/:id/scopes", s.SetScopes)
+}
+
+// GetAll lists API keys, filtered by ?owner, ?user_type and ?platform.
+// Revoked keys are only listed with ?revoked=true.
+func (s *APIKeyService) GetAll(c *gin.Context) {
+	query := s.data.DefaultQuery().SetSort("-iat").SetLimit(-1)
+	for _, field := range []string{"owner", "user_type", "platform"} {
+		if v := c.Query(field); v != "" {
+			query.AddCondition(field, v)
+		}
+	}
+	if c.Query("revoked") != "true" {
+		query.AddCondition("revoked_at", bson.M{"$exists": false})
+	}
+	apikeys, err := s.data.GetAPIKeys(query)
+	apiutil.SendSuccessOrError(c, apikeys, err)
+}
+
+// Get gets the API key with the given ID.
+func (s *APIKeyService) Get(c *gin.Context) {
+	apikey, err := s.data.GetAPIKey(c.Param("id"), s.data.DefaultQuery())
+	apiutil.SendSuccessOrError(c, apikey, err)
+}
+
+// Create creates an API key. The key is only shown in the response. Owner
+// defaults to the owner of the admin key.
+func (s *APIKeyService) Create(c *gin.Context) {
+	var body APIKeyBody
+	if err := c.ShouldBindJSON(&body); err != nil || !isValidAPIKeyBody(&body) {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	if body.Owner == "" {
+		if claims := rest.GetClaims(c); claims != nil {
+			if owner, err := s.data.GetAPIKey(claims.Key, s.data.DefaultQuery()); err == nil {
+				body.Owner = owner.Owner
+			}
+		}
+	}
+
+	apikey := &models.APIKey{
+		ID:        primitive.NewObjectID(),
+		Name:      body.Name,
+		UserType:  body.UserType,
+		Platform:  body.Platform,
+		Platforms: body.Platforms,
+		Role:      body.Role,
+		Scopes:    body.Scopes,
+		Owner:     body.Owner,
+		ExpiresAt: body.ExpiresAt,
+	}
+	key, err := setNewKey(apikey)
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	err = s.data.InsertAPIKey(*apikey)
+	apiutil.SendSuccessOrError(c, NewAPIKey{apikey, key}, err)
+}
+
+// Rotate replaces the key with a new one. Tokens issued before are no longer
+// valid.
+func (s *APIKeyService) Rotate(c *gin.Context) {
+	s.update(c, func(apikey *models.APIKey, now time.Time) (interface{}, bool) {
+		if apikey.RevokedAt != nil {
+			return nil, false
+		}
+		key, err := setNewKey(apikey)
+		if err != nil {
+			return nil, false
+		}
+		apikey.RotatedAt = &now
+		return NewAPIKey{apikey, key}, true
+	})
+}
+
+// Expire sets the expiry time of the key, now by default. Other times must be
+// in the future.
+func (s *APIKeyService) Expire(c *gin.Context) {
+	var body ExpireBody
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil || !isValidExpiry(body.ExpiresAt) {
+			apiutil.SendBadRequest(c)
+			return
+		}
+	}
+	s.update(c, func(apikey *models.APIKey, now time.Time) (interface{}, bool) {
+		apikey.ExpiresAt = body.ExpiresAt
+		if apikey.ExpiresAt == nil {
+			apikey.ExpiresAt = &now
+		}
+		return apikey, true
+	})
+}
+
+// Revoke revokes the key and the tokens issued with it.
+func (s *APIKeyService) Revoke(c *gin.Context) {
+	s.update(c, func(apikey *models.APIKey, now time.Time) (interface{}, bool) {
+		if apikey.RevokedAt == nil {
+			apikey.RevokedAt = &now
+		}
+		return apikey, true
+	})
+}
+
+// SetScopes replaces the role and scopes of the key. Tokens issued before
+// lose the removed scopes when refreshed, added scopes are only granted on
+// the next login.
+func (s *APIKeyService) SetScopes(c *gin.Context) {
+	var body ScopesBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	s.update(c, func(apikey *models.APIKey, now time.Time) (interface{}, bool) {
+		if !isValidScopes(apikey.UserType, body.Role, body.Scopes) {
+			return nil, false
+		}
+		apikey.Role = body.Role
+		apikey.Scopes = body.Scopes
+		return apikey, true
+	})
+}
+
+// update applies the change to the API key with the given ID, saves it and
+// removes it from memory. Changes returning false are bad requests.
+func (s *APIKeyService) update(c *gin.Context, change func(apikey *models.APIKey, now time.Time) (interface{}, bool)) {
+	apikey, err := s.data.GetAPIKey(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	result, ok := change(apikey, time.Now().UTC())
+	if !ok {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	if _, err := s.data.UpdateAPIKey(c.Param("id"), *apikey); err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	rest.InvalidateAPIKey(apikey.ID)
+	apiutil.SendSuccess(c, result)
+}
+
+// setNewKey generates a key for the API key and returns it.
+func setNewKey(apikey *models.APIKey) (string, error) {
+	key, err := models.GenerateAPIKey()
+	if err != nil {
+		return "", err
+	}
+	return key, apikey.SetKey(key)
+}
+
+func isValidAPIKeyBody(body *APIKeyBody) bool {
+	if body.Name == "" {
+		return false
+	}
+	if body.UserType != models.UserTypeAdmin && body.UserType != models.UserTypeClient {
+		return false
+	}
+	if !isValidScopes(body.UserType, body.Role, body.Scopes) {
+		return false
+	}
+	return isValidExpiry(body.ExpiresAt)
+}
+
+// isValidExpiry checks whether the expiry time, which can be empty, is in the
+// future.
+func isValidExpiry(expiresAt *time.Time) bool {
+	return expiresAt == nil || expiresAt.After(time.Now())
+}
+
+// isValidScopes checks whether the role, which can be empty, and the scopes
+// exist and can be granted to keys of the user type.
+func isValidScopes(userType, role string, scopes []string) bool {
+	if role != "" && (!models.IsRole(role) || !models.AllowsRole(userType, role)) {
+		return false
+	}
+	for _, s := range scopes {
+		if !models.IsScope(s) || !models.AllowsScope(userType, s) {
+			return false
+		}
+	}
+	return true
+}
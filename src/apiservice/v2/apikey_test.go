@@ -0,0 +1,136 @@
+package v2
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares"
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAPIKeyExpire(t *testing.T) {
+	data := NewMockDataAccessLayer()
+
+	r := NewMockRouter(data)
+	r.Use(rest.Init(), middlewares.ValidObjectIDHex())
+
+	s := RESTService{data: data}
+	s.ServeAPIKeys(&r.RouterGroup)
+
+	adminAuthToken := getAdminAuthToken(t)
+
+	var apikey NewAPIKey
+	res := r.Call("POST", "/apikeys", `{"name": "Test", "user_type": "client", "scopes": ["api_read"]}`, adminAuthToken)
+	if !assert.Equal(t, http.StatusOK, res.Code) {
+		return
+	}
+	ConvertAPIResponse(res, &apikey)
+	defer data.DeleteAPIKey(apikey.ID.Hex())
+
+	url := fmt.Sprintf("/apikeys/apikey/%s/expire", apikey.ID.Hex())
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	cases := []apiTestCase{
+		{
+			name:      "It should return Bad Request because expires_at is in the past",
+			method:    "POST",
+			url:       url,
+			body:      fmt.Sprintf(`{"expires_at": %q}`, past),
+			status:    http.StatusBadRequest,
+			authToken: adminAuthToken,
+		},
+		{
+			name:      "It should return OK",
+			method:    "POST",
+			url:       url,
+			body:      fmt.Sprintf(`{"expires_at": %q}`, future),
+			status:    http.StatusOK,
+			authToken: adminAuthToken,
+			onResponse: func(res *httptest.ResponseRecorder) {
+				var result NewAPIKey
+				ConvertAPIResponse(res, &result)
+				if assert.NotNil(t, result.ExpiresAt) {
+					assert.True(t, result.ExpiresAt.After(time.Now()))
+				}
+			},
+		},
+		{
+			name:      "It should return Not Found",
+			method:    "POST",
+			url:       fmt.Sprintf("/apikeys/apikey/%s/expire", primitive.NewObjectID().Hex()),
+			status:    http.StatusNotFound,
+			authToken: adminAuthToken,
+		},
+	}
+	r.RunTests(t, cases)
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	data := NewMockDataAccessLayer()
+
+	r := NewMockRouter(data)
+	r.Use(rest.Init(), middlewares.ValidObjectIDHex())
+
+	s := RESTService{data: data}
+	s.ServeAPIKeys(&r.RouterGroup)
+
+	adminAuthToken := getAdminAuthToken(t)
+
+	var apikey NewAPIKey
+	res := r.Call("POST", "/apikeys", `{"name": "Test", "user_type": "client"}`, adminAuthToken)
+	if !assert.Equal(t, http.StatusOK, res.Code) {
+		return
+	}
+	ConvertAPIResponse(res, &apikey)
+	defer data.DeleteAPIKey(apikey.ID.Hex())
+
+	url := fmt.Sprintf("/apikeys/apikey/%s/scopes", apikey.ID.Hex())
+	cases := []apiTestCase{
+		{
+			name:      "It should return Bad Request because client keys can't be admins",
+			method:    "POST",
+			url:       "/apikeys",
+			body:      `{"name": "Test", "user_type": "client", "role": "admin"}`,
+			status:    http.StatusBadRequest,
+			authToken: adminAuthToken,
+		},
+		{
+			name:      "It should return Bad Request because client keys can't write",
+			method:    "POST",
+			url:       "/apikeys",
+			body:      `{"name": "Test", "user_type": "client", "scopes": ["api_write"]}`,
+			status:    http.StatusBadRequest,
+			authToken: adminAuthToken,
+		},
+		{
+			name:      "It should return Bad Request because client keys can't be editors",
+			method:    "POST",
+			url:       url,
+			body:      `{"role": "editor"}`,
+			status:    http.StatusBadRequest,
+			authToken: adminAuthToken,
+		},
+		{
+			name:      "It should return Bad Request because the scope doesn't exist",
+			method:    "POST",
+			url:       url,
+			body:      `{"scopes": ["scrapers:run"]}`,
+			status:    http.StatusBadRequest,
+			authToken: adminAuthToken,
+		},
+		{
+			name:      "It should return OK",
+			method:    "POST",
+			url:       url,
+			body:      `{"role": "client", "scopes": ["api_read"]}`,
+			status:    http.StatusOK,
+			authToken: adminAuthToken,
+		},
+	}
+	r.RunTests(t, cases)
+}
@@ -0,0 +1,36 @@
+package v2
+
+import (
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/gin-gonic/gin"
+)
+
+// AttributeService ...
+type AttributeService struct{}
+
+// ServeAttributes ...
+func (r *RESTService) ServeAttributes(rg *gin.RouterGroup) {
+	s := &AttributeService{}
+
+	attributes := rg.Group("/attributes", rest.JWTAuth(nil), ValidateRequest())
+	attributes.GET("", s.GetAll)
+	attributes.GET("/attribute/:id", s.Get)
+}
+
+// Get gets the attribute corresponding the requested ID.
+func (s *AttributeService) Get(c *gin.Context) {
+	attr, ok := models.GetAttribute(c.Param("id"))
+	if !ok {
+		apiutil.SendNotFound(c)
+		return
+	}
+	apiutil.SendSuccess(c, attr)
+}
+
+// GetAll gets the attribute catalog. Sessions can be filtered by these IDs
+// with ?attributes=imax,vip.
+func (s *AttributeService) GetAll(c *gin.Context) {
+	apiutil.SendSuccess(c, models.GetAttributeList())
+}
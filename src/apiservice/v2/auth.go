@@ -1,20 +1,30 @@
 package v2
 
 import (
-	"strings"
+	"net/http"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"golang.org/x/crypto/bcrypt"
 
-	jwt_lib "github.com/dgrijalva/jwt-go"
 	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/tokens"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
 	"github.com/gin-gonic/gin"
 )
 
+// Lifetimes of tokens. Access tokens are short lived, admins get new ones
+// with their refresh token and clients request new ones.
+const (
+	adminTokenTTL   = 15 * time.Minute
+	adminRefreshTTL = 7 * 24 * time.Hour
+	clientTokenTTL  = time.Hour
+)
+
 // AuthService ...
 type AuthService struct {
 	data persistence.DataAccessLayer
@@ -25,20 +35,36 @@ type LoginBody struct {
 	Password string `json:"password"`
 }
 
+// RefreshBody is the body of requests exchanging or revoking refresh tokens.
+type RefreshBody struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"` // Seconds until the token expires
 }
 
 // ServeAuth ...
 func (r *RESTService) ServeAuth(rg *gin.RouterGroup) {
 	s := &AuthService{r.data}
 
-	auth := rg.Group("/auth")
+	auth := rg.Group("/auth", ValidateRequest())
 	auth.POST("", s.Login)
 	auth.GET("/request_token", s.RequestToken)
+	auth.POST("/refresh", s.Refresh)
+	auth.POST("/logout", s.Logout)
+	auth.GET("/jwks.json", s.GetJWKS)
+
+	admin := rg.Group("/auth", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}), ValidateRequest())
+	admin.POST("/keys/rotate", s.RotateKeys)
 }
 
-// Login is used to generate a new admin JWT token 7 days expiry time.
+// Login is used to generate a new admin JWT token with 15 minutes expiry
+// time, paired with a refresh token valid for 7 days. The token has the
+// scopes granted to the admin key, which may be less than full admin rights.
 func (r *AuthService) Login(c *gin.Context) {
 	var body LoginBody
 	if err := c.ShouldBindJSON(&body); err != nil {
@@ -61,64 +87,226 @@ func (r *AuthService) Login(c *gin.Context) {
 		return
 	}
 
-	apikey, err := r.data.FindAPIKey(r.data.DefaultQuery().
+	apikey, err := findActiveAPIKey(r.data, r.data.DefaultQuery().
 		AddCondition("owner", body.Username).
-		AddCondition("user_type", "admin"))
+		AddCondition("user_type", "admin"), "")
 	if err != nil {
 		apiutil.SendUnauthorized(c)
 		return
 	}
 
-	claims := rest.NewClaims(apikey, []string{"api_read", "api_write"})
-	if claims == nil {
+	res, err := r.issue(apikey, apikey.GrantedScopes(), "", "")
+	apiutil.SendSuccessOrError(c, res, err)
+}
+
+// RequestToken generates a new client JWT token with 1 hour expiry time with
+// the scopes granted to the client key, by default permission to read. It
+// isn't paired with a refresh token, since anyone can request new tokens the
+// same way.
+func (r *AuthService) RequestToken(c *gin.Context) {
+	platform := rest.RequestPlatform(c)
+	apikey, err := findActiveAPIKey(r.data, r.data.DefaultQuery().
+		AddCondition("$or", bson.A{bson.M{"platform": platform}, bson.M{"platforms": platform}}).
+		AddCondition("user_type", "client"), platform)
+	if apikey == nil || err != nil {
 		apiutil.SendUnauthorized(c)
 		return
 	}
 
-	claims.ExpiresAt = time.Now().AddDate(0, 0, 7).Unix()
-	// claims.Issuer ?
+	res, err := r.issue(apikey, apikey.GrantedScopes(), "", "")
+	apiutil.SendSuccessOrError(c, res, err)
+}
 
-	token := jwt_lib.New(jwt_lib.GetSigningMethod("HS256"))
-	token.Claims = claims
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. Refresh tokens can be used once, reusing one revokes every token
+// refreshed from the same login.
+func (r *AuthService) Refresh(c *gin.Context) {
+	var body RefreshBody
+	if err := c.ShouldBindJSON(&body); err != nil || body.RefreshToken == "" {
+		apiutil.SendBadRequest(c)
+		return
+	}
 
-	ts, err := token.SignedString([]byte(rest.JWTSecret))
-	apiutil.SendSuccessOrError(c, AuthResponse{ts}, nil)
-}
+	now := time.Now().UTC()
+	hash := tokens.HashToken(body.RefreshToken)
+	token, err := r.data.UseRefreshToken(hash, now)
+	if err != nil {
+		// Reused tokens were probably stolen.
+		if used, err := r.data.GetRefreshToken(hash); err == nil && used.ReplacedAt != nil && used.RevokedAt == nil {
+			r.data.RevokeRefreshTokens(used.Family, now)
+		}
+		apiutil.SendUnauthorized(c)
+		return
+	}
 
-// RequestToken generates a new client JWT token with 1 hour expiry time with permission to read.
-func (r *AuthService) RequestToken(c *gin.Context) {
-	apikey, err := r.data.FindAPIKey(r.data.DefaultQuery().
-		AddCondition("platform", retrievePlatform(c)).
-		AddCondition("user_type", "client"))
-	if apikey == nil || err != nil {
+	apikey, err := r.data.GetAPIKey(token.APIKeyID, r.data.DefaultQuery())
+	if err != nil || !apikey.IsActive(now) || (apikey.RotatedAt != nil && token.CreatedAt != nil && token.CreatedAt.Before(*apikey.RotatedAt)) {
+		r.data.RevokeRefreshTokens(token.Family, now)
 		apiutil.SendUnauthorized(c)
 		return
 	}
 
-	claims := rest.NewClaims(apikey, []string{"api_read"})
-	if claims == nil {
+	// Scopes removed from the key since login are dropped.
+	res, err := r.issue(apikey, grantedScopes(apikey, token.Scopes), token.Platform, token.Family)
+	apiutil.SendSuccessOrError(c, res, err)
+}
+
+// Logout revokes the refresh token in the body and the access token of the
+// request, along with every token refreshed from the same login.
+func (r *AuthService) Logout(c *gin.Context) {
+	var body RefreshBody
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			apiutil.SendBadRequest(c)
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	families := []string{}
+	if body.RefreshToken != "" {
+		if token, err := r.data.GetRefreshToken(tokens.HashToken(body.RefreshToken)); err == nil {
+			families = append(families, token.Family)
+		}
+	}
+	claims, err := rest.RequestClaims(c)
+	if err == nil {
+		if err := rest.RevokeToken(r.data, claims); err != nil {
+			apiutil.SendSuccessOrError(c, nil, err)
+			return
+		}
+		if claims.Session != "" {
+			families = append(families, claims.Session)
+		}
+	} else if len(families) == 0 {
 		apiutil.SendUnauthorized(c)
 		return
 	}
 
-	claims.ExpiresAt = time.Now().Add(time.Hour * 1).Unix()
-	// claims.Issuer ?
+	for _, family := range families {
+		if _, err := r.data.RevokeRefreshTokens(family, now); err != nil {
+			apiutil.SendSuccessOrError(c, nil, err)
+			return
+		}
+	}
+	apiutil.SendSuccess(c, 1)
+}
+
+// GetJWKS gets the public keys verifying tokens, so other services don't
+// need the shared secret.
+func (r *AuthService) GetJWKS(c *gin.Context) {
+	result := tokens.JWKS{Keys: []tokens.JWK{}}
+	if rest.Keys != nil {
+		keys, err := rest.Keys.KeySet()
+		if err != nil {
+			apiutil.SendSuccessOrError(c, nil, err)
+			return
+		}
+		result = keys.JWKS()
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// RotateKeys creates a new key signing tokens. Tokens signed with previous
+// keys stay valid until they expire.
+func (r *AuthService) RotateKeys(c *gin.Context) {
+	if rest.Keys == nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	key, err := rest.Keys.Rotate()
+	if err != nil {
+		apiutil.SendSuccessOrError(c, nil, err)
+		return
+	}
+	apiutil.SendSuccess(c, key.JWK())
+}
+
+// grantedScopes returns the given scopes still granted to the API key.
+func grantedScopes(apikey *models.APIKey, scopes []string) []string {
+	granted := apikey.GrantedScopes()
+	result := []string{}
+	for _, s := range scopes {
+		for _, g := range granted {
+			if s == g {
+				result = append(result, s)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// issue signs an access token for the API key. Admin tokens are paired with
+// a new refresh token of the given family, logins start new families.
+func (r *AuthService) issue(apikey *models.APIKey, scopes []string, platform, family string) (*AuthResponse, error) {
+	claims := rest.NewClaims(apikey, scopes)
+	if claims == nil {
+		return nil, apiutil.ErrInvalidCredentials
+	}
+	if platform != "" {
+		claims.Platform = platform
+	}
 
-	token := jwt_lib.New(jwt_lib.GetSigningMethod("HS256"))
-	token.Claims = claims
+	now := time.Now().UTC()
+	if apikey.UserType != models.UserTypeAdmin {
+		claims.ExpiresAt = now.Add(clientTokenTTL).Unix()
+		signed, err := rest.SignClaims(claims)
+		if err != nil {
+			return nil, err
+		}
+		return &AuthResponse{
+			Token:     signed,
+			TokenType: "Bearer",
+			ExpiresIn: int(clientTokenTTL.Seconds()),
+		}, nil
+	}
 
-	ts, err := token.SignedString([]byte(rest.JWTSecret))
-	apiutil.SendSuccessOrError(c, AuthResponse{ts}, err)
+	if family == "" {
+		family = tokens.NewID()
+	}
+	claims.Session = family
+	claims.ExpiresAt = now.Add(adminTokenTTL).Unix()
+	signed, err := rest.SignClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, hash := tokens.NewRefreshToken()
+	expiresAt := now.Add(adminRefreshTTL)
+	err = r.data.InsertRefreshToken(models.RefreshToken{
+		ID:        hash,
+		Family:    family,
+		APIKeyID:  apikey.ID.Hex(),
+		Scopes:    scopes,
+		Platform:  claims.Platform,
+		CreatedAt: &now,
+		ExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthResponse{
+		Token:        signed,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(adminTokenTTL.Seconds()),
+	}, nil
 }
 
-func retrievePlatform(c *gin.Context) string {
-	lower := strings.ToLower(c.GetHeader("user-agent"))
-	if strings.Contains(lower, "android") {
-		return "android"
-	} else if strings.Contains(lower, "ios") {
-		return "ios"
-	} else if strings.Contains(lower, "web") {
-		return "web"
+// findActiveAPIKey finds the first API key matching the query that is active
+// and allowed in the given platform.
+func findActiveAPIKey(data persistence.DataAccessLayer, query persistence.Query, platform string) (*models.APIKey, error) {
+	apikeys, err := data.GetAPIKeys(query.SetLimit(-1))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, apikey := range apikeys {
+		if apikey.IsActive(now) && (platform == "" || apikey.AllowsPlatform(platform)) {
+			return &apikey, nil
+		}
 	}
-	return ""
+	return nil, mongo.ErrNoDocuments
 }
@@ -1,7 +1,9 @@
 package v2
 
 import (
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -46,7 +48,7 @@ func TestAuth(t *testing.T) {
 		UserType: "admin",
 		Owner:    adm.Username,
 	}
-	apikey, err := data.FindAPIKey(data.DefaultQuery().AddCondition("key", testAPIKey.Key))
+	apikey, err := data.FindAPIKeyByKey(testAPIKey.Key)
 	if apikey != nil {
 		data.DeleteAPIKey(apikey.ID.Hex())
 	}
@@ -121,3 +123,69 @@ func getClientAuthToken(t *testing.T) string {
 func getAdminAuthToken(t *testing.T) string {
 	return getAuthToken(t, "admin", []string{"api_read", "api_write"})
 }
+
+func TestAuthRefresh(t *testing.T) {
+	data := NewMockDataAccessLayer()
+
+	r := NewMockRouter(data)
+	r.Use(rest.Init())
+
+	rest.JWTSecret = jwtSecret
+	rest.KeyData = data
+	defer func() { rest.KeyData = nil }()
+
+	s := RESTService{data: data}
+	s.ServeAuth(&r.RouterGroup)
+
+	username := "test-refresh-" + primitive.NewObjectID().Hex()
+	pwd, err := bcrypt.GenerateFromPassword([]byte("my password"), 0)
+	assert.NoError(t, err)
+	admin := models.Admin{ID: primitive.NewObjectID(), Username: username, Password: string(pwd)}
+	assert.NoError(t, data.InsertAdmin(admin))
+	defer data.DeleteAdmin(admin.ID.Hex())
+
+	apikey := models.APIKey{ID: primitive.NewObjectID(), Name: "Test", UserType: models.UserTypeAdmin, Owner: username}
+	assert.NoError(t, apikey.SetKey("test-refresh-key"))
+	assert.NoError(t, data.InsertAPIKey(apikey))
+	defer data.DeleteAPIKey(apikey.ID.Hex())
+
+	login := func() AuthResponse {
+		var result AuthResponse
+		res := r.Call("POST", "/auth", fmt.Sprintf(`{"username": %q, "password": "my password"}`, username), "")
+		if assert.Equal(t, http.StatusOK, res.Code) {
+			ConvertAPIResponse(res, &result)
+		}
+		return result
+	}
+	refresh := func(token string) *httptest.ResponseRecorder {
+		return r.Call("POST", "/auth/refresh", fmt.Sprintf(`{"refresh_token": %q}`, token), "")
+	}
+
+	// Refresh tokens can be exchanged once.
+	first := login()
+	assert.NotEmpty(t, first.RefreshToken)
+	res := refresh(first.RefreshToken)
+	assert.Equal(t, http.StatusOK, res.Code)
+	var second AuthResponse
+	ConvertAPIResponse(res, &second)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	// Reusing a refresh token revokes the tokens refreshed from the same login.
+	assert.Equal(t, http.StatusUnauthorized, refresh(first.RefreshToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, refresh(second.RefreshToken).Code)
+
+	// Logout revokes the access and refresh tokens.
+	third := login()
+	res = r.Call("POST", "/auth/logout", fmt.Sprintf(`{"refresh_token": %q}`, third.RefreshToken), third.Token)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, http.StatusUnauthorized, refresh(third.RefreshToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, r.Call("POST", "/auth/logout", "", third.Token).Code)
+
+	// Client tokens aren't paired with refresh tokens.
+	client := &models.APIKey{ID: primitive.NewObjectID(), UserType: models.UserTypeClient}
+	result, err := (&AuthService{data}).issue(client, client.GrantedScopes(), "", "")
+	if assert.NoError(t, err) {
+		assert.NotEmpty(t, result.Token)
+		assert.Empty(t, result.RefreshToken)
+	}
+}
@@ -0,0 +1,112 @@
+package v2
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares"
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// cachePolicies are the default caching policies of v2 routes, by route path
+// prefix. Versioned routes only change when scrapers or admins update data,
+// or when the day changes. Movies and scores are also changed by other
+// services, which don't bump the data version, so they aren't versioned.
+// Admin only routes are private.
+var cachePolicies = map[string]middlewares.CachePolicy{
+	"/v2/apikeys":                           {NoStore: true, ReadOnly: true},
+	"/v2/attributes":                        {MaxAge: 24 * time.Hour, Versioned: true},
+	"/v2/auth":                              {NoStore: true, ReadOnly: true},
+	"/v2/auth/jwks.json":                    {MaxAge: 5 * time.Minute, ReadOnly: true},
+	"/v2/calendar":                          {MaxAge: 15 * time.Minute},
+	"/v2/calendar/theater/:id/subscription": {NoStore: true},
+	"/v2/cities":                            {MaxAge: 24 * time.Hour, Private: true, Versioned: true},
+	"/v2/graphql":                           {MaxAge: time.Minute, Private: true, ReadOnly: true},
+	"/v2/holidays":                          {MaxAge: 6 * time.Hour, Versioned: true},
+	"/v2/holidays/overrides":                {MaxAge: time.Hour, Private: true, Versioned: true},
+	"/v2/movies":                            {MaxAge: 30 * time.Minute, Private: true},
+	"/v2/movies/":                           {MaxAge: 30 * time.Minute},
+	"/v2/movies/count":                      {MaxAge: 5 * time.Minute, Private: true},
+	"/v2/movies/movie/:id/sessions":         {MaxAge: 5 * time.Minute},
+	"/v2/movies/movie/:id/showtimes":        {MaxAge: 5 * time.Minute},
+	"/v2/notifications":                     {MaxAge: 5 * time.Minute, Private: true},
+	"/v2/notifications/notification":        {MaxAge: time.Hour},
+	"/v2/openapi.json":                      {MaxAge: time.Hour},
+	"/v2/prices":                            {MaxAge: time.Hour, Private: true, Versioned: true},
+	"/v2/prices/price":                      {MaxAge: time.Hour, Versioned: true},
+	"/v2/schedules":                         {MaxAge: 5 * time.Minute},
+	"/v2/scores":                            {MaxAge: time.Hour, Private: true},
+	"/v2/scrapers":                          {NoStore: true},
+	"/v2/sessions":                          {MaxAge: 5 * time.Minute},
+	"/v2/states":                            {MaxAge: 24 * time.Hour, Private: true, Versioned: true},
+	"/v2/theaters":                          {MaxAge: time.Hour, Private: true, Versioned: true},
+	"/v2/theaters/theater":                  {MaxAge: time.Hour, Versioned: true},
+	"/v2/theaters/theater/:id/sessions":     {MaxAge: 5 * time.Minute},
+}
+
+// Cache middleware sets the caching headers of v2 responses. The max-age of
+// the policies can be overridden by route path prefix.
+func Cache(data persistence.DataAccessLayer, maxAge map[string]time.Duration) gin.HandlerFunc {
+	policies := make(map[string]middlewares.CachePolicy, len(cachePolicies))
+	for path, policy := range cachePolicies {
+		policies[path] = policy
+	}
+	for path, d := range maxAge {
+		policy := policies[path]
+		policy.MaxAge = d
+		policies[path] = policy
+	}
+
+	opts := middlewares.CacheOptions{
+		Policies:  policies,
+		Authorize: authorizeCached,
+	}
+	if data != nil {
+		opts.Version = func() (int64, error) {
+			v, err := data.GetDataVersion()
+			if err != nil {
+				return 0, err
+			}
+			return v.Version, nil
+		}
+		opts.Bump = func() error {
+			_, err := data.BumpDataVersion()
+			return err
+		}
+		opts.Location = func(c *gin.Context) *time.Location {
+			return requestLocation(data, c)
+		}
+	}
+	return middlewares.Cache(opts)
+}
+
+// requestLocation returns the time zone of the theater of the request, given
+// by the theaterId query or the ID of theater routes.
+func requestLocation(data persistence.DataAccessLayer, c *gin.Context) *time.Location {
+	id := c.Query("theaterId")
+	if id == "" && strings.HasPrefix(c.FullPath(), "/v2/theaters/theater/:id") {
+		id = c.Param("id")
+	}
+	if !primitive.IsValidObjectID(id) {
+		return timeutil.DefaultLocation()
+	}
+	return theaterLocation(data, id)
+}
+
+// authorizeCached tells whether the request would pass rest.JWTAuth. Private
+// routes are admin only.
+func authorizeCached(c *gin.Context, policy middlewares.CachePolicy) bool {
+	claims, err := rest.RequestClaims(c)
+	if err != nil {
+		return false
+	}
+	if policy.Private {
+		return claims.IsAdmin()
+	}
+	return claims.HasScope(models.ScopeAPIRead)
+}
@@ -0,0 +1,24 @@
+package v2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachePolicies(t *testing.T) {
+	// Tokens and queries don't change data.
+	assert.True(t, cachePolicies["/v2/auth"].ReadOnly)
+	assert.True(t, cachePolicies["/v2/graphql"].ReadOnly)
+
+	// Schedules have the time dependent active flag.
+	assert.False(t, cachePolicies["/v2/schedules"].Versioned)
+
+	// Other services update movies and scores without bumping the version.
+	for path, policy := range cachePolicies {
+		if strings.HasPrefix(path, "/v2/movies") || strings.HasPrefix(path, "/v2/scores") {
+			assert.False(t, policy.Versioned, path)
+		}
+	}
+}
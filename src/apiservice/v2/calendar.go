@@ -0,0 +1,280 @@
+package v2
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/icalutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scheduleutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// defaultSessionDuration is used for movies without runtime.
+	defaultSessionDuration = 2 * time.Hour
+	// feedRefreshInterval is how often subscribers should update theater feeds.
+	feedRefreshInterval = time.Hour
+)
+
+// CalendarService serves schedules in iCalendar format.
+type CalendarService struct {
+	data persistence.DataAccessLayer
+}
+
+// Subscription is the address of a theater feed.
+type Subscription struct {
+	URL    string `json:"url"`    // URL using the webcal scheme, opened by calendar apps
+	Source string `json:"source"` // Source is the same feed over https
+}
+
+// ServeCalendars ...
+//
+// Calendar apps can't send the Authorization header, so theater feeds are
+// public. They only have the schedule of the theater, which is public too,
+// and no token ends up in the calendars of users.
+func (r *RESTService) ServeCalendars(rg *gin.RouterGroup) {
+	s := &CalendarService{r.data}
+
+	public := rg.Group("/calendar", ValidateRequest())
+	public.GET("/theater/:id/feed", s.GetFeed)
+
+	client := rg.Group("/calendar", rest.JWTAuth(nil), ValidateRequest())
+	client.GET("/session/:id", s.GetSession)
+	client.GET("/theater/:id", s.GetTheater)
+	client.GET("/theater/:id/movie/:movieId", s.GetTheaterMovie)
+	client.GET("/theater/:id/subscription", s.GetSubscription)
+}
+
+// GetSession gets a calendar with the session of the given ID.
+func (s *CalendarService) GetSession(c *gin.Context) {
+	session, err := s.data.GetSession(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	if !session.MovieID.IsZero() {
+		session.Movie, err = s.data.GetMovie(session.MovieID.Hex(), s.data.DefaultQuery())
+		if err != nil {
+			apiutil.HandleError(c, err)
+			return
+		}
+	}
+
+	theater, err := s.getTheater(session.TheaterID.Hex())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+
+	cal := newTheaterCalendar(theater, []models.Session{*session})
+	cal.Name = fmt.Sprintf("%s - %s", sessionTitle(session), theater.Name)
+	sendCalendar(c, cal, "session-"+session.ID.Hex())
+}
+
+// GetTheater gets a calendar with the sessions of a theater in the day of
+// the date query parameter, in YYYY-MM-DD format, or in the current week.
+func (s *CalendarService) GetTheater(c *gin.Context) {
+	theater, err := s.getTheater(c.Param("id"))
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+
+	loc := theater.City.Location()
+	var start, end time.Time
+	if date := c.Query("date"); date != "" {
+		start, err = timeutil.ParseDateIn(date, loc)
+		if err != nil {
+			apiutil.SendBadRequest(c)
+			return
+		}
+		end = timeutil.EndOfDay(start)
+	} else {
+		period := scheduleutil.GetWeekPeriodIn(time.Now(), loc)
+		start, end = period.Start, timeutil.EndOfDay(period.End)
+	}
+
+	sessions, err := s.getSessions(s.data.DefaultQuery().
+		AddCondition("theaterId", theater.ID).
+		AddCondition("startTime", bson.M{"$gte": start, "$lte": end}))
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+
+	cal := newTheaterCalendar(theater, sessions)
+	sendCalendar(c, cal, "theater-"+theater.ID.Hex())
+}
+
+// GetTheaterMovie gets a calendar with the sessions of a movie at a theater
+// from now until the end of the week.
+func (s *CalendarService) GetTheaterMovie(c *gin.Context) {
+	movieID, err := primitive.ObjectIDFromHex(c.Param("movieId"))
+	if err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+
+	theater, err := s.getTheater(c.Param("id"))
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+
+	period := scheduleutil.GetWeekPeriodIn(time.Now(), theater.City.Location())
+	sessions, err := s.getSessions(s.data.DefaultQuery().
+		AddCondition("theaterId", theater.ID).
+		AddCondition("movieId", movieID).
+		AddCondition("startTime", bson.M{"$gte": time.Now(), "$lte": timeutil.EndOfDay(period.End)}))
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	if len(sessions) == 0 {
+		apiutil.SendNotFound(c)
+		return
+	}
+
+	cal := newTheaterCalendar(theater, sessions)
+	cal.Name = fmt.Sprintf("%s - %s", sessionTitle(&sessions[0]), theater.Name)
+	sendCalendar(c, cal, "theater-"+theater.ID.Hex()+"-movie-"+movieID.Hex())
+}
+
+// GetFeed gets the subscribable calendar of a theater. It has every session
+// from the start of the current day on, so it follows the updates of the
+// scrapers.
+func (s *CalendarService) GetFeed(c *gin.Context) {
+	theater, err := s.getTheater(c.Param("id"))
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+
+	today := timeutil.FloorToDay(nil, theater.City.Location())
+	sessions, err := s.getSessions(s.data.DefaultQuery().
+		AddCondition("theaterId", theater.ID).
+		AddCondition("startTime", bson.M{"$gte": today}))
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+
+	cal := newTheaterCalendar(theater, sessions)
+	cal.Refresh = feedRefreshInterval
+	sendCalendar(c, cal, "theater-"+theater.ID.Hex())
+}
+
+// GetSubscription gets the feed address of a theater.
+func (s *CalendarService) GetSubscription(c *gin.Context) {
+	_, err := s.data.GetTheater(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+
+	u := url.URL{
+		Scheme: "https",
+		Host:   c.Request.Host,
+		Path:   strings.TrimSuffix(c.Request.URL.Path, "/subscription") + "/feed",
+	}
+	source := u.String()
+	u.Scheme = "webcal"
+	apiutil.SendSuccess(c, Subscription{URL: u.String(), Source: source})
+}
+
+func (s *CalendarService) getTheater(id string) (*models.Theater, error) {
+	return s.data.GetTheater(id, s.data.DefaultQuery().AddInclude("city"))
+}
+
+func (s *CalendarService) getSessions(query persistence.Query) ([]models.Session, error) {
+	return s.data.GetSessions(query.
+		AddCondition("hidden", false).
+		AddInclude("movie").
+		SetSort("startTime").
+		SetLimit(-1))
+}
+
+// newTheaterCalendar builds the calendar of the sessions of a theater, in
+// the time zone of its city.
+func newTheaterCalendar(theater *models.Theater, sessions []models.Session) *icalutil.Calendar {
+	address := make([]string, 0, 3)
+	for _, v := range []string{theater.Name, theater.AddressLine1, theater.AddressLine2} {
+		if v != "" {
+			address = append(address, v)
+		}
+	}
+	if theater.City != nil && theater.City.Name != "" {
+		address = append(address, fmt.Sprintf("%s - %s", theater.City.Name, theater.City.State))
+	}
+
+	events := make([]icalutil.Event, 0, len(sessions))
+	for i := range sessions {
+		session := &sessions[i]
+		if session.StartTime == nil {
+			continue
+		}
+
+		duration := defaultSessionDuration
+		if session.Movie != nil && session.Movie.Runtime > 0 {
+			duration = time.Duration(session.Movie.Runtime) * time.Minute
+		}
+
+		uid := session.Key
+		if uid == "" {
+			uid = session.ID.Hex()
+		}
+
+		event := icalutil.Event{
+			UID:         uid + "@amenic",
+			Summary:     sessionTitle(session),
+			Description: fmt.Sprintf("Sala %d - %s", session.Room, theater.Name),
+			Location:    strings.Join(address, ", "),
+			Start:       *session.StartTime,
+			End:         session.StartTime.Add(duration),
+		}
+		if session.UpdatedAt != nil {
+			event.Updated = *session.UpdatedAt
+		}
+		events = append(events, event)
+	}
+
+	return &icalutil.Calendar{
+		Name:     theater.Name,
+		Location: theater.City.Location(),
+		Events:   events,
+	}
+}
+
+// sessionTitle returns the movie title followed by the session attributes,
+// eg: Coringa (Legendado, 2D).
+func sessionTitle(session *models.Session) string {
+	title := "Sessão"
+	if session.Movie != nil && session.Movie.Title != "" {
+		title = session.Movie.Title
+	}
+
+	names := make([]string, 0)
+	for _, id := range models.SortAttributes(session.GetAttributes()) {
+		a, _ := models.GetAttribute(id)
+		names = append(names, a.Name)
+	}
+	if len(names) == 0 {
+		return title
+	}
+	return fmt.Sprintf("%s (%s)", title, strings.Join(names, ", "))
+}
+
+func sendCalendar(c *gin.Context, cal *icalutil.Calendar, name string) {
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.ics"`, name))
+	c.Data(http.StatusOK, icalutil.ContentType, []byte(cal.String()))
+}
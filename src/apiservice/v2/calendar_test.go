@@ -0,0 +1,73 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares"
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCalendar(t *testing.T) {
+	data := NewMockDataAccessLayer()
+
+	r := NewMockRouter(data)
+	r.Use(rest.Init(), middlewares.ValidObjectIDHex(), middlewares.BaseParseQuery())
+
+	s := RESTService{data: data}
+	s.ServeCalendars(&r.RouterGroup)
+
+	testTheater := models.Theater{
+		ID:        primitive.NewObjectID(),
+		Name:      "Fake Theater",
+		ShortName: "Fake",
+	}
+	err := data.InsertTheater(testTheater)
+	assert.NoError(t, err)
+
+	HexID := testTheater.ID.Hex()
+
+	clientAuthToken := getClientAuthToken(t)
+
+	cases := []apiTestCase{
+		apiTestCase{
+			name:   "It should return Unauthorized since theater calendars need a token",
+			method: "GET",
+			url:    "/calendar/theater/" + HexID,
+			status: http.StatusUnauthorized,
+		},
+		apiTestCase{
+			name:   "It should return the feed of the theater without a token",
+			method: "GET",
+			url:    "/calendar/theater/" + HexID + "/feed",
+			status: http.StatusOK,
+			onResponse: func(res *httptest.ResponseRecorder) {
+				assert.Contains(t, res.Body.String(), "BEGIN:VCALENDAR")
+			},
+		},
+		apiTestCase{
+			name:      "It should return the feed address without the token of the request",
+			method:    "GET",
+			url:       "/calendar/theater/" + HexID + "/subscription",
+			status:    http.StatusOK,
+			authToken: clientAuthToken,
+			onResponse: func(res *httptest.ResponseRecorder) {
+				var subscription Subscription
+				ConvertAPIResponse(res, &subscription)
+				assert.True(t, strings.HasPrefix(subscription.URL, "webcal://"))
+				assert.True(t, strings.HasSuffix(subscription.URL, "/calendar/theater/"+HexID+"/feed"))
+				assert.NotContains(t, subscription.Source, "access_token")
+			},
+		},
+	}
+
+	r.RunTests(t, cases)
+
+	err = data.DeleteTheater(HexID)
+	assert.NoError(t, err)
+}
@@ -1,10 +1,15 @@
 package v2
 
 import (
+	"strings"
+	"time"
+
 	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // CityService ...
@@ -16,9 +21,12 @@ type CityService struct {
 func (r *RESTService) ServeCities(rg *gin.RouterGroup) {
 	s := &CityService{r.data}
 
-	cities := rg.Group("/cities", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}))
+	cities := rg.Group("/cities", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}), ValidateRequest())
 	cities.GET("/", s.GetAll)
+	cities.POST("", s.Create)
 	cities.GET("/city/:id", s.Get)
+	cities.PUT("/city/:id", s.Update)
+	cities.DELETE("/city/:id", s.Delete)
 }
 
 // Get gets the city corresponding the requested ID.
@@ -33,8 +41,91 @@ func (s *CityService) GetAll(c *gin.Context) {
 	apiutil.SendSuccessOrError(c, cities, err)
 }
 
+// Create creates a city.
+func (s *CityService) Create(c *gin.Context) {
+	city := models.City{}
+	if err := c.ShouldBindJSON(&city); err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	if sendInvalid(c, validateCity(&city), nil) {
+		return
+	}
+	now := time.Now().UTC()
+	city.ID = primitive.NewObjectID()
+	city.CreatedAt = &now
+	city.UpdatedAt = &now
+	err := s.data.InsertCity(city)
+	apiutil.SendSuccessOrError(c, city, err)
+}
+
+// Update replaces the city with the given ID.
+func (s *CityService) Update(c *gin.Context) {
+	city := models.City{}
+	if err := c.ShouldBindJSON(&city); err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	current, err := s.data.GetCity(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	if sendInvalid(c, validateCity(&city), nil) {
+		return
+	}
+	city.ID = current.ID
+	city.CreatedAt = current.CreatedAt
+	_, err = s.data.UpdateCity(c.Param("id"), city)
+	apiutil.SendSuccessOrError(c, city, err)
+}
+
+// Delete removes the city with the given ID. Cities of theaters or holiday
+// overrides are a conflict.
+func (s *CityService) Delete(c *gin.Context) {
+	city, err := s.data.GetCity(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	theaters, err := s.data.CountTheaters(s.data.DefaultQuery().AddCondition("cityId", city.ID))
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	holidays, err := s.data.GetHolidays(s.data.DefaultQuery().AddCondition("cityId", city.ID).SetLimit(1))
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	if theaters > 0 || len(holidays) > 0 {
+		apiutil.SendConflict(c)
+		return
+	}
+	err = s.data.DeleteCity(c.Param("id"))
+	apiutil.SendSuccessOrError(c, 1, err)
+}
+
 // BuildCityQuery builds City query from request query string
 func BuildCityQuery(data persistence.DataAccessLayer, c *gin.Context) persistence.Query {
 	query := c.MustGet("query_options").(map[string]string)
 	return data.BuildCityQuery(query)
 }
+
+// validateCity checks the city has a name, a valid state and, if given, a
+// known time zone.
+func validateCity(city *models.City) []*apiutil.APIErrorDetail {
+	details := []*apiutil.APIErrorDetail{}
+	if strings.TrimSpace(city.Name) == "" {
+		details = append(details, bodyError("name", "is required"))
+	}
+	if _, ok := models.GetState(string(city.State)); !ok {
+		details = append(details, bodyError("state", "is not a state"))
+	}
+	if city.TimeZone != "" {
+		if _, err := time.LoadLocation(city.TimeZone); err != nil {
+			details = append(details, bodyError("timeZone", "is not a time zone"))
+		}
+	}
+	return details
+}
@@ -0,0 +1,75 @@
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares"
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCity(t *testing.T) {
+	data := NewMockDataAccessLayer()
+
+	r := NewMockRouter(data)
+	r.Use(rest.Init(), middlewares.ValidObjectIDHex(), middlewares.BaseParseQuery())
+
+	s := RESTService{data: data}
+	s.ServeCities(&r.RouterGroup)
+
+	adminAuthToken := getAdminAuthToken(t)
+
+	var city models.City
+	res := r.Call("POST", "/cities", `{"name": "Test City", "state": "SP"}`, adminAuthToken)
+	if !assert.Equal(t, http.StatusOK, res.Code) {
+		return
+	}
+	ConvertAPIResponse(res, &city)
+	assert.False(t, city.ID.IsZero())
+	assert.NotNil(t, city.CreatedAt)
+
+	url := "/cities/city/" + city.ID.Hex()
+	cases := []apiTestCase{
+		{
+			name:      "It should return BadRequest since the state is unknown",
+			method:    "PUT",
+			url:       url,
+			body:      `{"name": "Test City", "state": "XX"}`,
+			status:    http.StatusBadRequest,
+			authToken: adminAuthToken,
+		},
+		{
+			name:      "It should return OK",
+			method:    "PUT",
+			url:       url,
+			body:      `{"name": "Other City", "state": "SP", "timeZone": "America/Sao_Paulo"}`,
+			status:    http.StatusOK,
+			authToken: adminAuthToken,
+			onResponse: func(res *httptest.ResponseRecorder) {
+				var result models.City
+				ConvertAPIResponse(res, &result)
+				assert.Equal(t, city.ID, result.ID)
+				assert.Equal(t, "Other City", result.Name)
+				assert.Equal(t, city.CreatedAt.Unix(), result.CreatedAt.Unix())
+			},
+		},
+		{
+			name:      "It should return OK",
+			method:    "DELETE",
+			url:       url,
+			status:    http.StatusOK,
+			authToken: adminAuthToken,
+		},
+		{
+			name:      "It should return NotFound since the city was deleted",
+			method:    "DELETE",
+			url:       url,
+			status:    http.StatusNotFound,
+			authToken: adminAuthToken,
+		},
+	}
+	r.RunTests(t, cases)
+}
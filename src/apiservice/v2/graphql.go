@@ -0,0 +1,225 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// graphQLMaxDepth limits how deep queries can nest fields.
+const graphQLMaxDepth = 8
+
+// graphQLSchema describes the v2 data model. Listings that are admin only in
+// the REST API are admin only here too.
+const graphQLSchema = `
+schema {
+	query: Query
+}
+
+# Time is a date and time in RFC 3339 format.
+scalar Time
+
+enum MovieFilter {
+	ALL
+	NOW_PLAYING
+	UPCOMING
+}
+
+type Query {
+	# Movies now playing, upcoming or, for admins, every movie.
+	movies(filter: MovieFilter = NOW_PLAYING, limit: Int, skip: Int): [Movie!]!
+	movie(id: ID!): Movie
+	# Theaters, admin only.
+	theaters(cityId: ID, limit: Int, skip: Int): [Theater!]!
+	theater(id: ID!): Theater
+	# Cities, admin only.
+	cities(state: String): [City!]!
+	# City, admin only.
+	city(id: ID!): City
+	# Sessions with the same filters of GET /v2/sessions. Days are in the time
+	# zone of the theater. Pages have 25 sessions by default and 50 at most.
+	sessions(
+		theaterId: ID,
+		cityId: ID,
+		movieId: ID,
+		start: String,
+		end: String,
+		timeFrom: String,
+		timeTo: String,
+		attributes: [String!],
+		version: [String!],
+		upcoming: Boolean,
+		page: Int,
+		limit: Int
+	): [Session!]!
+	session(id: ID!): Session
+	price(id: ID!): Price
+	# Notifications, admin only.
+	notifications(limit: Int, skip: Int): [Notification!]!
+	notification(id: ID!): Notification
+}
+
+type Movie {
+	id: ID!
+	title: String!
+	originalTitle: String!
+	synopsis: String!
+	cast: [String!]!
+	genres: [String!]!
+	rating: Int!
+	runtime: Int!
+	distributor: String!
+	trailer: String!
+	posterUrl: String!
+	backdropUrl: String!
+	releaseDate: Time
+	scores: [Score!]!
+	images(type: String): [Image!]!
+	# Sessions of the movie at a theater in the given day, in YYYY-MM-DD
+	# format. Defaults to today.
+	sessions(theaterId: ID!, date: String): [Session!]!
+}
+
+type Theater {
+	id: ID!
+	name: String!
+	shortName: String!
+	website: String!
+	place: String!
+	addressLine1: String!
+	addressLine2: String!
+	phones: [String!]!
+	location: [String!]!
+	city: City
+	prices: [Price!]!
+	# Sessions of the theater in the given day, in YYYY-MM-DD format.
+	# Defaults to today in the theater time zone.
+	sessions(date: String, movieId: ID): [Session!]!
+}
+
+type City {
+	id: ID!
+	name: String!
+	state: String!
+	timeZone: String!
+	theaters: [Theater!]!
+}
+
+type Session {
+	id: ID!
+	startTime: Time
+	openingTime: String!
+	date: Int!
+	timeZone: String!
+	room: Int!
+	version: String!
+	format: String!
+	type: String!
+	attributes: [String!]!
+	movie: Movie
+	theater: Theater
+}
+
+type Price {
+	id: ID!
+	label: String!
+	full: Float!
+	half: Float!
+	weekdays: [Int!]!
+	attributes: [String!]!
+	includingPreviews: Boolean!
+	includingHolidays: Boolean!
+	exceptPreviews: Boolean!
+	exceptHolidays: Boolean!
+}
+
+type Score {
+	id: ID!
+	imdbId: String!
+	imdbScore: Float!
+	rottenPath: String!
+	rottenClass: String!
+	rottenScore: Int!
+	updatedAt: Time
+}
+
+type Image {
+	id: ID!
+	type: String!
+	main: Boolean!
+	url: String!
+	secureUrl: String!
+	width: Int!
+	height: Int!
+}
+
+type Notification {
+	id: ID!
+	type: String!
+	title: String!
+	text: String!
+	htmlText: String!
+	single: Boolean!
+	itemId: String!
+	createdAt: Time
+}
+`
+
+// GraphQLService ...
+type GraphQLService struct {
+	data   persistence.DataAccessLayer
+	schema *graphql.Schema
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ServeGraphQL ...
+func (r *RESTService) ServeGraphQL(rg *gin.RouterGroup) {
+	s := &GraphQLService{
+		data:   r.data,
+		schema: graphql.MustParseSchema(graphQLSchema, &graphQLResolver{data: r.data}, graphql.MaxDepth(graphQLMaxDepth)),
+	}
+
+	// The schema only has queries, so POST requests only need to read too.
+	client := rg.Group("/graphql", rest.JWTAuth(&rest.Endpoint{Scope: models.ScopeAPIRead}), ValidateRequest())
+	client.GET("", s.Query)
+	client.POST("", s.Query)
+}
+
+// Query executes a GraphQL query. GET requests carry it in the query,
+// operationName and variables query parameters.
+func (s *GraphQLService) Query(c *gin.Context) {
+	var req graphQLRequest
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if v := c.Query("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				apiutil.SendBadRequest(c)
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	if req.Query == "" {
+		apiutil.SendBadRequest(c)
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), graphQLClaimsKey, rest.GetClaims(c))
+	ctx = context.WithValue(ctx, graphQLLoadersKey, newLoaders())
+	c.JSON(http.StatusOK, s.schema.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
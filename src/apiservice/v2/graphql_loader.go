@@ -0,0 +1,129 @@
+package v2
+
+import (
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	// fetchFunc loads the values of the given IDs in a single query. IDs
+	// without value may be missing from the result.
+	fetchFunc func(ids []primitive.ObjectID) (map[primitive.ObjectID]interface{}, error)
+
+	// keySet is the set of IDs of a collection seen while resolving a
+	// GraphQL request.
+	keySet struct {
+		mu  sync.Mutex
+		ids map[primitive.ObjectID]bool
+	}
+
+	// loader batches the lookups of values by ID. The first Load fetches the
+	// values of every ID in its key set, so resolving a field of a list
+	// takes one query instead of one per item.
+	loader struct {
+		mu    sync.Mutex
+		keys  *keySet
+		fetch fetchFunc
+		cache map[primitive.ObjectID]interface{}
+	}
+
+	// loaders holds the loaders of a GraphQL request.
+	loaders struct {
+		mu      sync.Mutex
+		keys    map[string]*keySet
+		loaders map[string]*loader
+	}
+)
+
+func newKeySet() *keySet {
+	return &keySet{ids: map[primitive.ObjectID]bool{}}
+}
+
+// Add adds the given IDs to the set. Zero IDs are ignored.
+func (s *keySet) Add(ids ...primitive.ObjectID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		if !id.IsZero() {
+			s.ids[id] = true
+		}
+	}
+}
+
+// List returns the IDs of the set.
+func (s *keySet) List() []primitive.ObjectID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]primitive.ObjectID, 0, len(s.ids))
+	for id := range s.ids {
+		result = append(result, id)
+	}
+	return result
+}
+
+func newLoader(keys *keySet, fetch fetchFunc) *loader {
+	return &loader{keys: keys, fetch: fetch, cache: map[primitive.ObjectID]interface{}{}}
+}
+
+// Load returns the value of the given ID, or nil if there is none.
+func (l *loader) Load(id primitive.ObjectID) (interface{}, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if v, ok := l.cache[id]; ok {
+		return v, nil
+	}
+
+	l.keys.Add(id)
+	ids := make([]primitive.ObjectID, 0)
+	for _, k := range l.keys.List() {
+		if _, ok := l.cache[k]; !ok {
+			ids = append(ids, k)
+		}
+	}
+
+	values, err := l.fetch(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range ids {
+		l.cache[k] = values[k]
+	}
+	return l.cache[id], nil
+}
+
+func newLoaders() *loaders {
+	return &loaders{
+		keys:    map[string]*keySet{},
+		loaders: map[string]*loader{},
+	}
+}
+
+// Keys returns the key set of the given collection.
+func (l *loaders) Keys(collection string) *keySet {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.keys[collection]
+	if !ok {
+		s = newKeySet()
+		l.keys[collection] = s
+	}
+	return s
+}
+
+// Get returns the loader with the given name, creating it with the key set
+// of the given collection if needed. Names must include the arguments the
+// fetch function depends on.
+func (l *loaders) Get(name, collection string, fetch fetchFunc) *loader {
+	keys := l.Keys(collection)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	result, ok := l.loaders[name]
+	if !ok {
+		result = newLoader(keys, fetch)
+		l.loaders[name] = result
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestLoader(t *testing.T) {
+	l := newLoaders()
+
+	calls := 0
+	fetch := func(ids []primitive.ObjectID) (map[primitive.ObjectID]interface{}, error) {
+		calls++
+		result := map[primitive.ObjectID]interface{}{}
+		for _, id := range ids {
+			result[id] = id.Hex()
+		}
+		return result, nil
+	}
+
+	a, b, c := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	l.Keys(keyMovies).Add(a, b, primitive.NilObjectID)
+
+	movies := l.Get("movie", keyMovies, fetch)
+	v, err := movies.Load(a)
+	assert.NoError(t, err)
+	assert.Equal(t, a.Hex(), v)
+
+	// Keys seen before the first load are fetched with it.
+	v, err = movies.Load(b)
+	assert.NoError(t, err)
+	assert.Equal(t, b.Hex(), v)
+	assert.Equal(t, 1, calls)
+
+	// Unseen keys require another query.
+	v, err = movies.Load(c)
+	assert.NoError(t, err)
+	assert.Equal(t, c.Hex(), v)
+	assert.Equal(t, 2, calls)
+
+	// Loaders with the same name are shared.
+	assert.Equal(t, movies, l.Get("movie", keyMovies, fetch))
+}
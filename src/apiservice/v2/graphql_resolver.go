@@ -0,0 +1,828 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
+	graphql "github.com/graph-gophers/graphql-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type graphQLContextKey string
+
+const (
+	graphQLClaimsKey  graphQLContextKey = "claims"
+	graphQLLoadersKey graphQLContextKey = "loaders"
+)
+
+// Key sets of the loaders.
+const (
+	keyMovies   = "movies"
+	keyTheaters = "theaters"
+	keyCities   = "cities"
+)
+
+var (
+	errGraphQLUnauthorized = errors.New("this field requires admin credentials")
+	errGraphQLInvalidID    = errors.New("invalid id")
+)
+
+type (
+	// graphQLResolver is the root resolver of the GraphQL schema.
+	graphQLResolver struct {
+		data persistence.DataAccessLayer
+	}
+
+	movieResolver struct {
+		r *graphQLResolver
+		m *models.Movie
+	}
+
+	theaterResolver struct {
+		r *graphQLResolver
+		t *models.Theater
+	}
+
+	cityResolver struct {
+		r *graphQLResolver
+		c *models.City
+	}
+
+	sessionResolver struct {
+		r *graphQLResolver
+		s *models.Session
+	}
+
+	priceResolver struct {
+		p *models.Price
+	}
+
+	scoreResolver struct {
+		s *models.Score
+	}
+
+	imageResolver struct {
+		i *models.Image
+	}
+
+	notificationResolver struct {
+		n *models.Notification
+	}
+
+	pageArgs struct {
+		Limit *int32
+		Skip  *int32
+	}
+
+	sessionsArgs struct {
+		TheaterID  *graphql.ID
+		CityID     *graphql.ID
+		MovieID    *graphql.ID
+		Start      *string
+		End        *string
+		TimeFrom   *string
+		TimeTo     *string
+		Attributes *[]string
+		Version    *[]string
+		Upcoming   *bool
+		Page       *int32
+		Limit      *int32
+	}
+)
+
+// ------ Query ------
+
+// Movies ...
+func (r *graphQLResolver) Movies(ctx context.Context, args struct {
+	Filter string
+	Limit  *int32
+	Skip   *int32
+}) ([]*movieResolver, error) {
+	var movies []models.Movie
+	var err error
+	switch args.Filter {
+	case "NOW_PLAYING":
+		movies, err = r.data.GetNowPlayingMovies(nil)
+	case "UPCOMING":
+		movies, err = r.data.GetUpcomingMovies(r.data.BuildMovieQuery(map[string]string{}))
+	default:
+		if err := requireAdmin(ctx); err != nil {
+			return nil, err
+		}
+		movies, err = r.data.GetMovies(page(r.data.DefaultQuery(), pageArgs{args.Limit, args.Skip}))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.newMovies(ctx, movies), nil
+}
+
+// Movie ...
+func (r *graphQLResolver) Movie(ctx context.Context, args struct{ ID graphql.ID }) (*movieResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return r.loadMovie(ctx, id)
+}
+
+// Theaters ...
+func (r *graphQLResolver) Theaters(ctx context.Context, args struct {
+	CityID *graphql.ID
+	Limit  *int32
+	Skip   *int32
+}) ([]*theaterResolver, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	query := page(r.data.DefaultQuery(), pageArgs{args.Limit, args.Skip})
+	if args.CityID != nil {
+		id, err := parseID(*args.CityID)
+		if err != nil {
+			return nil, err
+		}
+		query.AddCondition("cityId", id)
+	}
+	theaters, err := r.data.GetTheaters(query)
+	if err != nil {
+		return nil, err
+	}
+	return r.newTheaters(ctx, theaters), nil
+}
+
+// Theater ...
+func (r *graphQLResolver) Theater(ctx context.Context, args struct{ ID graphql.ID }) (*theaterResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return r.loadTheater(ctx, id)
+}
+
+// Cities ...
+func (r *graphQLResolver) Cities(ctx context.Context, args struct{ State *string }) ([]*cityResolver, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	query := r.data.DefaultQuery().SetLimit(-1)
+	if args.State != nil {
+		query.AddCondition("state", *args.State)
+	}
+	cities, err := r.data.GetCities(query)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*cityResolver, len(cities))
+	for i := range cities {
+		result[i] = r.newCity(ctx, &cities[i])
+	}
+	return result, nil
+}
+
+// City ...
+func (r *graphQLResolver) City(ctx context.Context, args struct{ ID graphql.ID }) (*cityResolver, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return r.loadCity(ctx, id)
+}
+
+// Sessions ...
+func (r *graphQLResolver) Sessions(ctx context.Context, args sessionsArgs) ([]*sessionResolver, error) {
+	q := map[string]string{}
+	setID := func(name string, v *graphql.ID) {
+		if v != nil {
+			q[name] = string(*v)
+		}
+	}
+	setString := func(name string, v *string) {
+		if v != nil {
+			q[name] = *v
+		}
+	}
+	setID("theaterId", args.TheaterID)
+	setID("cityId", args.CityID)
+	setID("movieId", args.MovieID)
+	setString("start", args.Start)
+	setString("end", args.End)
+	setString("timeFrom", args.TimeFrom)
+	setString("timeTo", args.TimeTo)
+	if args.Attributes != nil {
+		q["attributes"] = strings.Join(*args.Attributes, ",")
+	}
+	if args.Version != nil {
+		q["version"] = strings.Join(*args.Version, ",")
+	}
+	if args.Upcoming != nil {
+		q["upcoming"] = strconv.FormatBool(*args.Upcoming)
+	}
+	if args.Page != nil {
+		q["page"] = strconv.Itoa(int(*args.Page))
+	}
+	if args.Limit != nil {
+		q["limit"] = strconv.Itoa(int(*args.Limit))
+	}
+
+	query, err := buildSessionListQuery(r.data, q)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := r.data.GetSessions(query)
+	if err != nil {
+		return nil, err
+	}
+	return r.newSessions(ctx, sessions), nil
+}
+
+// Session ...
+func (r *graphQLResolver) Session(ctx context.Context, args struct{ ID graphql.ID }) (*sessionResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	session, err := r.data.GetSession(id.Hex(), r.data.DefaultQuery())
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.newSession(ctx, session), nil
+}
+
+// Price ...
+func (r *graphQLResolver) Price(ctx context.Context, args struct{ ID graphql.ID }) (*priceResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	price, err := r.data.GetPrice(id.Hex(), r.data.DefaultQuery())
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &priceResolver{price}, nil
+}
+
+// Notifications ...
+func (r *graphQLResolver) Notifications(ctx context.Context, args pageArgs) ([]*notificationResolver, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	notifications, err := r.data.GetNotifications(page(r.data.DefaultQuery(), args).SetSort("-createdAt"))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*notificationResolver, len(notifications))
+	for i := range notifications {
+		result[i] = &notificationResolver{&notifications[i]}
+	}
+	return result, nil
+}
+
+// Notification ...
+func (r *graphQLResolver) Notification(ctx context.Context, args struct{ ID graphql.ID }) (*notificationResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	notification, err := r.data.GetNotification(id.Hex(), r.data.DefaultQuery())
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &notificationResolver{notification}, nil
+}
+
+// ------ Loaders ------
+
+func (r *graphQLResolver) loadMovie(ctx context.Context, id primitive.ObjectID) (*movieResolver, error) {
+	if id.IsZero() {
+		return nil, nil
+	}
+	v, err := contextLoaders(ctx).Get("movie", keyMovies, func(ids []primitive.ObjectID) (map[primitive.ObjectID]interface{}, error) {
+		movies, err := r.data.GetMovies(r.data.DefaultQuery().
+			AddCondition("_id", bson.M{"$in": ids}).
+			SetLimit(-1))
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[primitive.ObjectID]interface{}, len(movies))
+		for i := range movies {
+			result[movies[i].ID] = &movies[i]
+		}
+		return result, nil
+	}).Load(id)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return r.newMovie(ctx, v.(*models.Movie)), nil
+}
+
+func (r *graphQLResolver) loadTheater(ctx context.Context, id primitive.ObjectID) (*theaterResolver, error) {
+	if id.IsZero() {
+		return nil, nil
+	}
+	v, err := contextLoaders(ctx).Get("theater", keyTheaters, func(ids []primitive.ObjectID) (map[primitive.ObjectID]interface{}, error) {
+		theaters, err := r.data.GetTheaters(r.data.DefaultQuery().
+			AddCondition("_id", bson.M{"$in": ids}).
+			SetLimit(-1))
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[primitive.ObjectID]interface{}, len(theaters))
+		for i := range theaters {
+			result[theaters[i].ID] = &theaters[i]
+		}
+		return result, nil
+	}).Load(id)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return r.newTheater(ctx, v.(*models.Theater)), nil
+}
+
+func (r *graphQLResolver) loadCity(ctx context.Context, id primitive.ObjectID) (*cityResolver, error) {
+	if id.IsZero() {
+		return nil, nil
+	}
+	v, err := contextLoaders(ctx).Get("city", keyCities, func(ids []primitive.ObjectID) (map[primitive.ObjectID]interface{}, error) {
+		cities, err := r.data.GetCities(r.data.DefaultQuery().
+			AddCondition("_id", bson.M{"$in": ids}).
+			SetLimit(-1))
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[primitive.ObjectID]interface{}, len(cities))
+		for i := range cities {
+			result[cities[i].ID] = &cities[i]
+		}
+		return result, nil
+	}).Load(id)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return r.newCity(ctx, v.(*models.City)), nil
+}
+
+// location returns the time zone of the theater with the given ID.
+func (r *graphQLResolver) location(ctx context.Context, theaterID primitive.ObjectID) (*time.Location, error) {
+	theater, err := r.loadTheater(ctx, theaterID)
+	if err != nil || theater == nil {
+		return timeutil.DefaultLocation(), err
+	}
+	city, err := r.loadCity(ctx, theater.t.CityID)
+	if err != nil || city == nil {
+		return timeutil.DefaultLocation(), err
+	}
+	return city.c.Location(), nil
+}
+
+// sessionsBy loads the sessions grouped by the given field, which holds IDs
+// of the collection of the key set.
+func (r *graphQLResolver) sessionsBy(ctx context.Context, name, field, collection string, id primitive.ObjectID, query func(ids []primitive.ObjectID) (persistence.Query, error)) ([]*sessionResolver, error) {
+	v, err := contextLoaders(ctx).Get(name, collection, func(ids []primitive.ObjectID) (map[primitive.ObjectID]interface{}, error) {
+		q, err := query(ids)
+		if err != nil {
+			return nil, err
+		}
+		sessions, err := r.data.GetSessions(q.
+			AddCondition("hidden", false).
+			SetSort("startTime").
+			SetLimit(-1))
+		if err != nil {
+			return nil, err
+		}
+		result := map[primitive.ObjectID]interface{}{}
+		for _, s := range sessions {
+			key := s.TheaterID
+			if field == "movieId" {
+				key = s.MovieID
+			}
+			list, _ := result[key].([]models.Session)
+			result[key] = append(list, s)
+		}
+		return result, nil
+	}).Load(id)
+	if err != nil {
+		return nil, err
+	}
+	sessions, _ := v.([]models.Session)
+	return r.newSessions(ctx, sessions), nil
+}
+
+// ------ Constructors ------
+
+func (r *graphQLResolver) newMovie(ctx context.Context, m *models.Movie) *movieResolver {
+	contextLoaders(ctx).Keys(keyMovies).Add(m.ID)
+	return &movieResolver{r, m}
+}
+
+func (r *graphQLResolver) newMovies(ctx context.Context, movies []models.Movie) []*movieResolver {
+	result := make([]*movieResolver, len(movies))
+	for i := range movies {
+		result[i] = r.newMovie(ctx, &movies[i])
+	}
+	return result
+}
+
+func (r *graphQLResolver) newTheater(ctx context.Context, t *models.Theater) *theaterResolver {
+	l := contextLoaders(ctx)
+	l.Keys(keyTheaters).Add(t.ID)
+	l.Keys(keyCities).Add(t.CityID)
+	return &theaterResolver{r, t}
+}
+
+func (r *graphQLResolver) newTheaters(ctx context.Context, theaters []models.Theater) []*theaterResolver {
+	result := make([]*theaterResolver, len(theaters))
+	for i := range theaters {
+		result[i] = r.newTheater(ctx, &theaters[i])
+	}
+	return result
+}
+
+func (r *graphQLResolver) newCity(ctx context.Context, c *models.City) *cityResolver {
+	contextLoaders(ctx).Keys(keyCities).Add(c.ID)
+	return &cityResolver{r, c}
+}
+
+func (r *graphQLResolver) newSession(ctx context.Context, s *models.Session) *sessionResolver {
+	l := contextLoaders(ctx)
+	l.Keys(keyMovies).Add(s.MovieID)
+	l.Keys(keyTheaters).Add(s.TheaterID)
+	return &sessionResolver{r, s}
+}
+
+func (r *graphQLResolver) newSessions(ctx context.Context, sessions []models.Session) []*sessionResolver {
+	result := make([]*sessionResolver, len(sessions))
+	for i := range sessions {
+		result[i] = r.newSession(ctx, &sessions[i])
+	}
+	return result
+}
+
+// ------ Movie ------
+
+func (m *movieResolver) ID() graphql.ID        { return graphql.ID(m.m.ID.Hex()) }
+func (m *movieResolver) Title() string         { return m.m.Title }
+func (m *movieResolver) OriginalTitle() string { return m.m.OriginalTitle }
+func (m *movieResolver) Synopsis() string      { return m.m.Synopsis }
+func (m *movieResolver) Cast() []string        { return nonNil(m.m.Cast) }
+func (m *movieResolver) Genres() []string      { return nonNil(m.m.Genres) }
+func (m *movieResolver) Rating() int32         { return int32(m.m.Rating) }
+func (m *movieResolver) Runtime() int32        { return int32(m.m.Runtime) }
+func (m *movieResolver) Distributor() string   { return m.m.Distributor }
+func (m *movieResolver) Trailer() string       { return m.m.Trailer }
+func (m *movieResolver) PosterURL() string     { return m.m.PosterURL }
+func (m *movieResolver) BackdropURL() string   { return m.m.BackdropURL }
+func (m *movieResolver) ReleaseDate() *graphql.Time {
+	return toTime(m.m.ReleaseDate)
+}
+
+func (m *movieResolver) Scores(ctx context.Context) ([]*scoreResolver, error) {
+	v, err := contextLoaders(ctx).Get("movie.scores", keyMovies, func(ids []primitive.ObjectID) (map[primitive.ObjectID]interface{}, error) {
+		scores, err := m.r.data.GetScores(m.r.data.DefaultQuery().
+			AddCondition("movieId", bson.M{"$in": ids}).
+			SetLimit(-1))
+		if err != nil {
+			return nil, err
+		}
+		result := map[primitive.ObjectID]interface{}{}
+		for _, s := range scores {
+			list, _ := result[s.MovieID].([]models.Score)
+			result[s.MovieID] = append(list, s)
+		}
+		return result, nil
+	}).Load(m.m.ID)
+	if err != nil {
+		return nil, err
+	}
+	scores, _ := v.([]models.Score)
+	result := make([]*scoreResolver, len(scores))
+	for i := range scores {
+		result[i] = &scoreResolver{&scores[i]}
+	}
+	return result, nil
+}
+
+func (m *movieResolver) Images(ctx context.Context, args struct{ Type *string }) ([]*imageResolver, error) {
+	v, err := contextLoaders(ctx).Get("movie.images", keyMovies, func(ids []primitive.ObjectID) (map[primitive.ObjectID]interface{}, error) {
+		images, err := m.r.data.GetImages(m.r.data.DefaultQuery().
+			AddCondition("movieId", bson.M{"$in": ids}).
+			SetLimit(-1))
+		if err != nil {
+			return nil, err
+		}
+		result := map[primitive.ObjectID]interface{}{}
+		for _, i := range images {
+			list, _ := result[i.MovieID].([]models.Image)
+			result[i.MovieID] = append(list, i)
+		}
+		return result, nil
+	}).Load(m.m.ID)
+	if err != nil {
+		return nil, err
+	}
+	images, _ := v.([]models.Image)
+	result := make([]*imageResolver, 0, len(images))
+	for i := range images {
+		if args.Type == nil || images[i].Type == *args.Type {
+			result = append(result, &imageResolver{&images[i]})
+		}
+	}
+	return result, nil
+}
+
+func (m *movieResolver) Sessions(ctx context.Context, args struct {
+	TheaterID graphql.ID
+	Date      *string
+}) ([]*sessionResolver, error) {
+	theaterID, err := parseID(args.TheaterID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := m.r.location(ctx, theaterID)
+	if err != nil {
+		return nil, err
+	}
+	day := timeutil.NowIn(loc)
+	if args.Date != nil {
+		day, err = timeutil.ParseDateIn(*args.Date, loc)
+		if err != nil {
+			return nil, errInvalidSessionFilter
+		}
+	}
+	date := timeutil.DateIn(day, loc)
+
+	name := "movie.sessions:" + theaterID.Hex() + ":" + strconv.Itoa(date)
+	return m.r.sessionsBy(ctx, name, "movieId", keyMovies, m.m.ID, func(ids []primitive.ObjectID) (persistence.Query, error) {
+		return m.r.data.DefaultQuery().
+			AddCondition("movieId", bson.M{"$in": ids}).
+			AddCondition("theaterId", theaterID).
+			AddCondition("date", date), nil
+	})
+}
+
+// ------ Theater ------
+
+func (t *theaterResolver) ID() graphql.ID       { return graphql.ID(t.t.ID.Hex()) }
+func (t *theaterResolver) Name() string         { return t.t.Name }
+func (t *theaterResolver) ShortName() string    { return t.t.ShortName }
+func (t *theaterResolver) Website() string      { return t.t.Website }
+func (t *theaterResolver) Place() string        { return t.t.Place }
+func (t *theaterResolver) AddressLine1() string { return t.t.AddressLine1 }
+func (t *theaterResolver) AddressLine2() string { return t.t.AddressLine2 }
+func (t *theaterResolver) Phones() []string     { return nonNil(t.t.Phones) }
+func (t *theaterResolver) Location() []string   { return nonNil(t.t.Location) }
+
+func (t *theaterResolver) City(ctx context.Context) (*cityResolver, error) {
+	return t.r.loadCity(ctx, t.t.CityID)
+}
+
+func (t *theaterResolver) Prices(ctx context.Context) ([]*priceResolver, error) {
+	v, err := contextLoaders(ctx).Get("theater.prices", keyTheaters, func(ids []primitive.ObjectID) (map[primitive.ObjectID]interface{}, error) {
+		prices, err := t.r.data.GetPrices(t.r.data.DefaultQuery().
+			AddCondition("theaterId", bson.M{"$in": ids}).
+			SetSort("-weight", "label").
+			SetLimit(-1))
+		if err != nil {
+			return nil, err
+		}
+		result := map[primitive.ObjectID]interface{}{}
+		for _, p := range prices {
+			list, _ := result[p.TheaterID].([]models.Price)
+			result[p.TheaterID] = append(list, p)
+		}
+		return result, nil
+	}).Load(t.t.ID)
+	if err != nil {
+		return nil, err
+	}
+	prices, _ := v.([]models.Price)
+	result := make([]*priceResolver, len(prices))
+	for i := range prices {
+		result[i] = &priceResolver{&prices[i]}
+	}
+	return result, nil
+}
+
+func (t *theaterResolver) Sessions(ctx context.Context, args struct {
+	Date    *string
+	MovieID *graphql.ID
+}) ([]*sessionResolver, error) {
+	// Dates are days, so a given date is the same in every time zone. Today
+	// depends on the time zone of each theater.
+	date := 0
+	if args.Date != nil {
+		t, err := time.Parse(timeutil.DateFormat, *args.Date)
+		if err != nil {
+			return nil, errInvalidSessionFilter
+		}
+		date = timeutil.DateIn(t, time.UTC)
+	}
+	var movieID primitive.ObjectID
+	if args.MovieID != nil {
+		id, err := parseID(*args.MovieID)
+		if err != nil {
+			return nil, err
+		}
+		movieID = id
+	}
+
+	name := "theater.sessions:" + strconv.Itoa(date) + ":" + movieID.Hex()
+	return t.r.sessionsBy(ctx, name, "theaterId", keyTheaters, t.t.ID, func(ids []primitive.ObjectID) (persistence.Query, error) {
+		byDate := map[int][]primitive.ObjectID{}
+		for _, id := range ids {
+			d := date
+			if d == 0 {
+				loc, err := t.r.location(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+				d = timeutil.DateIn(time.Now(), loc)
+			}
+			byDate[d] = append(byDate[d], id)
+		}
+		or := bson.A{}
+		for d, ids := range byDate {
+			or = append(or, bson.M{"theaterId": bson.M{"$in": ids}, "date": d})
+		}
+		query := t.r.data.DefaultQuery().AddCondition("$or", or)
+		if !movieID.IsZero() {
+			query.AddCondition("movieId", movieID)
+		}
+		return query, nil
+	})
+}
+
+// ------ City ------
+
+func (c *cityResolver) ID() graphql.ID   { return graphql.ID(c.c.ID.Hex()) }
+func (c *cityResolver) Name() string     { return c.c.Name }
+func (c *cityResolver) State() string    { return string(c.c.State) }
+func (c *cityResolver) TimeZone() string { return c.c.TimeZone }
+
+func (c *cityResolver) Theaters(ctx context.Context) ([]*theaterResolver, error) {
+	v, err := contextLoaders(ctx).Get("city.theaters", keyCities, func(ids []primitive.ObjectID) (map[primitive.ObjectID]interface{}, error) {
+		theaters, err := c.r.data.GetTheaters(c.r.data.DefaultQuery().
+			AddCondition("cityId", bson.M{"$in": ids}).
+			AddCondition("hidden", false).
+			SetLimit(-1))
+		if err != nil {
+			return nil, err
+		}
+		result := map[primitive.ObjectID]interface{}{}
+		for _, t := range theaters {
+			list, _ := result[t.CityID].([]models.Theater)
+			result[t.CityID] = append(list, t)
+		}
+		return result, nil
+	}).Load(c.c.ID)
+	if err != nil {
+		return nil, err
+	}
+	theaters, _ := v.([]models.Theater)
+	return c.r.newTheaters(ctx, theaters), nil
+}
+
+// ------ Session ------
+
+func (s *sessionResolver) ID() graphql.ID           { return graphql.ID(s.s.ID.Hex()) }
+func (s *sessionResolver) StartTime() *graphql.Time { return toTime(s.s.StartTime) }
+func (s *sessionResolver) OpeningTime() string      { return s.s.OpeningTime }
+func (s *sessionResolver) Date() int32              { return int32(s.s.Date) }
+func (s *sessionResolver) TimeZone() string         { return s.s.TimeZone }
+func (s *sessionResolver) Room() int32              { return int32(s.s.Room) }
+func (s *sessionResolver) Version() string          { return s.s.Version }
+func (s *sessionResolver) Format() string           { return s.s.Format }
+func (s *sessionResolver) Type() string             { return s.s.GetType() }
+func (s *sessionResolver) Attributes() []string     { return nonNil(s.s.GetAttributes()) }
+
+func (s *sessionResolver) Movie(ctx context.Context) (*movieResolver, error) {
+	return s.r.loadMovie(ctx, s.s.MovieID)
+}
+
+func (s *sessionResolver) Theater(ctx context.Context) (*theaterResolver, error) {
+	return s.r.loadTheater(ctx, s.s.TheaterID)
+}
+
+// ------ Price ------
+
+func (p *priceResolver) ID() graphql.ID          { return graphql.ID(p.p.ID.Hex()) }
+func (p *priceResolver) Label() string           { return p.p.Label }
+func (p *priceResolver) Full() float64           { return float64(p.p.Full) }
+func (p *priceResolver) Half() float64           { return float64(p.p.Half) }
+func (p *priceResolver) Attributes() []string    { return nonNil(p.p.Attributes) }
+func (p *priceResolver) IncludingPreviews() bool { return p.p.IncludingPreviews }
+func (p *priceResolver) IncludingHolidays() bool { return p.p.IncludingHolidays }
+func (p *priceResolver) ExceptPreviews() bool    { return p.p.ExceptPreviews }
+func (p *priceResolver) ExceptHolidays() bool    { return p.p.ExceptHolidays }
+
+func (p *priceResolver) Weekdays() []int32 {
+	result := make([]int32, len(p.p.Weekdays))
+	for i, w := range p.p.Weekdays {
+		result[i] = int32(w)
+	}
+	return result
+}
+
+// ------ Score ------
+
+func (s *scoreResolver) ID() graphql.ID           { return graphql.ID(s.s.ID.Hex()) }
+func (s *scoreResolver) ImdbID() string           { return s.s.Imdb.ID }
+func (s *scoreResolver) ImdbScore() float64       { return float64(s.s.Imdb.Score) }
+func (s *scoreResolver) RottenPath() string       { return s.s.Rotten.Path }
+func (s *scoreResolver) RottenClass() string      { return s.s.Rotten.Class }
+func (s *scoreResolver) RottenScore() int32       { return int32(s.s.Rotten.Score) }
+func (s *scoreResolver) UpdatedAt() *graphql.Time { return toTime(s.s.UpdatedAt) }
+
+// ------ Image ------
+
+func (i *imageResolver) ID() graphql.ID    { return graphql.ID(i.i.ID.Hex()) }
+func (i *imageResolver) Type() string      { return i.i.Type }
+func (i *imageResolver) Main() bool        { return i.i.Main }
+func (i *imageResolver) URL() string       { return i.i.URL }
+func (i *imageResolver) SecureURL() string { return i.i.SecureURL }
+func (i *imageResolver) Width() int32      { return int32(i.i.Width) }
+func (i *imageResolver) Height() int32     { return int32(i.i.Height) }
+
+// ------ Notification ------
+
+func (n *notificationResolver) ID() graphql.ID   { return graphql.ID(n.n.ID.Hex()) }
+func (n *notificationResolver) Type() string     { return n.n.Type }
+func (n *notificationResolver) Title() string    { return n.n.Title }
+func (n *notificationResolver) Text() string     { return n.n.Text }
+func (n *notificationResolver) HTMLText() string { return n.n.HTMLText }
+func (n *notificationResolver) Single() bool     { return n.n.Single }
+func (n *notificationResolver) ItemID() string   { return n.n.ItemID }
+func (n *notificationResolver) CreatedAt() *graphql.Time {
+	return toTime(&n.n.CreatedAt)
+}
+
+// ------ Helpers ------
+
+func contextLoaders(ctx context.Context) *loaders {
+	return ctx.Value(graphQLLoadersKey).(*loaders)
+}
+
+// requireAdmin checks whether the request has admin claims, like
+// rest.JWTAuth does for admin only endpoints.
+func requireAdmin(ctx context.Context) error {
+	claims, _ := ctx.Value(graphQLClaimsKey).(*rest.Claims)
+	if claims == nil || !claims.IsAdmin() {
+		return errGraphQLUnauthorized
+	}
+	return nil
+}
+
+func parseID(id graphql.ID) (primitive.ObjectID, error) {
+	result, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return result, errGraphQLInvalidID
+	}
+	return result, nil
+}
+
+func page(query persistence.Query, args pageArgs) persistence.Query {
+	limit := int64(-1)
+	if args.Limit != nil {
+		limit = int64(*args.Limit)
+	}
+	query.SetLimit(limit)
+	if args.Skip != nil {
+		query.SetSkip(int64(*args.Skip))
+	}
+	return query
+}
+
+func toTime(t *time.Time) *graphql.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
+
+// strings returns a non nil slice, since lists of the schema are not nullable.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,154 @@
+package v2
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares"
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGraphQL(t *testing.T) {
+	data := NewMockDataAccessLayer()
+
+	r := NewMockRouter(data)
+	r.Use(rest.Init(), middlewares.ValidObjectIDHex(), middlewares.BaseParseQuery())
+
+	s := RESTService{data: data}
+	s.ServeGraphQL(&r.RouterGroup)
+
+	clientAuthToken := getClientAuthToken(t)
+	adminAuthToken := getAdminAuthToken(t)
+
+	query := func(q string) string {
+		return `{"query": "` + q + `"}`
+	}
+	unauthorized := func(res *httptest.ResponseRecorder) {
+		assert.Contains(t, res.Body.String(), errGraphQLUnauthorized.Error())
+	}
+	authorized := func(res *httptest.ResponseRecorder) {
+		assert.NotContains(t, res.Body.String(), `"errors"`)
+	}
+
+	cases := []apiTestCase{
+		apiTestCase{
+			name:   "It should return Unauthorized",
+			method: "POST",
+			url:    "/graphql",
+			body:   query("{ movies { id } }"),
+			status: http.StatusUnauthorized,
+		},
+		apiTestCase{
+			name:       "It should return movies now playing to clients",
+			method:     "POST",
+			url:        "/graphql",
+			body:       query("{ movies { id } }"),
+			status:     http.StatusOK,
+			authToken:  clientAuthToken,
+			onResponse: authorized,
+		},
+	}
+
+	// Admin only fields.
+	for _, q := range []string{
+		"{ movies(filter: ALL) { id } }",
+		"{ theaters { id } }",
+		"{ cities { id } }",
+		"{ notifications { id } }",
+	} {
+		cases = append(cases, apiTestCase{
+			name:       "It should not resolve " + q + " for clients",
+			method:     "POST",
+			url:        "/graphql",
+			body:       query(q),
+			status:     http.StatusOK,
+			authToken:  clientAuthToken,
+			onResponse: unauthorized,
+		}, apiTestCase{
+			name:       "It should resolve " + q + " for admins",
+			method:     "POST",
+			url:        "/graphql",
+			body:       query(q),
+			status:     http.StatusOK,
+			authToken:  adminAuthToken,
+			onResponse: authorized,
+		})
+	}
+
+	r.RunTests(t, cases)
+}
+
+func TestGraphQLSessions(t *testing.T) {
+	data := NewMockDataAccessLayer()
+
+	theaterID := primitive.NewObjectID()
+	movies := []models.Movie{
+		{ID: primitive.NewObjectID(), Title: "Coringa"},
+		{ID: primitive.NewObjectID(), Title: "Frozen 2"},
+	}
+	for _, m := range movies {
+		assert.NoError(t, data.InsertMovie(m))
+	}
+
+	start := time.Now().Add(time.Hour).Truncate(time.Minute)
+	for i := 0; i < 3; i++ {
+		s := models.Session{
+			ID:        primitive.NewObjectID(),
+			TheaterID: theaterID,
+			MovieID:   movies[i%2].ID,
+			Room:      uint(i + 1),
+			StartTime: &start,
+		}
+		assert.NoError(t, data.InsertSession(s))
+	}
+
+	r := &graphQLResolver{data: data}
+	ctx := context.WithValue(context.Background(), graphQLLoadersKey, newLoaders())
+
+	theater := graphql.ID(theaterID.Hex())
+	upcoming := true
+	limit := int32(2)
+	sessions, err := r.Sessions(ctx, sessionsArgs{TheaterID: &theater, Upcoming: &upcoming, Limit: &limit})
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	sessions, err = r.Sessions(ctx, sessionsArgs{TheaterID: &theater, Upcoming: &upcoming})
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 3)
+
+	// Movies of every listed session are loaded with the first one.
+	l := contextLoaders(ctx)
+	assert.ElementsMatch(t, []primitive.ObjectID{movies[0].ID, movies[1].ID}, l.Keys(keyMovies).List())
+	titles := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		m, err := s.Movie(ctx)
+		if assert.NoError(t, err) && assert.NotNil(t, m) {
+			titles = append(titles, m.Title())
+		}
+		if len(titles) == 1 {
+			assert.Len(t, l.Get("movie", keyMovies, nil).cache, 2)
+		}
+	}
+	assert.ElementsMatch(t, []string{"Coringa", "Frozen 2", "Coringa"}, titles)
+
+	// Clients can't list theaters.
+	_, err = r.Theaters(context.WithValue(ctx, graphQLClaimsKey, &rest.Claims{}), struct {
+		CityID *graphql.ID
+		Limit  *int32
+		Skip   *int32
+	}{})
+	assert.Equal(t, errGraphQLUnauthorized, err)
+
+	_, err = data.DeleteSessions(data.DefaultQuery().AddCondition("theaterId", theaterID))
+	assert.NoError(t, err)
+	for _, m := range movies {
+		assert.NoError(t, data.DeleteMovie(m.ID.Hex()))
+	}
+}
@@ -0,0 +1,161 @@
+package v2
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/holidayutil"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HolidayService ...
+type HolidayService struct {
+	data persistence.DataAccessLayer
+}
+
+// ServeHolidays ...
+func (r *RESTService) ServeHolidays(rg *gin.RouterGroup) {
+	s := &HolidayService{r.data}
+
+	client := rg.Group("/holidays", rest.JWTAuth(nil), ValidateRequest())
+	client.GET("", s.GetAll)
+	client.GET("/date/:date", s.GetByDate)
+
+	admin := rg.Group("/holidays", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}), ValidateRequest())
+	admin.GET("/overrides", s.GetOverrides)
+	admin.POST("/overrides", s.CreateOverride)
+	admin.PUT("/override/:id", s.UpdateOverride)
+	admin.DELETE("/override/:id", s.DeleteOverride)
+}
+
+// GetAll gets the holidays of a year, the current one by default, in the
+// city given by ?cityId. Only national holidays are listed without city.
+func (s *HolidayService) GetAll(c *gin.Context) {
+	year := time.Now().Year()
+	if v := c.Query("year"); v != "" {
+		y, err := strconv.Atoi(v)
+		if err != nil {
+			apiutil.SendBadRequest(c)
+			return
+		}
+		year = y
+	}
+
+	city, err := s.getCity(c)
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	calendar, err := LoadHolidayCalendar(s.data)
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	apiutil.SendSuccess(c, calendar.Year(year, city))
+}
+
+// GetByDate gets the holidays of the given date (YYYY-MM-DD) in the city
+// given by ?cityId.
+func (s *HolidayService) GetByDate(c *gin.Context) {
+	date, err := time.Parse(holidayutil.DateFormat, c.Param("date"))
+	if err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+
+	city, err := s.getCity(c)
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	calendar, err := LoadHolidayCalendar(s.data)
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	apiutil.SendSuccess(c, calendar.On(date, city))
+}
+
+// GetOverrides gets the holidays added or removed by admins.
+func (s *HolidayService) GetOverrides(c *gin.Context) {
+	holidays, err := s.data.GetHolidays(s.data.DefaultQuery().SetSort("date").SetLimit(-1))
+	apiutil.SendSuccessOrError(c, holidays, err)
+}
+
+// CreateOverride adds a holiday to the calendar, or removes one if the
+// override is marked as removed.
+func (s *HolidayService) CreateOverride(c *gin.Context) {
+	var holiday models.Holiday
+	if err := c.ShouldBindJSON(&holiday); err != nil || !s.isValidHoliday(&holiday) {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	holiday.ID = primitive.NewObjectID()
+	err := s.data.InsertHoliday(holiday)
+	apiutil.SendSuccessOrError(c, holiday, err)
+}
+
+// UpdateOverride replaces the override with the given ID.
+func (s *HolidayService) UpdateOverride(c *gin.Context) {
+	var holiday models.Holiday
+	if err := c.ShouldBindJSON(&holiday); err != nil || !s.isValidHoliday(&holiday) {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	current, err := s.data.GetHoliday(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	holiday.CreatedAt = current.CreatedAt
+	_, err = s.data.UpdateHoliday(c.Param("id"), holiday)
+	apiutil.SendSuccessOrError(c, holiday, err)
+}
+
+// DeleteOverride removes the override with the given ID.
+func (s *HolidayService) DeleteOverride(c *gin.Context) {
+	err := s.data.DeleteHoliday(c.Param("id"))
+	apiutil.SendSuccessOrError(c, 1, err)
+}
+
+func (s *HolidayService) getCity(c *gin.Context) (*models.City, error) {
+	id := c.Query("cityId")
+	if id == "" {
+		return nil, nil
+	}
+	return s.data.GetCity(id, s.data.DefaultQuery())
+}
+
+// LoadHolidayCalendar creates a holiday calendar with the overrides of admins.
+func LoadHolidayCalendar(data persistence.DataAccessLayer) (*holidayutil.Calendar, error) {
+	overrides, err := data.GetHolidays(data.DefaultQuery().SetLimit(-1))
+	if err != nil {
+		return nil, err
+	}
+	return holidayutil.NewCalendar(overrides), nil
+}
+
+// isValidHoliday checks the override. City overrides must be of an existing
+// city of the given state, if any.
+func (s *HolidayService) isValidHoliday(h *models.Holiday) bool {
+	if _, err := time.Parse(holidayutil.DateFormat, h.Date); err != nil {
+		return false
+	}
+	if h.State != "" {
+		if _, ok := models.GetState(string(h.State)); !ok {
+			return false
+		}
+	}
+	if !h.CityID.IsZero() {
+		city, err := s.data.GetCity(h.CityID.Hex(), s.data.DefaultQuery())
+		if err != nil || (h.State != "" && h.State != city.State) {
+			return false
+		}
+	}
+	return h.Removed || h.Name != ""
+}
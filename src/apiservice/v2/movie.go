@@ -19,19 +19,21 @@ type MovieService struct {
 func (r *RESTService) ServeMovies(rg *gin.RouterGroup) {
 	s := &MovieService{r.data}
 
-	movies := rg.Group("/movies", rest.JWTAuth(nil))
+	movies := rg.Group("/movies", rest.JWTAuth(nil), ValidateRequest())
 
 	movies.GET("/now_playing", s.GetNowPlaying)
 	movies.GET("/upcoming", s.GetUpcoming)
 
 	movies.GET("/movie/:id", s.Get)
-	movies.PUT("/movie/:id", s.Update)
-	movies.DELETE("/movie/:id", s.Delete)
 
 	movies.GET("/movie/:id/showtimes", s.GetSessions)
 	movies.GET("/movie/:id/sessions", s.GetSessions)
 
-	admin := rg.Group("/movies", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}))
+	editor := rg.Group("/movies", rest.JWTAuth(&rest.Endpoint{Scope: models.ScopeMoviesWrite}), ValidateRequest())
+	editor.PUT("/movie/:id", s.Update)
+	editor.DELETE("/movie/:id", s.Delete)
+
+	admin := rg.Group("/movies", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}), ValidateRequest())
 	admin.GET("", s.GetAll)
 	admin.GET("/count", s.Count)
 }
@@ -63,13 +65,25 @@ func (s *MovieService) GetAll(c *gin.Context) {
 	apiutil.SendSuccessOrError(c, movies, err)
 }
 
-// GetSessions gets all showtimes for a given movie
+// GetSessions gets all showtimes for a given movie. It accepts the filters
+// of the session listing, cinema is kept as an alias of theaterId.
 func (s *MovieService) GetSessions(c *gin.Context) {
-	query := BuildSessionQuery(s.data, c).
-		AddCondition("movieId", c.Param("id"))
-	if cinema := c.Query("cinema"); cinema != "" {
-		query.AddCondition("cinemaId", cinema)
+	q := c.MustGet("query_options").(map[string]string)
+	q["movieId"] = c.Param("id")
+	if cinema, ok := q["cinema"]; ok && q["theaterId"] == "" {
+		q["theaterId"] = cinema
+	}
+
+	query, err := buildSessionListQuery(s.data, q)
+	if err == errInvalidSessionFilter {
+		apiutil.SendBadRequest(c)
+		return
 	}
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+
 	showtimes, err := s.data.GetSessions(query)
 	apiutil.SendSuccessOrError(c, showtimes, err)
 }
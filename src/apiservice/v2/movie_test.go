@@ -31,6 +31,7 @@ func TestMovie(t *testing.T) {
 
 	clientAuthToken := getClientAuthToken(t)
 	adminAuthToken := getAdminAuthToken(t)
+	editorAuthToken := getAuthToken(t, "admin", models.Roles[models.RoleEditor])
 
 	cases := []apiTestCase{
 		apiTestCase{
@@ -74,6 +75,29 @@ func TestMovie(t *testing.T) {
 			status:    http.StatusOK,
 			authToken: adminAuthToken,
 		},
+		apiTestCase{
+			name:      "It should return Unauthorized because editors aren't admins",
+			method:    "GET",
+			url:       "/movies/count",
+			status:    http.StatusUnauthorized,
+			authToken: editorAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return Unauthorized because the token can't write movies",
+			method:    "PUT",
+			url:       "/movies/movie/" + HexID,
+			body:      `{"title": "Edited Movie"}`,
+			status:    http.StatusUnauthorized,
+			authToken: clientAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return OK because the token can write movies",
+			method:    "PUT",
+			url:       "/movies/movie/" + HexID,
+			body:      `{"title": "Edited Movie"}`,
+			status:    http.StatusOK,
+			authToken: editorAuthToken,
+		},
 	}
 
 	r.RunTests(t, cases)
@@ -17,11 +17,11 @@ func (r *RESTService) ServeNotifications(rg *gin.RouterGroup) {
 	s := &NotificationService{r.data}
 
 	// Apply ClientAuth only to /notifications/:id path
-	client := rg.Group("/notifications", rest.JWTAuth(nil))
+	client := rg.Group("/notifications", rest.JWTAuth(nil), ValidateRequest())
 	client.GET("/notification/:id", s.Get)
 
 	// Apply AdminAuth only to /notifications
-	admin := rg.Group("/notifications", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}))
+	admin := rg.Group("/notifications", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}), ValidateRequest())
 	admin.GET("", s.GetAll)
 }
 
@@ -0,0 +1,478 @@
+package v2
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares"
+	"github.com/dsbezerra/amenic-lambda/src/lib/openapi"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/tokens"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+	"github.com/gin-gonic/gin"
+)
+
+// Access levels of operations.
+const (
+	accessPublic = iota
+	accessClient
+	accessAdmin
+)
+
+// timeOfDayPattern matches times in HH:MM format.
+const timeOfDayPattern = `^([01]\d|2[0-3]):[0-5]\d$`
+
+var (
+	openAPIOnce sync.Once
+	openAPIDoc  *openapi.Document
+)
+
+// ServeOpenAPI serves the OpenAPI document of the v2 API.
+func (r *RESTService) ServeOpenAPI(rg *gin.RouterGroup) {
+	rg.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, OpenAPIDocument())
+	})
+}
+
+// ValidateRequest middleware checks v2 requests against the OpenAPI document.
+// Route groups use it after rest.JWTAuth, so requests that aren't allowed
+// aren't told which of their fields are invalid.
+func ValidateRequest() gin.HandlerFunc {
+	return middlewares.ValidateRequest(OpenAPIDocument())
+}
+
+// OpenAPIDocument returns the OpenAPI document describing every v2 route.
+func OpenAPIDocument() *openapi.Document {
+	openAPIOnce.Do(func() {
+		openAPIDoc = newOpenAPIDocument()
+	})
+	return openAPIDoc
+}
+
+func newOpenAPIDocument() *openapi.Document {
+	doc := openapi.New("Amenic API", "2.0")
+	doc.Info.Description = "Movies, theaters and sessions of brazilian cinemas."
+	doc.Components.SecuritySchemes["bearer"] = &openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+	}
+	doc.Components.SecuritySchemes["accessToken"] = &openapi.SecurityScheme{
+		Type: "apiKey",
+		In:   openapi.InQuery,
+		Name: "access_token",
+	}
+
+	schemas := doc.Components.Schemas
+	schemas["Error"] = openapi.SchemaOf(apiutil.APIError{})
+	schemas["Attribute"] = openapi.SchemaOf(models.Attribute{})
+	schemas["City"] = openapi.SchemaOf(models.City{})
+	schemas["Holiday"] = openapi.SchemaOf(models.Holiday{})
+	schemas["Movie"] = openapi.SchemaOf(models.Movie{})
+	schemas["Notification"] = openapi.SchemaOf(models.Notification{})
+	schemas["Price"] = openapi.SchemaOf(models.Price{})
+	schemas["Score"] = openapi.SchemaOf(models.Score{})
+	schemas["Session"] = openapi.SchemaOf(models.Session{})
+	schemas["Theater"] = openapi.SchemaOf(models.Theater{})
+
+	id := pathParam("id", openapi.ObjectID())
+
+	// Auth
+	doc.AddOperation("POST", "/v2/auth", operation("auth", "Logs in an admin", accessPublic, "AuthResponse").
+		WithBody(openapi.SchemaOf(LoginBody{}).WithRequired("username", "password")))
+	doc.AddOperation("GET", "/v2/auth/request_token", operation("auth", "Requests a client token for the platform in the User-Agent header", accessPublic, "AuthResponse"))
+	doc.AddOperation("POST", "/v2/auth/refresh", operation("auth", "Exchanges a refresh token for new tokens", accessPublic, "AuthResponse").
+		WithBody(openapi.SchemaOf(RefreshBody{}).WithRequired("refresh_token")))
+	logout := operation("auth", "Revokes the access token of the request and the refresh token in the body", accessPublic, "")
+	logout.RequestBody = openapi.JSONBody(openapi.SchemaOf(RefreshBody{}), false)
+	logout.Security = []map[string][]string{{}, {"bearer": {}}, {"accessToken": {}}}
+	logout.Responses["401"] = jsonResponse("Unauthorized", responseSchema(""))
+	doc.AddOperation("POST", "/v2/auth/logout", logout)
+	jwks := operation("auth", "Gets the public keys verifying tokens", accessPublic, "")
+	jwks.Responses["200"] = jsonResponse("OK", openapi.Ref("JWKS"))
+	doc.AddOperation("GET", "/v2/auth/jwks.json", jwks)
+	doc.AddOperation("POST", "/v2/auth/keys/rotate", operation("auth", "Creates a new key signing tokens", accessAdmin, "JWK"))
+	schemas["AuthResponse"] = openapi.SchemaOf(AuthResponse{})
+	schemas["JWK"] = openapi.SchemaOf(tokens.JWK{})
+	schemas["JWKS"] = openapi.SchemaOf(tokens.JWKS{})
+
+	// API keys
+	userType := openapi.String().WithEnum(models.UserTypeAdmin, models.UserTypeClient)
+	role := openapi.String().WithEnum(stringValues(models.RoleAdmin, models.RoleEditor, models.RoleClient)...)
+	scopes := openapi.ArrayOf(openapi.String().WithEnum(stringValues(models.Scopes...)...))
+	apikey := openapi.SchemaOf(APIKeyBody{}).WithRequired("name", "user_type")
+	apikey.Properties["user_type"] = userType
+	apikey.Properties["role"] = role
+	apikey.Properties["scopes"] = scopes
+	scopesBody := openapi.SchemaOf(ScopesBody{})
+	scopesBody.Properties["role"] = role
+	scopesBody.Properties["scopes"] = scopes
+	doc.AddOperation("GET", "/v2/apikeys", operation("apikeys", "Lists API keys", accessAdmin, "[]APIKey").
+		WithParameters(
+			queryParam("owner", "", openapi.String()),
+			queryParam("user_type", "", userType),
+			queryParam("platform", "", openapi.String()),
+			queryParam("revoked", "Includes revoked keys", openapi.Boolean())))
+	doc.AddOperation("POST", "/v2/apikeys", operation("apikeys", "Creates an API key", accessAdmin, "NewAPIKey").
+		WithBody(apikey))
+	doc.AddOperation("GET", "/v2/apikeys/apikey/:id", operation("apikeys", "Gets an API key", accessAdmin, "APIKey").WithParameters(id))
+	doc.AddOperation("POST", "/v2/apikeys/apikey/:id/rotate", operation("apikeys", "Replaces an API key, revoking its tokens", accessAdmin, "NewAPIKey").
+		WithParameters(id))
+	expire := operation("apikeys", "Expires an API key, now by default", accessAdmin, "APIKey").WithParameters(id)
+	expire.RequestBody = openapi.JSONBody(openapi.SchemaOf(ExpireBody{}), false)
+	doc.AddOperation("POST", "/v2/apikeys/apikey/:id/expire", expire)
+	doc.AddOperation("POST", "/v2/apikeys/apikey/:id/revoke", operation("apikeys", "Revokes an API key and its tokens", accessAdmin, "APIKey").
+		WithParameters(id))
+	doc.AddOperation("POST", "/v2/apikeys/apikey/:id/scopes", operation("apikeys", "Replaces the role and scopes of an API key", accessAdmin, "APIKey").
+		WithParameters(id).WithBody(scopesBody))
+	schemas["APIKey"] = openapi.SchemaOf(models.APIKey{})
+	schemas["NewAPIKey"] = openapi.SchemaOf(NewAPIKey{})
+
+	// Attributes
+	doc.AddOperation("GET", "/v2/attributes", operation("attributes", "Lists session attributes", accessClient, "[]Attribute"))
+	doc.AddOperation("GET", "/v2/attributes/attribute/:id", operation("attributes", "Gets a session attribute", accessClient, "Attribute").
+		WithParameters(pathParam("id", openapi.String())))
+
+	// Calendars
+	doc.AddOperation("GET", "/v2/calendar/session/:id", calendarOperation("Gets the calendar of a session", accessClient).WithParameters(id))
+	doc.AddOperation("GET", "/v2/calendar/theater/:id", calendarOperation("Gets the calendar of a theater in a day or in the current week", accessClient).
+		WithParameters(id, queryParam("date", "Day in YYYY-MM-DD format", openapi.Date())))
+	doc.AddOperation("GET", "/v2/calendar/theater/:id/movie/:movieId", calendarOperation("Gets the calendar of a movie at a theater", accessClient).
+		WithParameters(id, pathParam("movieId", openapi.ObjectID())))
+	doc.AddOperation("GET", "/v2/calendar/theater/:id/feed", calendarOperation("Gets the subscribable calendar of a theater", accessPublic).WithParameters(id))
+	doc.AddOperation("GET", "/v2/calendar/theater/:id/subscription", operation("calendars", "Gets the feed address of a theater", accessClient, "Subscription").
+		WithParameters(id))
+	schemas["Subscription"] = openapi.SchemaOf(Subscription{})
+
+	// Cities
+	doc.AddOperation("GET", "/v2/cities/", operation("cities", "Lists cities", accessAdmin, "[]City").
+		WithParameters(listParams()...).
+		WithParameters(queryParam("name", "", openapi.String()), queryParam("state", "", openapi.String())))
+	doc.AddOperation("GET", "/v2/cities/city/:id", operation("cities", "Gets a city", accessAdmin, "City").WithParameters(id))
+	city := openapi.SchemaOf(models.City{}).WithRequired("name", "state")
+	city.Properties["state"] = openapi.String().WithEnum(stringValues(stateIDs()...)...)
+	doc.AddOperation("POST", "/v2/cities", operation("cities", "Creates a city", accessAdmin, "City").WithBody(city))
+	doc.AddOperation("PUT", "/v2/cities/city/:id", operation("cities", "Updates a city", accessAdmin, "City").
+		WithParameters(id).WithBody(city))
+	doc.AddOperation("DELETE", "/v2/cities/city/:id", withConflict(operation("cities", "Deletes a city without theaters or holidays", accessAdmin, "")).
+		WithParameters(id))
+
+	// GraphQL
+	doc.AddOperation("GET", "/v2/graphql", operation("graphql", "Executes a GraphQL query", accessClient, "").
+		WithParameters(
+			requiredQueryParam("query", "GraphQL query", openapi.String()),
+			queryParam("operationName", "", openapi.String()),
+			queryParam("variables", "Variables in JSON format", openapi.String())))
+	doc.AddOperation("POST", "/v2/graphql", operation("graphql", "Executes a GraphQL query", accessClient, "").
+		WithBody(openapi.SchemaOf(graphQLRequest{}).WithRequired("query")))
+
+	// Holidays
+	holiday := openapi.SchemaOf(models.Holiday{}).WithRequired("date", "name")
+	holiday.Properties["date"] = openapi.Date()
+	doc.AddOperation("GET", "/v2/holidays", operation("holidays", "Lists the holidays of a year", accessClient, "[]Holiday").
+		WithParameters(
+			queryParam("year", "Defaults to the current year", openapi.Integer().WithMinimum(1900)),
+			queryParam("cityId", "Includes the state and municipal holidays of the city", openapi.ObjectID())))
+	doc.AddOperation("GET", "/v2/holidays/date/:date", operation("holidays", "Gets the holiday of a date", accessClient, "Holiday").
+		WithParameters(pathParam("date", openapi.Date()), queryParam("cityId", "", openapi.ObjectID())))
+	doc.AddOperation("GET", "/v2/holidays/overrides", operation("holidays", "Lists holiday overrides", accessAdmin, "[]Holiday"))
+	doc.AddOperation("POST", "/v2/holidays/overrides", operation("holidays", "Creates a holiday override", accessAdmin, "Holiday").
+		WithBody(holiday))
+	doc.AddOperation("PUT", "/v2/holidays/override/:id", operation("holidays", "Updates a holiday override", accessAdmin, "Holiday").
+		WithParameters(id).WithBody(holiday))
+	doc.AddOperation("DELETE", "/v2/holidays/override/:id", operation("holidays", "Deletes a holiday override", accessAdmin, "").
+		WithParameters(id))
+
+	// Movies
+	doc.AddOperation("GET", "/v2/movies/now_playing", operation("movies", "Lists now playing movies", accessClient, "[]Movie").
+		WithParameters(listParams()...).
+		WithParameters(
+			queryParam("theaterId", "", openapi.ObjectID()),
+			queryParam("theaterIds", "Comma separated theater IDs", openapi.ArrayOf(openapi.ObjectID())),
+			queryParam("attributes", "Comma separated attribute IDs", openapi.ArrayOf(openapi.String())),
+			queryParam("version", "Comma separated versions", openapi.ArrayOf(openapi.String()))))
+	doc.AddOperation("GET", "/v2/movies/upcoming", operation("movies", "Lists upcoming movies", accessClient, "[]Movie").
+		WithParameters(listParams()...).
+		WithParameters(movieFilters()...))
+	doc.AddOperation("GET", "/v2/movies/movie/:id", operation("movies", "Gets a movie", accessClient, "Movie").
+		WithParameters(id).WithParameters(queryParam("fields", "", openapi.String()), queryParam("include", "", openapi.String())))
+	doc.AddOperation("PUT", "/v2/movies/movie/:id", scopedOperation("movies", "Updates a movie", models.ScopeMoviesWrite, "Movie").
+		WithParameters(id).WithBody(openapi.SchemaOf(models.Movie{})))
+	doc.AddOperation("DELETE", "/v2/movies/movie/:id", scopedOperation("movies", "Deletes a movie", models.ScopeMoviesWrite, "").WithParameters(id))
+	for _, path := range []string{"/v2/movies/movie/:id/showtimes", "/v2/movies/movie/:id/sessions"} {
+		doc.AddOperation("GET", path, operation("movies", "Lists the sessions of a movie", accessClient, "[]Session").
+			WithParameters(id, queryParam("cinema", "Alias of theaterId", openapi.ObjectID())).
+			WithParameters(sessionFilters()...))
+	}
+	doc.AddOperation("GET", "/v2/movies", operation("movies", "Lists movies", accessAdmin, "[]Movie").
+		WithParameters(listParams()...).
+		WithParameters(movieFilters()...))
+	doc.AddOperation("GET", "/v2/movies/count", operation("movies", "Counts movies", accessAdmin, "").
+		WithParameters(movieFilters()...))
+
+	// Notifications
+	doc.AddOperation("GET", "/v2/notifications/notification/:id", operation("notifications", "Gets a notification", accessClient, "Notification").
+		WithParameters(id))
+	doc.AddOperation("GET", "/v2/notifications", operation("notifications", "Lists notifications", accessAdmin, "[]Notification").
+		WithParameters(listParams()...))
+
+	// OpenAPI
+	doc.AddOperation("GET", "/v2/openapi.json", operation("openapi", "Gets this document", accessPublic, ""))
+
+	// Prices
+	doc.AddOperation("GET", "/v2/prices/price/:id", operation("prices", "Gets a price", accessClient, "Price").WithParameters(id))
+	doc.AddOperation("GET", "/v2/prices", operation("prices", "Lists prices", accessAdmin, "[]Price").
+		WithParameters(listParams()...).
+		WithParameters(queryParam("theaterId", "", openapi.ObjectID())))
+	price := openapi.SchemaOf(models.Price{}).WithRequired("theaterId", "label", "full")
+	price.Properties["weekdays"] = openapi.ArrayOf(openapi.Integer().WithMinimum(0).WithMaximum(6))
+	doc.AddOperation("POST", "/v2/prices", scopedOperation("prices", "Creates a price", models.ScopeTheatersWrite, "Price").
+		WithBody(price))
+	doc.AddOperation("PUT", "/v2/prices/price/:id", scopedOperation("prices", "Updates a price", models.ScopeTheatersWrite, "Price").
+		WithParameters(id).WithBody(price))
+	doc.AddOperation("DELETE", "/v2/prices/price/:id", scopedOperation("prices", "Deletes a price", models.ScopeTheatersWrite, "").
+		WithParameters(id))
+
+	// Schedules
+	doc.AddOperation("GET", "/v2/schedules", operation("schedules", "Lists the schedules of a theater", accessClient, "").
+		WithParameters(
+			requiredQueryParam("theaterId", "", openapi.ObjectID()),
+			queryParam("movieId", "", openapi.ObjectID()),
+			queryParam("date", "Day in YYYY-MM-DD format, in the theater time zone", openapi.Date()),
+			queryParam("attributes", "Comma separated attribute IDs", openapi.ArrayOf(openapi.String())),
+			queryParam("price", "Includes the price of each session", openapi.Boolean())))
+
+	// Scores
+	doc.AddOperation("GET", "/v2/scores", operation("scores", "Lists scores", accessAdmin, "[]Score").
+		WithParameters(listParams()...).
+		WithParameters(queryParam("movieId", "", openapi.ObjectID())))
+	doc.AddOperation("GET", "/v2/scores/score/:id", operation("scores", "Gets a score", accessAdmin, "Score").WithParameters(id))
+
+	// Scrapers
+	scraperType := openapi.String().WithEnum(stringValues(scraperutil.Types...)...)
+	scraperProvider := openapi.String().WithEnum(stringValues(scraperProviders()...)...)
+	scraper := openapi.SchemaOf(models.Scraper{}).WithRequired("theater_id", "type", "provider")
+	scraper.Properties["type"] = scraperType
+	scraper.Properties["provider"] = scraperProvider
+	doc.AddOperation("GET", "/v2/scrapers", operation("scrapers", "Lists scrapers", accessAdmin, "[]Scraper").
+		WithParameters(
+			queryParam("theater_id", "", openapi.ObjectID()),
+			queryParam("type", "", scraperType),
+			queryParam("provider", "", scraperProvider)))
+	doc.AddOperation("POST", "/v2/scrapers", withConflict(operation("scrapers", "Attaches a scraper to a theater", accessAdmin, "Scraper")).
+		WithBody(scraper))
+	doc.AddOperation("GET", "/v2/scrapers/scraper/:id", operation("scrapers", "Gets a scraper", accessAdmin, "Scraper").WithParameters(id))
+	doc.AddOperation("PUT", "/v2/scrapers/scraper/:id", withConflict(operation("scrapers", "Updates a scraper", accessAdmin, "Scraper")).
+		WithParameters(id).WithBody(scraper))
+	doc.AddOperation("DELETE", "/v2/scrapers/scraper/:id", operation("scrapers", "Deletes a scraper and its queued jobs", accessAdmin, "").
+		WithParameters(id))
+	schemas["Scraper"] = openapi.SchemaOf(models.Scraper{})
+
+	// Sessions
+	doc.AddOperation("GET", "/v2/sessions", operation("sessions", "Lists sessions", accessClient, "[]Session").
+		WithParameters(sessionFilters()...))
+	doc.AddOperation("GET", "/v2/sessions/session/:id", operation("sessions", "Gets a session", accessClient, "Session").WithParameters(id))
+	doc.AddOperation("GET", "/v2/sessions/session/:id/price", operation("sessions", "Gets the price of a session", accessClient, "Price").
+		WithParameters(id))
+
+	// States
+	state := pathParam("id", openapi.String().WithPattern("^[A-Za-z]{2}$"))
+	doc.AddOperation("GET", "/v2/states", operation("states", "Lists states", accessAdmin, ""))
+	doc.AddOperation("GET", "/v2/states/state/:id", operation("states", "Gets a state", accessAdmin, "").WithParameters(state))
+	doc.AddOperation("GET", "/v2/states/state/:id/cities", operation("states", "Lists the cities of a state", accessAdmin, "[]City").
+		WithParameters(state))
+
+	// Theaters
+	doc.AddOperation("GET", "/v2/theaters/theater/:id", operation("theaters", "Gets a theater", accessClient, "Theater").
+		WithParameters(id).WithParameters(queryParam("fields", "", openapi.String()), queryParam("include", "", openapi.String())))
+	doc.AddOperation("GET", "/v2/theaters/theater/:id/prices", operation("theaters", "Lists the prices of a theater", accessClient, "[]Price").
+		WithParameters(id).WithParameters(listParams()...))
+	doc.AddOperation("GET", "/v2/theaters/theater/:id/sessions", operation("theaters", "Lists the sessions of a theater", accessClient, "[]Session").
+		WithParameters(id).WithParameters(listParams()...))
+	doc.AddOperation("GET", "/v2/theaters", operation("theaters", "Lists theaters", accessAdmin, "[]Theater").
+		WithParameters(listParams()...).
+		WithParameters(theaterFilters()...))
+	doc.AddOperation("GET", "/v2/theaters/count", operation("theaters", "Counts theaters", accessAdmin, "").
+		WithParameters(theaterFilters()...))
+	theater := openapi.SchemaOf(models.Theater{}).WithRequired("name", "cityId")
+	doc.AddOperation("POST", "/v2/theaters", scopedOperation("theaters", "Creates a theater", models.ScopeTheatersWrite, "Theater").
+		WithBody(theater))
+	doc.AddOperation("PUT", "/v2/theaters/theater/:id", scopedOperation("theaters", "Updates a theater", models.ScopeTheatersWrite, "Theater").
+		WithParameters(id).WithBody(theater))
+	doc.AddOperation("DELETE", "/v2/theaters/theater/:id", withConflict(scopedOperation("theaters", "Deletes a theater and its prices", models.ScopeTheatersWrite, "")).
+		WithParameters(id, queryParam("cascade", "Deletes its sessions and scrapers too, admin only", openapi.Boolean())))
+
+	return doc
+}
+
+// operation creates an operation. The data is the name of the response data
+// schema in the components, prefixed with [] for lists.
+func operation(tag, summary string, access int, data string) *openapi.Operation {
+	op := &openapi.Operation{
+		Summary: summary,
+		Tags:    []string{tag},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("OK", responseSchema(data)),
+			"400": jsonResponse("Bad request or invalid fields", responseSchema("")),
+			"429": jsonResponse("Too many requests", responseSchema("")),
+		},
+	}
+	switch access {
+	case accessAdmin:
+		op.Summary += " (admin only)"
+		fallthrough
+	case accessClient:
+		op.Security = []map[string][]string{{"bearer": {}}, {"accessToken": {}}}
+		op.Responses["401"] = jsonResponse("Unauthorized", responseSchema(""))
+	}
+	return op
+}
+
+// scopedOperation creates an operation allowed to admins and tokens with the
+// given scope.
+func scopedOperation(tag, summary, scope, data string) *openapi.Operation {
+	op := operation(tag, summary, accessClient, data)
+	op.Summary += " (requires " + scope + ")"
+	return op
+}
+
+// withConflict adds the response of requests conflicting with other
+// resources to the operation and returns it.
+func withConflict(op *openapi.Operation) *openapi.Operation {
+	op.Responses["409"] = jsonResponse("Resource in use or already exists", responseSchema(""))
+	return op
+}
+
+// calendarOperation creates an operation with an iCalendar response.
+func calendarOperation(summary string, access int) *openapi.Operation {
+	op := operation("calendars", summary, access, "")
+	op.Responses["200"] = &openapi.Response{
+		Description: "OK",
+		Content:     map[string]*openapi.MediaType{"text/calendar": {Schema: openapi.String()}},
+	}
+	return op
+}
+
+// responseSchema creates the schema of an APIResponse with the given data.
+func responseSchema(data string) *openapi.Schema {
+	result := openapi.SchemaOf(apiutil.APIResponse{})
+	result.Properties["error"] = openapi.Ref("Error")
+	switch {
+	case data == "":
+		delete(result.Properties, "data")
+	case strings.HasPrefix(data, "[]"):
+		result.Properties["data"] = openapi.ArrayOf(openapi.Ref(data[2:]))
+	default:
+		result.Properties["data"] = openapi.Ref(data)
+	}
+	return result
+}
+
+func jsonResponse(description string, schema *openapi.Schema) *openapi.Response {
+	return &openapi.Response{
+		Description: description,
+		Content:     map[string]*openapi.MediaType{openapi.ContentJSON: {Schema: schema}},
+	}
+}
+
+// stringValues converts strings to enum values.
+func stringValues(values ...string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
+
+// stateIDs returns the IDs of every state.
+func stateIDs() []string {
+	states := models.GetStateList()
+	result := make([]string, len(states))
+	for i, s := range states {
+		result[i] = string(s)
+	}
+	return result
+}
+
+// scraperProviders returns the registered providers, sorted.
+func scraperProviders() []string {
+	result := make([]string, 0, len(scraperutil.Providers))
+	for name := range scraperutil.Providers {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func pathParam(name string, schema *openapi.Schema) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: openapi.InPath, Required: true, Schema: schema}
+}
+
+func queryParam(name, description string, schema *openapi.Schema) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: openapi.InQuery, Description: description, Schema: schema}
+}
+
+func requiredQueryParam(name, description string, schema *openapi.Schema) *openapi.Parameter {
+	p := queryParam(name, description, schema)
+	p.Required = true
+	return p
+}
+
+// listParams are the parameters handled by BaseParseQuery.
+func listParams() []*openapi.Parameter {
+	return []*openapi.Parameter{
+		queryParam("limit", "Values above 50 are lowered to 50", openapi.Integer().WithMinimum(-1)),
+		queryParam("skip", "", openapi.Integer().WithMinimum(0)),
+		queryParam("sort", "Comma separated fields, prefixed with - for descending order", openapi.String()),
+		queryParam("fields", "Comma separated fields to return", openapi.String()),
+		queryParam("include", "Comma separated relations to include", openapi.String()),
+	}
+}
+
+func movieFilters() []*openapi.Parameter {
+	return []*openapi.Parameter{
+		queryParam("claqueteId", "", openapi.String()),
+		queryParam("tmdbId", "", openapi.String()),
+		queryParam("imdbId", "", openapi.String()),
+		queryParam("hidden", "", openapi.Boolean()),
+		queryParam("backdrop", "Whether the movie has a backdrop", openapi.Boolean()),
+		queryParam("poster", "Whether the movie has a poster", openapi.Boolean()),
+		queryParam("trailer", "Whether the movie has a trailer", openapi.Boolean()),
+		queryParam("rating", "Either -1 or an age from 10 to 18", openapi.Integer().WithMinimum(-1).WithMaximum(18)),
+		queryParam("search", "Text in the title or original title", openapi.String()),
+	}
+}
+
+func theaterFilters() []*openapi.Parameter {
+	return []*openapi.Parameter{
+		queryParam("internalId", "", openapi.String()),
+		queryParam("hidden", "", openapi.Boolean()),
+		queryParam("search", "Text in the name or short name", openapi.String()),
+	}
+}
+
+// sessionFilters are the parameters handled by buildSessionListQuery.
+func sessionFilters() []*openapi.Parameter {
+	timeOfDay := openapi.String().WithPattern(timeOfDayPattern)
+	return []*openapi.Parameter{
+		queryParam("theaterId", "", openapi.ObjectID()),
+		queryParam("theaterIds", "Comma separated theater IDs", openapi.ArrayOf(openapi.ObjectID())),
+		queryParam("cityId", "Sessions of every theater of the city", openapi.ObjectID()),
+		queryParam("movieId", "", openapi.ObjectID()),
+		queryParam("start", "First day in YYYY-MM-DD format, in the theater time zone", openapi.Date()),
+		queryParam("end", "Last day in YYYY-MM-DD format, in the theater time zone", openapi.Date()),
+		queryParam("timeFrom", "Earliest start time in HH:MM format", timeOfDay),
+		queryParam("timeTo", "Latest start time in HH:MM format", timeOfDay),
+		queryParam("attributes", "Comma separated attribute IDs", openapi.ArrayOf(openapi.String())),
+		queryParam("version", "Comma separated versions", openapi.ArrayOf(openapi.String())),
+		queryParam("upcoming", "Only sessions that didn't start yet", openapi.Boolean()),
+		queryParam("page", "Page of the results, starting at 1", openapi.Integer().WithMinimum(1)),
+		queryParam("limit", "Page size, at most 50", openapi.Integer().WithMinimum(1)),
+		queryParam("sort", "", openapi.String()),
+		queryParam("fields", "", openapi.String()),
+		queryParam("include", "", openapi.String()),
+	}
+}
@@ -0,0 +1,76 @@
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares"
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPI(t *testing.T) {
+	if rest.JWTSecret == "" {
+		rest.JWTSecret = "openapi-test"
+	}
+
+	r := gin.New()
+	AddRoutes(r, nil, nil)
+
+	doc := OpenAPIDocument()
+	for _, route := range r.Routes() {
+		if strings.HasPrefix(route.Path, "/v2/") {
+			assert.NotNil(t, doc.Operation(route.Method, route.Path), route.Method+" "+route.Path+" is not documented")
+		}
+	}
+
+	call := func(method, url, body, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		return res
+	}
+
+	res := call("GET", "/v2/openapi.json", "", "")
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	// Requests are authenticated before they are validated.
+	res = call("GET", "/v2/sessions?page=0&timeFrom=25:00&theaterId=invalid", "", "")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+	res = call("GET", "/v2/sessions?page=0&timeFrom=25:00&theaterId=invalid", "", getClientAuthToken(t))
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+	var body apiutil.APIResponse
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+	if assert.NotNil(t, body.Error) {
+		assert.Equal(t, "validation_failed", body.Error.Code)
+		fields := make([]string, 0)
+		for _, d := range body.Error.Details {
+			fields = append(fields, d.Field)
+		}
+		assert.ElementsMatch(t, []string{"page", "timeFrom", "theaterId"}, fields)
+	}
+
+	url := "/v2/movies/movie/5c353e8cebd54428b4f25447"
+	res = call("PUT", url, `{"title": 1, "runtime": "120"}`, "")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+	res = call("PUT", url, `{"title": 1, "runtime": "120"}`, getAdminAuthToken(t))
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Contains(t, res.Body.String(), `"field":"runtime"`)
+	assert.Contains(t, res.Body.String(), `"field":"title"`)
+
+	large := `{"title": "` + strings.Repeat("a", middlewares.MaxBodySize) + `"}`
+	res = call("PUT", url, large, getAdminAuthToken(t))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+}
@@ -1,12 +1,28 @@
 package v2
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/holidayutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/priceutil"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// sessionPricer resolves the prices of sessions of a theater.
+type sessionPricer struct {
+	prices   []models.Price
+	city     *models.City
+	calendar *holidayutil.Calendar
+}
+
 // PriceService ...
 type PriceService struct {
 	data persistence.DataAccessLayer
@@ -16,11 +32,17 @@ type PriceService struct {
 func (r *RESTService) ServePrices(rg *gin.RouterGroup) {
 	s := &PriceService{r.data}
 
-	client := rg.Group("/prices", rest.JWTAuth(nil))
+	client := rg.Group("/prices", rest.JWTAuth(nil), ValidateRequest())
 	client.GET("/price/:id", s.Get)
 
-	admin := rg.Group("/prices", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}))
+	admin := rg.Group("/prices", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}), ValidateRequest())
 	admin.GET("", s.GetAll)
+
+	// Prices belong to theaters, so they're edited with the same scope.
+	editor := rg.Group("/prices", rest.JWTAuth(&rest.Endpoint{Scope: models.ScopeTheatersWrite}), ValidateRequest())
+	editor.POST("", s.Create)
+	editor.PUT("/price/:id", s.Update)
+	editor.DELETE("/price/:id", s.Delete)
 }
 
 // Get gets the price corresponding the requested ID.
@@ -35,8 +57,152 @@ func (s *PriceService) GetAll(c *gin.Context) {
 	apiutil.SendSuccessOrError(c, prices, err)
 }
 
+// Create creates a price of an existing theater. Its weight, which breaks
+// ties between matching prices, is given by its attributes.
+func (s *PriceService) Create(c *gin.Context) {
+	price := models.Price{}
+	if err := c.ShouldBindJSON(&price); err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	details, err := validatePrice(s.data, &price)
+	if sendInvalid(c, details, err) {
+		return
+	}
+	now := time.Now().UTC()
+	price.ID = primitive.NewObjectID()
+	price.Weight = models.GetAttributesWeight(price.Attributes)
+	price.CreatedAt = &now
+	err = s.data.InsertPrice(price)
+	apiutil.SendSuccessOrError(c, price, err)
+}
+
+// Update replaces the price with the given ID.
+func (s *PriceService) Update(c *gin.Context) {
+	price := models.Price{}
+	if err := c.ShouldBindJSON(&price); err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	current, err := s.data.GetPrice(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	details, err := validatePrice(s.data, &price)
+	if sendInvalid(c, details, err) {
+		return
+	}
+	price.ID = current.ID
+	price.Weight = models.GetAttributesWeight(price.Attributes)
+	price.CreatedAt = current.CreatedAt
+	_, err = s.data.UpdatePrice(c.Param("id"), price)
+	apiutil.SendSuccessOrError(c, price, err)
+}
+
+// Delete removes the price with the given ID.
+func (s *PriceService) Delete(c *gin.Context) {
+	if _, err := s.data.GetPrice(c.Param("id"), s.data.DefaultQuery()); err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	err := s.data.DeletePrice(c.Param("id"))
+	apiutil.SendSuccessOrError(c, 1, err)
+}
+
 // BuildPriceQuery builds price query from request query string
 func BuildPriceQuery(data persistence.DataAccessLayer, c *gin.Context) persistence.Query {
 	query := c.MustGet("query_options").(map[string]string)
 	return data.BuildPriceQuery(query)
 }
+
+// validatePrice checks the price belongs to an existing theater, has a label,
+// a full price and known weekdays and attributes. The theater included in
+// responses is removed, so it isn't stored.
+func validatePrice(data persistence.DataAccessLayer, price *models.Price) ([]*apiutil.APIErrorDetail, error) {
+	price.Theater = nil
+
+	details := []*apiutil.APIErrorDetail{}
+	if price.TheaterID.IsZero() {
+		details = append(details, bodyError("theaterId", "is required"))
+	} else if _, err := data.GetTheater(price.TheaterID.Hex(), data.DefaultQuery()); err == mongo.ErrNoDocuments {
+		details = append(details, bodyError("theaterId", "doesn't exist"))
+	} else if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(price.Label) == "" {
+		details = append(details, bodyError("label", "is required"))
+	}
+	if price.Full <= 0 {
+		details = append(details, bodyError("full", "must be greater than 0"))
+	}
+	if price.Half < 0 {
+		details = append(details, bodyError("half", "must not be negative"))
+	}
+	for i, w := range price.Weekdays {
+		if w < time.Sunday || w > time.Saturday {
+			details = append(details, bodyError(fmt.Sprintf("weekdays[%d]", i), "is not a weekday"))
+		}
+	}
+	for i, a := range price.Attributes {
+		if _, ok := models.GetAttribute(a); !ok {
+			details = append(details, bodyError(fmt.Sprintf("attributes[%d]", i), "is not an attribute"))
+		}
+	}
+	return details, nil
+}
+
+// newSessionPricer loads what's needed to resolve prices of sessions of the
+// given theater.
+func newSessionPricer(data persistence.DataAccessLayer, theaterID primitive.ObjectID) (*sessionPricer, error) {
+	theater, err := data.GetTheater(theaterID.Hex(), data.DefaultQuery())
+	if err != nil {
+		return nil, err
+	}
+
+	var city *models.City
+	if !theater.CityID.IsZero() {
+		city, err = data.GetCity(theater.CityID.Hex(), data.DefaultQuery())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	prices, err := data.GetPrices(data.DefaultQuery().
+		AddCondition("theaterId", theater.ID).
+		SetLimit(-1))
+	if err != nil {
+		return nil, err
+	}
+
+	calendar, err := LoadHolidayCalendar(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sessionPricer{prices: prices, city: city, calendar: calendar}, nil
+}
+
+// Resolve finds the price of the session. Previews are sessions of type
+// preview.
+func (p *sessionPricer) Resolve(session *models.Session) (*priceutil.Resolution, error) {
+	if session.StartTime == nil {
+		return nil, priceutil.ErrNoPrice
+	}
+
+	start := session.StartTime.In(p.location(session))
+	return priceutil.Resolve(p.prices, priceutil.Session{
+		StartTime:  start,
+		Format:     session.Format,
+		Attributes: session.GetAttributes(),
+		Preview:    session.GetType() == models.SessionTypePreview,
+		City:       p.city,
+	}, p.calendar)
+}
+
+func (p *sessionPricer) location(session *models.Session) *time.Location {
+	if session.TimeZone == "" {
+		return p.city.Location()
+	}
+	return session.Location()
+}
@@ -0,0 +1,93 @@
+package v2
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares"
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPrice(t *testing.T) {
+	data := NewMockDataAccessLayer()
+
+	r := NewMockRouter(data)
+	r.Use(rest.Init(), middlewares.ValidObjectIDHex(), middlewares.BaseParseQuery())
+
+	s := RESTService{data: data}
+	s.ServePrices(&r.RouterGroup)
+
+	testTheater := models.Theater{
+		ID:        primitive.NewObjectID(),
+		Name:      "Fake Theater",
+		ShortName: "Fake",
+	}
+	assert.NoError(t, data.InsertTheater(testTheater))
+	defer data.DeleteTheater(testTheater.ID.Hex())
+
+	adminAuthToken := getAdminAuthToken(t)
+
+	var price models.Price
+	body := fmt.Sprintf(`{"theaterId": %q, "label": "3D Magic D", "full": 30, "attributes": [%q, %q]}`,
+		testTheater.ID.Hex(), models.Attribute3D, models.AttributeMagicD)
+	res := r.Call("POST", "/prices", body, adminAuthToken)
+	if !assert.Equal(t, http.StatusOK, res.Code) {
+		return
+	}
+	ConvertAPIResponse(res, &price)
+
+	// The weight is given by the attributes.
+	stored, err := data.GetPrice(price.ID.Hex(), data.DefaultQuery())
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.GetAttributesWeight([]string{models.Attribute3D, models.AttributeMagicD}), stored.Weight)
+		assert.NotNil(t, stored.CreatedAt)
+	}
+
+	url := "/prices/price/" + price.ID.Hex()
+	cases := []apiTestCase{
+		{
+			name:      "It should return BadRequest since the attribute is unknown",
+			method:    "PUT",
+			url:       url,
+			body:      fmt.Sprintf(`{"theaterId": %q, "label": "VIP", "full": 40, "attributes": ["Poltrona VIP"]}`, testTheater.ID.Hex()),
+			status:    http.StatusBadRequest,
+			authToken: adminAuthToken,
+		},
+		{
+			name:      "It should return OK",
+			method:    "PUT",
+			url:       url,
+			body:      fmt.Sprintf(`{"theaterId": %q, "label": "2D", "full": 20, "attributes": [%q]}`, testTheater.ID.Hex(), models.Attribute2D),
+			status:    http.StatusOK,
+			authToken: adminAuthToken,
+			onResponse: func(res *httptest.ResponseRecorder) {
+				stored, err := data.GetPrice(price.ID.Hex(), data.DefaultQuery())
+				if assert.NoError(t, err) {
+					assert.Equal(t, "2D", stored.Label)
+					assert.Equal(t, models.GetAttributesWeight([]string{models.Attribute2D}), stored.Weight)
+					assert.NotNil(t, stored.CreatedAt)
+				}
+			},
+		},
+		{
+			name:      "It should return OK",
+			method:    "DELETE",
+			url:       url,
+			status:    http.StatusOK,
+			authToken: adminAuthToken,
+		},
+		{
+			name:      "It should return NotFound since the price was deleted",
+			method:    "DELETE",
+			url:       url,
+			status:    http.StatusNotFound,
+			authToken: adminAuthToken,
+		},
+	}
+	r.RunTests(t, cases)
+}
@@ -2,7 +2,6 @@ package v2
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -10,8 +9,10 @@ import (
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/priceutil"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -44,20 +45,23 @@ type Schedule struct {
 
 // Session ...
 type Session struct {
-	ID        string `json:"_id"`
-	Active    bool   `json:"active"`
-	StartTime string `json:"startTime"`
+	ID        string                `json:"_id"`
+	Active    bool                  `json:"active"`
+	StartTime string                `json:"startTime"`
+	Type      string                `json:"type"`            // Type is regular, preview, premiere or special_event
+	Price     *priceutil.Resolution `json:"price,omitempty"` // Price is included with ?price=true
 }
 
 // ServeSchedules ...
 func (r *RESTService) ServeSchedules(rg *gin.RouterGroup) {
 	s := &ScheduleService{r.data}
 
-	schedules := rg.Group("/schedules", rest.JWTAuth(nil))
+	schedules := rg.Group("/schedules", rest.JWTAuth(nil), ValidateRequest())
 	schedules.GET("", s.GetAll)
 }
 
-// GetAll ...
+// GetAll lists the schedules of a theater. Use ?price=true to include the
+// price of each session.
 func (s *ScheduleService) GetAll(c *gin.Context) {
 	query := BuildScheduleQuery(s.data, c)
 	if query == nil {
@@ -70,7 +74,17 @@ func (s *ScheduleService) GetAll(c *gin.Context) {
 		apiutil.SendSuccessOrError(c, nil, err)
 		return
 	}
-	schedules := mapSessionsToSchedules(sessions)
+
+	var pricer *sessionPricer
+	if c.Query("price") == "true" {
+		theaterID, _ := primitive.ObjectIDFromHex(c.MustGet("query_options").(map[string]string)["theaterId"])
+		pricer, err = newSessionPricer(s.data, theaterID)
+		if err != nil {
+			apiutil.HandleError(c, err)
+			return
+		}
+	}
+	schedules := mapSessionsToSchedules(sessions, pricer)
 	apiutil.SendSuccessOrError(c, schedules, err)
 }
 
@@ -91,50 +105,27 @@ func BuildScheduleQuery(data persistence.DataAccessLayer, c *gin.Context) persis
 		query.AddCondition("movieId", movieID)
 	}
 
-	loc, _ := time.LoadLocation("America/Sao_Paulo")
-	t, err := time.ParseInLocation("2006-01-02", qopts["date"], loc)
+	// Dates are days in the theater city, not in the server time zone.
+	loc := theaterLocation(data, theaterID.Hex())
+	t, err := timeutil.ParseDateIn(qopts["date"], loc)
 	if err != nil {
-		t = timeutil.StartOfDay()
+		t = timeutil.NowIn(loc)
+	}
+	query.AddCondition("date", timeutil.DateIn(t, loc))
+	if attrs := qopts["attributes"]; attrs != "" {
+		query.AddCondition("attributes", bson.M{"$all": strings.Split(attrs, ",")})
 	}
-	d := fmt.Sprintf("%d%02d%02d", t.Year(), int(t.Month()), t.Day())
-	v, _ := strconv.Atoi(d)
-	query.AddCondition("date", v)
 	query.AddInclude("movie")
 	query.SetSort("movie.title", "room", "version", "format", "startTime")
 	query.SetLimit(-1)
 	return query
 }
 
-func makeRoom(session *models.Session) Room {
+func makeRoom(session *models.Session, price *priceutil.Resolution) Room {
 	var attrs []RoomAttribute
-
-	attr := RoomAttribute{}
-	if session.Format == models.Format2D {
-		attr.ID = models.Format2D
-		attr.Name = "2D"
-	} else if session.Format == models.Format3D {
-		attr.ID = models.Format3D
-		attr.Name = "3D"
-	}
-
-	if attr.ID != "" {
-		attrs = append(attrs, attr)
-	}
-
-	attr = RoomAttribute{}
-	if session.Version == models.VersionDubbed {
-		attr.ID = models.VersionDubbed
-		attr.Name = "Dublado"
-	} else if session.Version == models.VersionSubbed || session.Version == models.VersionSubtitled {
-		attr.ID = models.VersionSubtitled
-		attr.Name = "Legendado"
-	} else if session.Version == models.VersionNational {
-		attr.ID = models.VersionNational
-		attr.Name = "Nacional"
-	}
-
-	if attr.ID != "" {
-		attrs = append(attrs, attr)
+	for _, id := range models.SortAttributes(session.GetAttributes()) {
+		a, _ := models.GetAttribute(id)
+		attrs = append(attrs, RoomAttribute{ID: a.ID, Name: a.Name})
 	}
 
 	ID := strings.Builder{}
@@ -154,12 +145,14 @@ func makeRoom(session *models.Session) Room {
 				ID:        session.ID.Hex(),
 				Active:    active,
 				StartTime: session.OpeningTime,
+				Type:      session.GetType(),
+				Price:     price,
 			},
 		},
 	}
 }
 
-func mapSessionsToSchedules(sessions []models.Session) []Schedule {
+func mapSessionsToSchedules(sessions []models.Session, pricer *sessionPricer) []Schedule {
 
 	var schedules []Schedule
 	if sessions == nil || len(sessions) == 0 {
@@ -183,7 +176,12 @@ func mapSessionsToSchedules(sessions []models.Session) []Schedule {
 			schedmap[session.MovieID.Hex()] = index
 		}
 
-		room := makeRoom(&session)
+		var price *priceutil.Resolution
+		if pricer != nil {
+			// Sessions without an applicable price are listed without it.
+			price, _ = pricer.Resolve(&session)
+		}
+		room := makeRoom(&session, price)
 
 		found := -1
 		for i, r := range schedules[index].Rooms {
@@ -201,6 +199,8 @@ func mapSessionsToSchedules(sessions []models.Session) []Schedule {
 				ID:        session.ID.Hex(),
 				StartTime: session.OpeningTime,
 				Active:    now.Before(*session.StartTime),
+				Type:      session.GetType(),
+				Price:     price,
 			})
 			schedules[index].Rooms[found] = room
 		}
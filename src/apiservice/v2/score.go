@@ -16,7 +16,7 @@ type ScoreService struct {
 func (r *RESTService) ServeScores(rg *gin.RouterGroup) {
 	s := &ScoreService{r.data}
 
-	scores := rg.Group("/scores", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}))
+	scores := rg.Group("/scores", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}), ValidateRequest())
 	scores.GET("", s.GetAll)
 	scores.GET("/score/:id", s.Get)
 }
@@ -0,0 +1,178 @@
+package v2
+
+import (
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ScraperService ...
+type ScraperService struct {
+	data persistence.DataAccessLayer
+}
+
+// ServeScrapers ...
+func (r *RESTService) ServeScrapers(rg *gin.RouterGroup) {
+	s := &ScraperService{r.data}
+
+	admin := rg.Group("/scrapers", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}), ValidateRequest())
+	admin.GET("", s.GetAll)
+	admin.POST("", s.Create)
+	admin.GET("/scraper/:id", s.Get)
+	admin.PUT("/scraper/:id", s.Update)
+	admin.DELETE("/scraper/:id", s.Delete)
+}
+
+// GetAll lists scrapers, filtered by ?theater_id, ?type and ?provider.
+func (s *ScraperService) GetAll(c *gin.Context) {
+	query := s.data.DefaultQuery().SetLimit(-1)
+	if v := c.Query("theater_id"); v != "" {
+		id, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			apiutil.SendBadRequest(c)
+			return
+		}
+		query.AddCondition("theaterId", id)
+	}
+	for _, field := range []string{"type", "provider"} {
+		if v := c.Query(field); v != "" {
+			query.AddCondition(field, v)
+		}
+	}
+	scrapers, err := s.data.GetScrapers(query)
+	apiutil.SendSuccessOrError(c, scrapers, err)
+}
+
+// Get gets the scraper with the given ID.
+func (s *ScraperService) Get(c *gin.Context) {
+	scraper, err := s.data.GetScraper(c.Param("id"), s.data.DefaultQuery())
+	apiutil.SendSuccessOrError(c, scraper, err)
+}
+
+// Create attaches a scraper to a theater. Theaters have at most one scraper
+// of each type per provider.
+func (s *ScraperService) Create(c *gin.Context) {
+	scraper := models.Scraper{}
+	if err := c.ShouldBindJSON(&scraper); err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	scraper.ID = primitive.NewObjectID()
+	scraper.LastRun = primitive.NilObjectID
+	details, err := validateScraper(s.data, &scraper)
+	if sendInvalid(c, details, err) {
+		return
+	}
+	if s.isDuplicate(c, &scraper) {
+		return
+	}
+	err = s.data.InsertScraper(scraper)
+	apiutil.SendSuccessOrError(c, scraper, err)
+}
+
+// Update replaces the scraper with the given ID. The last run is kept.
+func (s *ScraperService) Update(c *gin.Context) {
+	scraper := models.Scraper{}
+	if err := c.ShouldBindJSON(&scraper); err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	current, err := s.data.GetScraper(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	scraper.ID = current.ID
+	scraper.LastRun = current.LastRun
+	details, err := validateScraper(s.data, &scraper)
+	if sendInvalid(c, details, err) {
+		return
+	}
+	if s.isDuplicate(c, &scraper) {
+		return
+	}
+	_, err = s.data.UpdateScraper(c.Param("id"), scraper)
+	apiutil.SendSuccessOrError(c, scraper, err)
+}
+
+// Delete removes the scraper with the given ID and its queued jobs. Its runs
+// are kept as history.
+func (s *ScraperService) Delete(c *gin.Context) {
+	scraper, err := s.data.GetScraper(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	err = deleteScraper(s.data, scraper)
+	apiutil.SendSuccessOrError(c, 1, err)
+}
+
+// isDuplicate sends a conflict if the theater already has another scraper
+// of the same type and provider.
+func (s *ScraperService) isDuplicate(c *gin.Context, scraper *models.Scraper) bool {
+	other, err := s.data.FindScraper(s.data.DefaultQuery().
+		AddCondition("theaterId", scraper.TheaterID).
+		AddCondition("type", scraper.Type).
+		AddCondition("provider", scraper.Provider))
+	if err == mongo.ErrNoDocuments {
+		return false
+	}
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return true
+	}
+	if other.ID == scraper.ID {
+		return false
+	}
+	apiutil.SendConflict(c)
+	return true
+}
+
+// deleteScraper removes the scraper and the jobs waiting to run it.
+func deleteScraper(data persistence.DataAccessLayer, scraper *models.Scraper) error {
+	jobs, err := data.GetScraperJobs(data.DefaultQuery().
+		AddCondition("scraper_id", scraper.ID).
+		SetLimit(-1))
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if err := data.DeleteScraperJob(job.ID.Hex()); err != nil {
+			return err
+		}
+	}
+	return data.DeleteScraper(scraper.ID.Hex())
+}
+
+// validateScraper checks the scraper belongs to an existing theater, has a
+// valid type, a provider from the registry and a valid schedule. The theater
+// included in responses is removed, so it isn't stored.
+func validateScraper(data persistence.DataAccessLayer, scraper *models.Scraper) ([]*apiutil.APIErrorDetail, error) {
+	scraper.Theater = nil
+
+	details := []*apiutil.APIErrorDetail{}
+	if scraper.TheaterID.IsZero() {
+		details = append(details, bodyError("theater_id", "is required"))
+	} else if _, err := data.GetTheater(scraper.TheaterID.Hex(), data.DefaultQuery()); err == mongo.ErrNoDocuments {
+		details = append(details, bodyError("theater_id", "doesn't exist"))
+	} else if err != nil {
+		return nil, err
+	}
+	if !scraperutil.IsType(scraper.Type) {
+		details = append(details, bodyError("type", "is not a scraper type"))
+	}
+	if !scraperutil.IsProvider(scraper.Provider) {
+		details = append(details, bodyError("provider", "is not a registered provider"))
+	}
+	if err := scraperutil.ValidateSchedule(*scraper); err == scraperutil.ErrInvalidWindow {
+		details = append(details, bodyError("windows", err.Error()))
+	} else if err != nil {
+		details = append(details, bodyError("cron", err.Error()))
+	}
+	return details, nil
+}
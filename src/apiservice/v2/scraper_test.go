@@ -0,0 +1,100 @@
+package v2
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares"
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestScraper(t *testing.T) {
+	data := NewMockDataAccessLayer()
+
+	r := NewMockRouter(data)
+	r.Use(rest.Init(), middlewares.ValidObjectIDHex(), middlewares.BaseParseQuery())
+
+	s := RESTService{data: data}
+	s.ServeScrapers(&r.RouterGroup)
+
+	// Add test data
+	testTheater := models.Theater{
+		ID:        primitive.NewObjectID(),
+		Name:      "Fake Theater",
+		ShortName: "Fake",
+	}
+	err := data.InsertTheater(testTheater)
+	assert.NoError(t, err)
+
+	HexID := testTheater.ID.Hex()
+
+	clientAuthToken := getClientAuthToken(t)
+	adminAuthToken := getAdminAuthToken(t)
+
+	cases := []apiTestCase{
+		apiTestCase{
+			name:      "It should return Unauthorized because scrapers are admin only",
+			method:    "GET",
+			url:       "/scrapers",
+			status:    http.StatusUnauthorized,
+			authToken: clientAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return BadRequest since the provider isn't registered",
+			method:    "POST",
+			url:       "/scrapers",
+			body:      `{"theater_id": "` + HexID + `", "type": "schedule", "provider": "unknown"}`,
+			status:    http.StatusBadRequest,
+			authToken: adminAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return BadRequest since the cron spec is invalid",
+			method:    "POST",
+			url:       "/scrapers",
+			body:      `{"theater_id": "` + HexID + `", "type": "schedule", "provider": "cinemais", "cron": ["every day"]}`,
+			status:    http.StatusBadRequest,
+			authToken: adminAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return OK because the scraper is valid",
+			method:    "POST",
+			url:       "/scrapers",
+			body:      `{"theater_id": "` + HexID + `", "type": "schedule", "provider": "cinemais"}`,
+			status:    http.StatusOK,
+			authToken: adminAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return Conflict since the theater already has this scraper",
+			method:    "POST",
+			url:       "/scrapers",
+			body:      `{"theater_id": "` + HexID + `", "type": "schedule", "provider": "cinemais"}`,
+			status:    http.StatusConflict,
+			authToken: adminAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return scrapers of Theater with ID " + HexID,
+			method:    "GET",
+			url:       "/scrapers?theater_id=" + HexID,
+			status:    http.StatusOK,
+			authToken: adminAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return BadRequest since theater_id is not a valid ObjectId",
+			method:    "GET",
+			url:       "/scrapers?theater_id=invalid-theater-id",
+			status:    http.StatusBadRequest,
+			authToken: adminAuthToken,
+		},
+	}
+
+	r.RunTests(t, cases)
+
+	_, err = data.DeleteScrapers(data.DefaultQuery().AddCondition("theaterId", testTheater.ID))
+	assert.NoError(t, err)
+
+	err = data.DeleteTheater(HexID)
+	assert.NoError(t, err)
+}
@@ -1,16 +1,28 @@
 package v2
 
 import (
+	"errors"
+	"strconv"
 	"time"
 
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares"
 	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/priceutil"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/scheduleutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// defaultSessionsPageSize is the page size of session listings when the
+// request has no limit.
+const defaultSessionsPageSize = 25
+
+var errInvalidSessionFilter = errors.New("invalid session filter")
+
 // SessionService ...
 type SessionService struct {
 	data persistence.DataAccessLayer
@@ -20,11 +32,10 @@ type SessionService struct {
 func (r *RESTService) ServeSessions(rg *gin.RouterGroup) {
 	s := &SessionService{r.data}
 
-	client := rg.Group("/sessions", rest.JWTAuth(nil))
+	client := rg.Group("/sessions", rest.JWTAuth(nil), ValidateRequest())
+	client.GET("", s.GetAll)
 	client.GET("/session/:id", s.Get)
-
-	admin := rg.Group("/sessions", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}))
-	admin.GET("", s.GetAll)
+	client.GET("/session/:id/price", s.GetPrice)
 }
 
 // Get gets the session corresponding the requested ID.
@@ -33,47 +44,201 @@ func (s *SessionService) Get(c *gin.Context) {
 	apiutil.SendSuccessOrError(c, session, err)
 }
 
-// GetAll gets all sessions.
+// GetPrice resolves which price of the theater applies to the session.
+func (s *SessionService) GetPrice(c *gin.Context) {
+	session, err := s.data.GetSession(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	if !session.MovieID.IsZero() {
+		session.Movie, err = s.data.GetMovie(session.MovieID.Hex(), s.data.DefaultQuery())
+		if err != nil {
+			apiutil.HandleError(c, err)
+			return
+		}
+	}
+
+	pricer, err := newSessionPricer(s.data, session.TheaterID)
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	price, err := pricer.Resolve(session)
+	if err == priceutil.ErrNoPrice {
+		apiutil.SendNotFound(c)
+		return
+	}
+	apiutil.SendSuccessOrError(c, price, err)
+}
+
+// GetAll gets the sessions matching the request filters. See
+// buildSessionListQuery for the supported filters.
 func (s *SessionService) GetAll(c *gin.Context) {
 	q := c.MustGet("query_options").(map[string]string)
 
-	query := s.data.BuildSessionQuery(q)
+	query, err := buildSessionListQuery(s.data, q)
+	if err == errInvalidSessionFilter {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+
+	sessions, err := s.data.GetSessions(query)
+	apiutil.SendSuccessOrError(c, sessions, err)
+}
+
+// buildSessionListQuery builds the query of a session listing. Besides the
+// filters of BuildSessionQuery it supports:
+//
+//	cityId           sessions of the theaters of a city
+//	start, end       days in YYYY-MM-DD format, in the theater time zone
+//	timeFrom, timeTo time of day window in HH:MM format, in the session time zone
+//	upcoming         sessions starting from now, soonest first
+//	page             page number, starting at 1, of limit sessions
+//	limit            page size, up to middlewares.MaxLimit
+//
+// Without start or upcoming only sessions of the current week are listed.
+func buildSessionListQuery(data persistence.DataAccessLayer, q map[string]string) (persistence.Query, error) {
+	query := data.BuildSessionQuery(q)
+
+	// Days of start and end are the ones of the theater city.
+	loc := timeutil.DefaultLocation()
+	if id, ok := q["theaterId"]; ok {
+		loc = theaterLocation(data, id)
+	} else if id, ok := q["cityId"]; ok {
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return nil, errInvalidSessionFilter
+		}
+		city, err := data.GetCity(id, data.DefaultQuery())
+		if err != nil {
+			return nil, err
+		}
+		theaters, err := data.GetTheaters(data.DefaultQuery().
+			AddCondition("cityId", city.ID).
+			SetLimit(-1))
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]primitive.ObjectID, len(theaters))
+		for i, t := range theaters {
+			ids[i] = t.ID
+		}
+		query.AddCondition("theaterId", bson.M{"$in": ids})
+		loc = city.Location()
+	}
+
+	now := time.Now()
+	upcoming := q["upcoming"] == "true"
 
-	var hasStart, hasEnd bool
-	start, ok := q["start"]
-	if ok {
-		t, err := time.ParseInLocation("2006-01-02", start, time.UTC)
-		if err == nil {
-			query.AddCondition("startTime", bson.M{"$gte": t})
-			hasStart = true
+	startTime := bson.M{}
+	if v, ok := q["start"]; ok {
+		t, err := timeutil.ParseDateIn(v, loc)
+		if err != nil {
+			return nil, errInvalidSessionFilter
+		}
+		startTime["$gte"] = t
+	}
+	if v, ok := q["end"]; ok {
+		t, err := timeutil.ParseDateIn(v, loc)
+		if err != nil {
+			return nil, errInvalidSessionFilter
+		}
+		startTime["$lte"] = timeutil.EndOfDay(t)
+	}
+	if upcoming {
+		if t, ok := startTime["$gte"].(time.Time); !ok || t.Before(now) {
+			startTime["$gte"] = now
 		}
+	} else if _, ok := startTime["$gte"]; !ok {
+		startTime["$gte"] = scheduleutil.GetWeekPeriodIn(now, loc).Start
 	}
+	query.AddCondition("startTime", startTime)
 
-	end, ok := q["end"]
-	if ok {
-		t, err := time.ParseInLocation("2006-01-02", end, time.UTC)
-		if err == nil {
-			t.Add((time.Hour * 24) - time.Nanosecond)
-			query.AddCondition("startTime", bson.M{"$lte": t})
-			hasEnd = true
+	from, to := q["timeFrom"], q["timeTo"]
+	if from != "" || to != "" {
+		expr, err := timeOfDayCondition(from, to)
+		if err != nil {
+			return nil, err
 		}
+		query.AddCondition("$expr", expr)
 	}
 
-	if hasStart && hasEnd {
-		query.SetLimit(-1)
-	} else if !hasStart {
-		period := scheduleutil.GetWeekPeriod(nil)
-		query.AddCondition("startTime", bson.M{"$gte": period.Start})
-		query.SetLimit(-1)
+	// Listings are always paged. The page size is the limit of the request,
+	// up to middlewares.MaxLimit.
+	limit := int64(defaultSessionsPageSize)
+	if v := q["limit"]; v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return nil, errInvalidSessionFilter
+		}
+		limit = n
+	}
+	if limit > middlewares.MaxLimit {
+		limit = middlewares.MaxLimit
+	}
+	page := int64(1)
+	if v := q["page"]; v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			return nil, errInvalidSessionFilter
+		}
+		page = n
 	}
+	query.SetLimit(limit).SetSkip((page - 1) * limit)
 
-	// If we are not sorting let's set the default sort
-	if !query.Sorting() {
+	if upcoming {
+		query.SetSort("+startTime", "+_id")
+	} else if !query.Sorting() {
+		// If we are not sorting let's set the default sort
 		query.SetSort("-movieId", "+version", "+format", "+startTime")
 	}
 
-	sessions, err := s.data.GetSessions(query)
-	apiutil.SendSuccessOrError(c, sessions, err)
+	return query, nil
+}
+
+// timeOfDayCondition builds the condition matching sessions starting between
+// from and to, both in HH:MM format and inclusive. Windows ending before they
+// start cross midnight, eg: 22:00 to 02:00.
+func timeOfDayCondition(from, to string) (bson.M, error) {
+	parse := func(v string) (string, error) {
+		if v == "" {
+			return "", nil
+		}
+		t, err := time.Parse("15:04", v)
+		if err != nil {
+			return "", errInvalidSessionFilter
+		}
+		return t.Format("15:04"), nil
+	}
+	from, err := parse(from)
+	if err != nil {
+		return nil, err
+	}
+	to, err = parse(to)
+	if err != nil {
+		return nil, err
+	}
+
+	local := bson.M{"$dateToString": bson.M{
+		"format":   "%H:%M",
+		"date":     "$startTime",
+		"timezone": bson.M{"$ifNull": bson.A{"$timeZone", timeutil.DefaultTimeZone}},
+	}}
+	conds := bson.A{}
+	if from != "" {
+		conds = append(conds, bson.M{"$gte": bson.A{local, from}})
+	}
+	if to != "" {
+		conds = append(conds, bson.M{"$lte": bson.A{local, to}})
+	}
+	if from != "" && to != "" && to < from {
+		return bson.M{"$or": conds}, nil
+	}
+	return bson.M{"$and": conds}, nil
 }
 
 // BuildSessionQuery builds session query from request query string
@@ -66,6 +66,27 @@ func TestSession(t *testing.T) {
 			status:    http.StatusOK,
 			authToken: adminAuthToken,
 		},
+		apiTestCase{
+			name:      "It should return a list of Session models for clients",
+			method:    "GET",
+			url:       "/sessions?upcoming=true&version=dubbed,subtitled&timeFrom=18:00&timeTo=23:59",
+			status:    http.StatusOK,
+			authToken: clientAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return BadRequest since time of day is invalid",
+			method:    "GET",
+			url:       "/sessions?timeFrom=25:00",
+			status:    http.StatusBadRequest,
+			authToken: clientAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return BadRequest since page is invalid",
+			method:    "GET",
+			url:       "/sessions?page=0",
+			status:    http.StatusBadRequest,
+			authToken: clientAuthToken,
+		},
 	}
 
 	r.RunTests(t, cases)
@@ -73,3 +94,28 @@ func TestSession(t *testing.T) {
 	err = data.DeleteSession(testSession.ID.Hex())
 	assert.NoError(t, err)
 }
+
+func TestBuildSessionListQuery(t *testing.T) {
+	data := NewMockDataAccessLayer()
+
+	cases := []struct {
+		q     map[string]string
+		limit int64
+		skip  int64
+	}{
+		{map[string]string{}, defaultSessionsPageSize, 0},
+		{map[string]string{"limit": "10", "page": "3"}, 10, 20},
+		{map[string]string{"limit": "1000"}, middlewares.MaxLimit, 0},
+		{map[string]string{"upcoming": "true", "page": "2"}, defaultSessionsPageSize, defaultSessionsPageSize},
+	}
+	for _, test := range cases {
+		query, err := buildSessionListQuery(data, test.q)
+		if assert.NoError(t, err) {
+			assert.Equal(t, test.limit, query.GetLimit(), "%v", test.q)
+			assert.Equal(t, test.skip, query.GetSkip(), "%v", test.q)
+		}
+	}
+
+	_, err := buildSessionListQuery(data, map[string]string{"limit": "-1"})
+	assert.Equal(t, errInvalidSessionFilter, err)
+}
@@ -23,7 +23,7 @@ type StateService struct {
 func (r *RESTService) ServeStates(rg *gin.RouterGroup) {
 	s := &StateService{r.data}
 
-	states := rg.Group("/states", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}))
+	states := rg.Group("/states", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}), ValidateRequest())
 	states.GET("", s.GetAll)
 	states.GET("/state/:id", s.Get)
 	states.GET("/state/:id/cities", s.GetCities)
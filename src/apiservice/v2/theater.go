@@ -1,11 +1,18 @@
 package v2
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // TheaterService ...
@@ -17,16 +24,19 @@ type TheaterService struct {
 func (r *RESTService) ServeTheaters(rg *gin.RouterGroup) {
 	s := &TheaterService{r.data}
 
-	client := rg.Group("/theaters", rest.JWTAuth(nil))
+	client := rg.Group("/theaters", rest.JWTAuth(nil), ValidateRequest())
 	client.GET("/theater/:id", s.Get)
 	client.GET("/theater/:id/prices", s.GetPrices)
 	client.GET("/theater/:id/sessions", s.GetSessions)
 
-	admin := rg.Group("/theaters", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}))
+	admin := rg.Group("/theaters", rest.JWTAuth(&rest.Endpoint{AdminOnly: true}), ValidateRequest())
 	admin.GET("", s.GetAll)
 	admin.GET("/count", s.Count)
-	admin.PUT("/theater/:id", s.Update)
-	admin.DELETE("/theater/:id", s.Delete)
+
+	editor := rg.Group("/theaters", rest.JWTAuth(&rest.Endpoint{Scope: models.ScopeTheatersWrite}), ValidateRequest())
+	editor.POST("", s.Create)
+	editor.PUT("/theater/:id", s.Update)
+	editor.DELETE("/theater/:id", s.Delete)
 }
 
 // Get gets the theater corresponding the requested ID.
@@ -59,6 +69,25 @@ func (s *TheaterService) GetSessions(c *gin.Context) {
 	apiutil.SendSuccessOrError(c, sessions, err)
 }
 
+// Create creates a theater in an existing city.
+func (s *TheaterService) Create(c *gin.Context) {
+	theater := models.Theater{}
+	if err := c.ShouldBindJSON(&theater); err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	details, err := validateTheater(s.data, &theater)
+	if sendInvalid(c, details, err) {
+		return
+	}
+	now := time.Now().UTC()
+	theater.ID = primitive.NewObjectID()
+	theater.CreatedAt = &now
+	theater.UpdatedAt = &now
+	err = s.data.InsertTheater(theater)
+	apiutil.SendSuccessOrError(c, theater, err)
+}
+
 // Update apply to Theater with the given ID the given body data
 func (s *TheaterService) Update(c *gin.Context) {
 	theater := models.Theater{}
@@ -67,13 +96,71 @@ func (s *TheaterService) Update(c *gin.Context) {
 		apiutil.SendBadRequest(c)
 		return
 	}
+	current, err := s.data.GetTheater(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	details, err := validateTheater(s.data, &theater)
+	if sendInvalid(c, details, err) {
+		return
+	}
+	theater.ID = current.ID
+	theater.CreatedAt = current.CreatedAt
 	_, err = s.data.UpdateTheater(c.Param("id"), theater)
 	apiutil.SendSuccessOrError(c, theater, err)
 }
 
-// Delete the Theater with the given ID
+// Delete the Theater with the given ID and its prices. Theaters that still
+// have sessions or scrapers are a conflict, unless ?cascade=true is given to
+// delete them too, which only admins can do.
 func (s *TheaterService) Delete(c *gin.Context) {
-	err := s.data.DeleteTheater(c.Param("id"))
+	theater, err := s.data.GetTheater(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	owned := func() persistence.Query {
+		return s.data.DefaultQuery().AddCondition("theaterId", theater.ID).SetLimit(-1)
+	}
+	scrapers, err := s.data.GetScrapers(owned())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+
+	if c.Query("cascade") == "true" {
+		if claims := rest.GetClaims(c); claims == nil || !claims.IsAdmin() {
+			apiutil.SendUnauthorized(c)
+			return
+		}
+		for _, scraper := range scrapers {
+			if err := deleteScraper(s.data, &scraper); err != nil {
+				apiutil.HandleError(c, err)
+				return
+			}
+		}
+		if _, err := s.data.DeleteSessions(owned()); err != nil {
+			apiutil.HandleError(c, err)
+			return
+		}
+	} else {
+		sessions, err := s.data.CountSessions(owned())
+		if err != nil {
+			apiutil.HandleError(c, err)
+			return
+		}
+		if sessions > 0 || len(scrapers) > 0 {
+			apiutil.SendConflict(c)
+			return
+		}
+	}
+
+	if _, err := s.data.DeletePrices(owned()); err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	err = s.data.DeleteTheater(c.Param("id"))
 	apiutil.SendSuccessOrError(c, 1, err)
 }
 
@@ -88,3 +175,40 @@ func BuildTheaterQuery(data persistence.DataAccessLayer, c *gin.Context) persist
 	query := c.MustGet("query_options").(map[string]string)
 	return data.BuildTheaterQuery(query)
 }
+
+// validateTheater checks the theater has a name, an existing city and known
+// room attributes. The documents included in responses are removed, so they
+// aren't stored.
+func validateTheater(data persistence.DataAccessLayer, theater *models.Theater) ([]*apiutil.APIErrorDetail, error) {
+	theater.City, theater.Prices, theater.Sessions = nil, nil, nil
+
+	details := []*apiutil.APIErrorDetail{}
+	if strings.TrimSpace(theater.Name) == "" {
+		details = append(details, bodyError("name", "is required"))
+	}
+	if theater.CityID.IsZero() {
+		details = append(details, bodyError("cityId", "is required"))
+	} else if _, err := data.GetCity(theater.CityID.Hex(), data.DefaultQuery()); err == mongo.ErrNoDocuments {
+		details = append(details, bodyError("cityId", "doesn't exist"))
+	} else if err != nil {
+		return nil, err
+	}
+	for i, r := range theater.Rooms {
+		for j, a := range r.Attributes {
+			if _, ok := models.GetAttribute(a); !ok {
+				details = append(details, bodyError(fmt.Sprintf("rooms[%d].attributes[%d]", i, j), "is not an attribute"))
+			}
+		}
+	}
+	return details, nil
+}
+
+// theaterLocation returns the time zone of the theater with the given ID,
+// resolved from its city.
+func theaterLocation(data persistence.DataAccessLayer, theaterID string) *time.Location {
+	theater, err := data.GetTheater(theaterID, data.DefaultQuery().AddInclude("city"))
+	if err != nil {
+		return timeutil.DefaultLocation()
+	}
+	return theater.City.Location()
+}
@@ -69,10 +69,119 @@ func TestTheater(t *testing.T) {
 			status:    http.StatusOK,
 			authToken: adminAuthToken,
 		},
+		apiTestCase{
+			name:      "It should return Unauthorized because the token can't write theaters",
+			method:    "POST",
+			url:       "/theaters",
+			body:      `{"name": "New Theater"}`,
+			status:    http.StatusUnauthorized,
+			authToken: clientAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return BadRequest since the theater has no city",
+			method:    "POST",
+			url:       "/theaters",
+			body:      `{"name": "New Theater"}`,
+			status:    http.StatusBadRequest,
+			authToken: adminAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return BadRequest since the city doesn't exist",
+			method:    "POST",
+			url:       "/theaters",
+			body:      `{"name": "New Theater", "cityId": "` + primitive.NewObjectID().Hex() + `"}`,
+			status:    http.StatusBadRequest,
+			authToken: adminAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return BadRequest since the room attribute is unknown",
+			method:    "POST",
+			url:       "/theaters",
+			body:      `{"name": "New Theater", "rooms": [{"number": 1, "attributes": ["Poltrona VIP"]}]}`,
+			status:    http.StatusBadRequest,
+			authToken: adminAuthToken,
+		},
+		apiTestCase{
+			name:      "It should return Conflict since the theater has a scraper",
+			method:    "DELETE",
+			url:       "/theaters/theater/" + HexID,
+			status:    http.StatusConflict,
+			authToken: adminAuthToken,
+		},
 	}
 
+	testScraper := models.Scraper{
+		ID:        primitive.NewObjectID(),
+		TheaterID: testTheater.ID,
+		Type:      "schedule",
+		Provider:  "cinemais",
+	}
+	err = data.InsertScraper(testScraper)
+	assert.NoError(t, err)
+
 	r.RunTests(t, cases)
 
+	err = data.DeleteScraper(testScraper.ID.Hex())
+	assert.NoError(t, err)
+
 	err = data.DeleteTheater(testTheater.ID.Hex())
 	assert.NoError(t, err)
 }
+
+func TestTheaterCascade(t *testing.T) {
+	data := NewMockDataAccessLayer()
+
+	r := NewMockRouter(data)
+	r.Use(rest.Init(), middlewares.ValidObjectIDHex(), middlewares.BaseParseQuery())
+
+	s := RESTService{data: data}
+	s.ServeTheaters(&r.RouterGroup)
+
+	testTheater := models.Theater{
+		ID:        primitive.NewObjectID(),
+		Name:      "Fake Theater",
+		ShortName: "Fake",
+	}
+	assert.NoError(t, data.InsertTheater(testTheater))
+	testScraper := models.Scraper{
+		ID:        primitive.NewObjectID(),
+		TheaterID: testTheater.ID,
+		Type:      "schedule",
+		Provider:  "cinemais",
+	}
+	assert.NoError(t, data.InsertScraper(testScraper))
+	testPrice := models.Price{
+		ID:        primitive.NewObjectID(),
+		TheaterID: testTheater.ID,
+		Label:     "Inteira",
+		Full:      20,
+	}
+	assert.NoError(t, data.InsertPrice(testPrice))
+
+	url := "/theaters/theater/" + testTheater.ID.Hex()
+	cases := []apiTestCase{
+		{
+			name:      "It should return Unauthorized since only admins can cascade",
+			method:    "DELETE",
+			url:       url + "?cascade=true",
+			status:    http.StatusUnauthorized,
+			authToken: getAuthToken(t, "admin", []string{models.ScopeAPIRead, models.ScopeTheatersWrite}),
+		},
+		{
+			name:      "It should return OK",
+			method:    "DELETE",
+			url:       url + "?cascade=true",
+			status:    http.StatusOK,
+			authToken: getAdminAuthToken(t),
+		},
+	}
+	r.RunTests(t, cases)
+
+	// Scrapers and prices of the theater are deleted with it.
+	_, err := data.GetTheater(testTheater.ID.Hex(), data.DefaultQuery())
+	assert.Error(t, err)
+	_, err = data.GetScraper(testScraper.ID.Hex(), data.DefaultQuery())
+	assert.Error(t, err)
+	_, err = data.GetPrice(testPrice.ID.Hex(), data.DefaultQuery())
+	assert.Error(t, err)
+}
@@ -1,8 +1,12 @@
 package v2
 
 import (
+	"time"
+
 	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares"
+	"github.com/dsbezerra/amenic-lambda/src/lib/openapi"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,19 +17,48 @@ type (
 	}
 )
 
-// AddRoutes add V2 routes to main router in group v2
-func AddRoutes(r *gin.Engine, data persistence.DataAccessLayer) {
+// AddRoutes add V2 routes to main router in group v2. The cacheMaxAge
+// overrides the max-age of the caching policies by route path prefix.
+func AddRoutes(r *gin.Engine, data persistence.DataAccessLayer, cacheMaxAge map[string]time.Duration) {
 	r.Use(middlewares.BaseParseQuery())
-	v2 := r.Group("v2")
+	v2 := r.Group("v2", Cache(data, cacheMaxAge))
 	s := RESTService{data}
 
+	s.ServeOpenAPI(v2)
 	s.ServeAuth(v2)
+	s.ServeAPIKeys(v2)
+	s.ServeAttributes(v2)
 	s.ServeSchedules(v2)
+	s.ServeSessions(v2)
+	s.ServeCalendars(v2)
 	s.ServeCities(v2)
+	s.ServeHolidays(v2)
 	s.ServeStates(v2)
 	s.ServeTheaters(v2)
 	s.ServeScores(v2)
 	s.ServePrices(v2)
 	s.ServeMovies(v2)
 	s.ServeNotifications(v2)
+	s.ServeScrapers(v2)
+	s.ServeGraphQL(v2)
+}
+
+// bodyError describes an invalid field of the body of a request, in the
+// format of the errors of ValidateRequest.
+func bodyError(field, message string) *apiutil.APIErrorDetail {
+	return &apiutil.APIErrorDetail{Field: field, In: openapi.InBody, Message: message}
+}
+
+// sendInvalid sends the errors of the validation of a request body, or the
+// error that stopped it. Valid bodies return false.
+func sendInvalid(c *gin.Context, details []*apiutil.APIErrorDetail, err error) bool {
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return true
+	}
+	if len(details) > 0 {
+		apiutil.SendValidationError(c, details)
+		return true
+	}
+	return false
 }
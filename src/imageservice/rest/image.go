@@ -3,6 +3,7 @@ package rest
 import (
 	"github.com/dsbezerra/amenic-lambda/src/imageservice/cloudinary"
 	"github.com/dsbezerra/amenic-lambda/src/lib/messagequeue"
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
@@ -28,12 +29,14 @@ type imageUploadBody struct {
 func (rs *Service) ServeImages(r *gin.Engine) {
 	s := &ImageService{rs.data, rs.emitter}
 
-	images := r.Group("/images")
+	images := r.Group("/images", rest.AdminAuth(rs.data))
 	images.GET("/:id", s.Get)
 	images.GET("/", s.GetAll)
-
-	images.POST("/upload", s.Upload)
 	images.DELETE("/:id", s.Delete)
+
+	// Uploading only needs the images:upload scope.
+	uploaders := r.Group("/images", rest.ScopeAuth(rs.data, models.ScopeImagesUpload))
+	uploaders.POST("/upload", s.Upload)
 }
 
 // Get TODO
@@ -19,7 +19,7 @@ func ServeAPI(r *gin.Engine, data persistence.DataAccessLayer, emitter messagequ
 	s := &Service{data, emitter}
 
 	// Apply default middlewares
-	r.Use(rest.Init(), rest.AdminAuth(data), middlewares.BaseParseQuery())
+	r.Use(rest.Init(), middlewares.BaseParseQuery())
 
 	// ServeImages routes.
 	s.ServeImages(r)
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/scheduleutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
 
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
@@ -47,13 +48,9 @@ func GetWeekReleases(data persistence.DataAccessLayer) ([]models.Movie, error) {
 		return nil, ErrNoMoviesPlaying
 	}
 
-	loc, err := time.LoadLocation("America/Sao_Paulo")
-	if err != nil {
-		loc = time.UTC
-	}
-
-	now := time.Now().In(loc)
-	week := scheduleutil.GetWeekPeriod(&now)
+	// Releases are national, so the week is the one of the default time zone.
+	loc := timeutil.DefaultLocation()
+	week := scheduleutil.GetWeekPeriodIn(time.Now(), loc)
 	start := week.Start.UnixNano()
 	end := week.End.UnixNano()
 	for _, m := range playing {
@@ -61,7 +58,8 @@ func GetWeekReleases(data persistence.DataAccessLayer) ([]models.Movie, error) {
 			continue
 		}
 
-		release := m.ReleaseDate.UnixNano()
+		// Release dates are stored at midnight UTC, only the day matters.
+		release := timeutil.FloorToDay(m.ReleaseDate, loc).UnixNano()
 		if release >= start && release <= end {
 			result = append(result, m)
 		}
@@ -8,6 +8,8 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-lambda-go/lambdacontext"
 	"github.com/dsbezerra/amenic-lambda/src/jobservice/jobs"
+	"github.com/dsbezerra/amenic-lambda/src/lib/config"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/metadata"
 )
 
 var (
@@ -37,5 +39,11 @@ func Handler(ctx context.Context, event jobs.Input) {
 }
 
 func main() {
+	settings, err := config.LoadConfiguration()
+	if err != nil {
+		fmt.Println(fmt.Sprintf("Failed to load configuration. Error: %s", err.Error()))
+	}
+	// Scrapers started by jobs get metadata too.
+	metadata.SetDefault(metadata.New(settings))
 	lambda.Start(Handler)
 }
@@ -1,12 +1,27 @@
 package middlewares
 
 import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
 	"github.com/gin-contrib/static"
 	"github.com/gin-gonic/gin"
 )
 
+// StaticCacheControl is the Cache-Control header of static files.
+const StaticCacheControl = "public, max-age=21600"
+
+// DefaultVersionTTL is how long the Cache middleware reuses the data version
+// before fetching it again.
+const DefaultVersionTTL = 10 * time.Second
+
 // StaticWithCache little modification of static.Serve that adds Cache-Control header
 // to all static files.
 func StaticWithCache(urlPrefix string, fs static.ServeFileSystem) gin.HandlerFunc {
@@ -17,10 +32,265 @@ func StaticWithCache(urlPrefix string, fs static.ServeFileSystem) gin.HandlerFun
 	return func(c *gin.Context) {
 		if fs.Exists(urlPrefix, c.Request.URL.Path) {
 			// Define cache values here.
-			c.Writer.Header().Set("Cache-Control", "public, max-age=21600")
+			c.Writer.Header().Set("Cache-Control", StaticCacheControl)
 
 			fileserver.ServeHTTP(c.Writer, c.Request)
 			c.Abort()
 		}
 	}
 }
+
+type (
+	// CachePolicy is the caching policy of a route.
+	CachePolicy struct {
+		MaxAge    time.Duration // MaxAge is how long clients may reuse a response
+		Private   bool          // Private responses can't be stored by shared caches
+		NoStore   bool          // NoStore responses can't be stored at all
+		Versioned bool          // Versioned responses only change with the data version or the day
+		ReadOnly  bool          // ReadOnly routes don't change data with any method
+	}
+
+	// CacheOptions configures the Cache middleware.
+	CacheOptions struct {
+		// Policies by gin route path. Routes use the policy with the longest
+		// path that prefixes theirs. Routes without policy are not cached.
+		Policies map[string]CachePolicy
+		// Version returns the version of the data. Without it versioned
+		// policies use content hashes too.
+		Version func() (int64, error)
+		// VersionTTL is how long the version is reused. Defaults to
+		// DefaultVersionTTL.
+		VersionTTL time.Duration
+		// Bump is called after successful requests that change data.
+		Bump func() error
+		// Authorize tells whether a request would pass the authentication of
+		// its route, which runs after this middleware. Versioned routes only
+		// answer 304 before running the route handlers for authorized
+		// requests. Without it, they always run the handlers.
+		Authorize func(c *gin.Context, policy CachePolicy) bool
+		// Location returns the time zone of the current day of versioned
+		// responses, eg: the one of the theater of the request. Defaults to
+		// timeutil.DefaultLocation.
+		Location func(c *gin.Context) *time.Location
+	}
+
+	// versionCache holds the last data version fetched.
+	versionCache struct {
+		mu        sync.Mutex
+		fetch     func() (int64, error)
+		ttl       time.Duration
+		version   int64
+		fetchedAt time.Time
+	}
+
+	// bufferedWriter holds the response until its ETag is known.
+	bufferedWriter struct {
+		gin.ResponseWriter
+		body   bytes.Buffer
+		status int
+	}
+)
+
+// String returns the Cache-Control header of the policy.
+func (p CachePolicy) String() string {
+	if p.NoStore {
+		return "no-store"
+	}
+	visibility := "public"
+	if p.Private {
+		visibility = "private"
+	}
+	return fmt.Sprintf("%s, max-age=%d", visibility, int(p.MaxAge.Seconds()))
+}
+
+// Policy returns the policy of the given gin route path.
+func (o *CacheOptions) Policy(route string) (CachePolicy, bool) {
+	var result CachePolicy
+	found := ""
+	for path, policy := range o.Policies {
+		if len(path) > len(found) && strings.HasPrefix(route, path) {
+			result, found = policy, path
+		}
+	}
+	return result, found != ""
+}
+
+// Cache middleware sets the Cache-Control and ETag headers of GET responses
+// following the policy of their routes, and answers requests with a matching
+// If-None-Match header with 304 Not Modified.
+//
+// ETags of versioned routes are computed from the data version, so matching
+// requests skip the handler. Other ETags are hashes of the response body.
+func Cache(opts CacheOptions) gin.HandlerFunc {
+	var versions *versionCache
+	if opts.Version != nil {
+		ttl := opts.VersionTTL
+		if ttl == 0 {
+			ttl = DefaultVersionTTL
+		}
+		versions = &versionCache{fetch: opts.Version, ttl: ttl}
+	}
+
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if method != http.MethodGet && method != http.MethodHead {
+			c.Next()
+			if policy, ok := opts.Policy(c.FullPath()); ok && policy.ReadOnly {
+				return
+			}
+			if opts.Bump != nil && c.Writer.Status() < http.StatusMultipleChoices && !c.IsAborted() {
+				if err := opts.Bump(); err == nil && versions != nil {
+					versions.Reset()
+				}
+			}
+			return
+		}
+
+		policy, ok := opts.Policy(c.FullPath())
+		if !ok {
+			return
+		}
+		header := c.Writer.Header()
+		if policy.NoStore {
+			header.Set("Cache-Control", policy.String())
+			return
+		}
+
+		etag := ""
+		if policy.Versioned && versions != nil {
+			if version, err := versions.Get(); err == nil {
+				etag = versionETag(version, c.Request, opts.location(c))
+				early := opts.Authorize != nil && opts.Authorize(c, policy)
+				if early && matchETag(c.GetHeader("If-None-Match"), etag) {
+					header.Set("Cache-Control", policy.String())
+					header.Set("ETag", etag)
+					c.AbortWithStatus(http.StatusNotModified)
+					return
+				}
+			}
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		// Errors are not cached.
+		status := w.Status()
+		if status != http.StatusOK {
+			w.flush(status)
+			return
+		}
+
+		header.Set("Cache-Control", policy.String())
+		if etag == "" {
+			etag = contentETag(w.body.Bytes())
+		}
+		header.Set("ETag", etag)
+		if matchETag(c.GetHeader("If-None-Match"), etag) {
+			header.Del("Content-Length")
+			w.ResponseWriter.WriteHeader(http.StatusNotModified)
+			w.ResponseWriter.WriteHeaderNow()
+			return
+		}
+		w.flush(status)
+	}
+}
+
+// location returns the time zone of the current day of the request.
+func (o *CacheOptions) location(c *gin.Context) *time.Location {
+	if o.Location == nil {
+		return timeutil.DefaultLocation()
+	}
+	return o.Location(c)
+}
+
+// Get returns the data version, fetching it again if it's older than the TTL.
+func (v *versionCache) Get() (int64, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if time.Since(v.fetchedAt) < v.ttl {
+		return v.version, nil
+	}
+	version, err := v.fetch()
+	if err != nil {
+		return 0, err
+	}
+	v.version, v.fetchedAt = version, time.Now()
+	return version, nil
+}
+
+// Reset makes the next Get fetch the version again.
+func (v *versionCache) Reset() {
+	v.mu.Lock()
+	v.fetchedAt = time.Time{}
+	v.mu.Unlock()
+}
+
+// versionETag is a hash of the data version, the current day in the given
+// location and the request URL, since responses of versioned routes default
+// to the current day. ETags are weak because responses may be compressed.
+func versionETag(version int64, r *http.Request, loc *time.Location) string {
+	day := timeutil.NowIn(loc).Format(timeutil.DateFormat)
+	return contentETag([]byte(fmt.Sprintf("%d|%s|%s", version, day, r.URL.RequestURI())))
+}
+
+func contentETag(body []byte) string {
+	sum := sha1.Sum(body)
+	return `W/"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// matchETag tells whether the If-None-Match header matches the ETag, using
+// the weak comparison.
+func matchETag(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+// WriteHeaderNow is deferred to flush.
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.status != 0 || w.body.Len() > 0
+}
+
+// Flush is deferred to flush.
+func (w *bufferedWriter) Flush() {}
+
+// flush writes the held response.
+func (w *bufferedWriter) flush(status int) {
+	w.ResponseWriter.WriteHeader(status)
+	w.ResponseWriter.Write(w.body.Bytes())
+}
@@ -0,0 +1,98 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	version := int64(1)
+	calls := 0
+	r := gin.New()
+	r.Use(Cache(CacheOptions{
+		Policies: map[string]CachePolicy{
+			"/sessions": {MaxAge: 5 * time.Minute},
+			"/cities":   {MaxAge: 24 * time.Hour, Versioned: true},
+			"/auth":     {NoStore: true, ReadOnly: true},
+		},
+		Version:   func() (int64, error) { return version, nil },
+		Bump:      func() error { version++; return nil },
+		Authorize: func(c *gin.Context, policy CachePolicy) bool { return true },
+	}))
+	handler := func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"name": "Cinemais"})
+	}
+	r.GET("/sessions", handler)
+	r.GET("/cities", handler)
+	r.GET("/auth", handler)
+	r.PUT("/cities", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/auth", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/missing", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	call := func(method, url, etag string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		return res
+	}
+
+	res := call("GET", "/sessions", "")
+	etag := res.Header().Get("ETag")
+	if res.Code != http.StatusOK || etag == "" || res.Header().Get("Cache-Control") != "public, max-age=300" {
+		t.Fatalf("unexpected response %d %v", res.Code, res.Header())
+	}
+	if res = call("GET", "/sessions", etag); res.Code != http.StatusNotModified || res.Body.Len() != 0 {
+		t.Errorf("expected 304 without body, got %d", res.Code)
+	}
+
+	// Versioned routes answer 304 without running the handler.
+	res = call("GET", "/cities", "")
+	etag = res.Header().Get("ETag")
+	calls = 0
+	if res = call("GET", "/cities", etag); res.Code != http.StatusNotModified || calls != 0 {
+		t.Errorf("expected 304 without calling the handler, got %d", res.Code)
+	}
+
+	// Changing data invalidates versioned ETags.
+	call("PUT", "/cities", "")
+	if res = call("GET", "/cities", etag); res.Code != http.StatusOK {
+		t.Errorf("expected 200 after data changed, got %d", res.Code)
+	}
+
+	// Read only routes don't change data.
+	call("POST", "/auth", "")
+	if version != 2 {
+		t.Errorf("expected version 2 after read only request, got %d", version)
+	}
+
+	if res = call("GET", "/auth", ""); res.Header().Get("Cache-Control") != "no-store" || res.Header().Get("ETag") != "" {
+		t.Errorf("expected no-store without ETag, got %v", res.Header())
+	}
+	if res = call("GET", "/missing", ""); res.Code != http.StatusNotFound || res.Header().Get("ETag") != "" {
+		t.Errorf("expected 404 without ETag, got %d %v", res.Code, res.Header())
+	}
+}
+
+func TestVersionETag(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/cities", nil)
+
+	// The current day in these zones is never the same.
+	east, _ := time.LoadLocation("Pacific/Kiritimati")
+	west, _ := time.LoadLocation("Pacific/Honolulu")
+	if versionETag(1, req, east) == versionETag(1, req, west) {
+		t.Errorf("expected ETags of different days to differ")
+	}
+	if versionETag(1, req, east) != versionETag(1, req, east) {
+		t.Errorf("expected the same ETag for the same day")
+	}
+}
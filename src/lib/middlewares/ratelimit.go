@@ -0,0 +1,122 @@
+package middlewares
+
+import (
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/ratelimit"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitOptions configures the RateLimit middleware.
+type RateLimitOptions struct {
+	Store ratelimit.Store
+	// Rules limit identified clients. Clients without matching rule use
+	// Default.
+	Rules   ratelimit.Rules
+	Default ratelimit.Limit
+	// IP limits every request by client IP. Zero disables it.
+	IP ratelimit.Limit
+	// Identify returns the client of the request. Requests without key are
+	// only limited by IP.
+	Identify func(c *gin.Context) ratelimit.Identity
+	// ClientIP returns the IP of the request. Defaults to RemoteIP, since
+	// clients can send any X-Forwarded-For header.
+	ClientIP func(c *gin.Context) string
+}
+
+// RateLimit middleware limits requests with token buckets per client key,
+// rule route and IP. Responses have the RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset headers of the most restrictive bucket, and denied
+// requests are answered with 429 Too Many Requests.
+//
+// Store errors don't block requests.
+func RateLimit(opts RateLimitOptions) gin.HandlerFunc {
+	clientIP := opts.ClientIP
+	if clientIP == nil {
+		clientIP = RemoteIP
+	}
+	return func(c *gin.Context) {
+		now := time.Now()
+		results := make([]ratelimit.Result, 0, 2)
+		take := func(key string, limit ratelimit.Limit) {
+			if limit.IsZero() {
+				return
+			}
+			result, err := opts.Store.Take(key, limit, now)
+			if err != nil {
+				log.Printf("Error: failed to take rate limit token of %s: %s\n", key, err)
+				return
+			}
+			results = append(results, result)
+		}
+
+		var id ratelimit.Identity
+		if opts.Identify != nil {
+			id = opts.Identify(c)
+		}
+		if id.Key != "" {
+			key, limit := "key:"+id.Key, opts.Default
+			if rule, ok := opts.Rules.Match(id, c.FullPath()); ok {
+				limit = rule.Limit
+				if rule.Route != "" {
+					key += ":" + rule.Route
+				}
+			}
+			take(key, limit)
+		}
+		if ip := clientIP(c); ip != "" {
+			take("ip:"+ip, opts.IP)
+		}
+
+		if len(results) == 0 {
+			return
+		}
+		result := mostRestrictive(results)
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(int(result.Limit.Capacity())))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+		if !result.Allowed {
+			header.Set("Retry-After", ceilSeconds(result.RetryAfter))
+			apiutil.SendTooManyRequests(c)
+		}
+	}
+}
+
+// RemoteIP returns the IP of the connection of the request, ignoring the
+// forwarded headers. Behind API Gateway, the Lambda proxy sets it to the
+// source IP of the request, without port.
+func RemoteIP(c *gin.Context) string {
+	if ip := c.RemoteIP(); ip != "" {
+		return ip
+	}
+	if ip := net.ParseIP(strings.TrimSpace(c.Request.RemoteAddr)); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// mostRestrictive returns the first denied result, or the one with fewer
+// remaining requests.
+func mostRestrictive(results []ratelimit.Result) ratelimit.Result {
+	result := results[0]
+	for _, r := range results[1:] {
+		if !result.Allowed {
+			break
+		}
+		if !r.Allowed || r.Remaining < result.Remaining {
+			result = r
+		}
+	}
+	return result
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
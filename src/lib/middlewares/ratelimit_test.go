@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RateLimit(RateLimitOptions{
+		Store: ratelimit.NewMemoryStore(),
+		Rules: ratelimit.Rules{
+			{UserType: "client", Route: "/sessions", Limit: ratelimit.PerMinute(1)},
+		},
+		Default: ratelimit.PerMinute(5),
+		IP:      ratelimit.PerMinute(3),
+		Identify: func(c *gin.Context) ratelimit.Identity {
+			return ratelimit.Identity{Key: c.Query("api_key"), UserType: "client"}
+		},
+	}))
+	handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/sessions", handler)
+	r.GET("/movies", handler)
+
+	call := func(url string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", url, nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		return res
+	}
+
+	res := call("/sessions?api_key=a")
+	if res.Code != http.StatusOK || res.Header().Get("RateLimit-Remaining") != "0" || res.Header().Get("RateLimit-Limit") != "1" {
+		t.Fatalf("unexpected response %d %v", res.Code, res.Header())
+	}
+	res = call("/sessions?api_key=a")
+	if res.Code != http.StatusTooManyRequests || res.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected 429 retrying after 60s, got %d %v", res.Code, res.Header())
+	}
+
+	// Routes without rule use their own bucket, but share the IP one.
+	if res = call("/movies?api_key=a"); res.Code != http.StatusOK || res.Header().Get("RateLimit-Limit") != "3" {
+		t.Errorf("expected IP limit to be the most restrictive, got %d %v", res.Code, res.Header())
+	}
+	if res = call("/movies"); res.Code != http.StatusTooManyRequests {
+		t.Errorf("expected IP limit to deny anonymous request, got %d", res.Code)
+	}
+
+	// Forwarded headers don't change the IP bucket.
+	req, _ := http.NewRequest("GET", "/movies", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "10.0.0.2")
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	if res.Code != http.StatusTooManyRequests {
+		t.Errorf("expected X-Forwarded-For to be ignored, got %d", res.Code)
+	}
+}
+
+func TestRemoteIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := map[string]string{
+		"10.0.0.1:1234": "10.0.0.1",
+		"10.0.0.1":      "10.0.0.1", // API Gateway source IP
+		"":              "",
+	}
+	for addr, expected := range cases {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request, _ = http.NewRequest("GET", "/", nil)
+		c.Request.RemoteAddr = addr
+		if ip := RemoteIP(c); ip != expected {
+			t.Errorf("expected %q for %q, got %q", expected, addr, ip)
+		}
+	}
+}
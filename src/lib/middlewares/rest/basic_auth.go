@@ -1,30 +1,44 @@
 package rest
 
 import (
+	"errors"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
-	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/mongolayer"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// APIKeyCacheTTL is how long API keys are kept in memory. Keys revoked by
+// other instances stop working after it.
+const APIKeyCacheTTL = time.Minute
+
+// ErrInactiveAPIKey is returned for revoked or expired keys.
+var ErrInactiveAPIKey = errors.New("inactive api key")
+
 // Credentials ...
 type Credentials struct {
 	ID  string // Holds username
-	Key string // Holds API Key
+	Key string // Holds API Key ID
 }
 
 // APIKeysTable keeps API keys in memory to avoid querying database everytime.
 type APIKeysTable struct {
 	sync.RWMutex
-	m map[string]models.APIKey
+	m map[string]cachedAPIKey
+}
+
+type cachedAPIKey struct {
+	apiKey    models.APIKey
+	fetchedAt time.Time
 }
 
 var apiKeysTable = &APIKeysTable{
-	m: make(map[string]models.APIKey),
+	m: make(map[string]cachedAPIKey),
 }
 
 // ClientAuth is a short function for BasicAuth(UserTypeClient)
@@ -44,6 +58,22 @@ func AdminAuth(data persistence.DataAccessLayer) gin.HandlerFunc {
 // BasicAuth is a simple middleware that checks if the query contains our
 // api key and see if it's valid or not.
 func BasicAuth(data persistence.DataAccessLayer, minUserType string) gin.HandlerFunc {
+	return keyAuth(data, func(key *models.APIKey) bool {
+		return key.UserTypeLevel() >= models.UserTypeLevel(minUserType)
+	})
+}
+
+// ScopeAuth is like BasicAuth, but lets in any key granted the given scope.
+// It's the equivalent of JWTAuth with Endpoint.Scope for routes
+// authenticated by api_key.
+func ScopeAuth(data persistence.DataAccessLayer, scope string) gin.HandlerFunc {
+	return keyAuth(data, func(key *models.APIKey) bool {
+		return key.HasScope(scope)
+	})
+}
+
+// keyAuth authenticates the api_key of the query and checks it with allow.
+func keyAuth(data persistence.DataAccessLayer, allow func(key *models.APIKey) bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		apiKey := c.Query("api_key")
 		if apiKey == "" {
@@ -57,7 +87,7 @@ func BasicAuth(data persistence.DataAccessLayer, minUserType string) gin.Handler
 			return
 		}
 
-		if result.UserTypeLevel() < models.UserTypeLevel(minUserType) {
+		if !allow(result) || !result.AllowsPlatform(RequestPlatform(c)) {
 			apiutil.SendUnauthorized(c)
 			return
 		}
@@ -65,44 +95,83 @@ func BasicAuth(data persistence.DataAccessLayer, minUserType string) gin.Handler
 		rs := GetRequestScope(c)
 		rs.SetUserCredentials(Credentials{
 			ID:  result.Owner,
-			Key: result.Key,
+			Key: result.ID.Hex(),
 		})
 
 		c.Next()
 	}
 }
 
-func getAPIKey(data persistence.DataAccessLayer, key string) (*models.APIKey, error) {
-	c := apiKeysTable.get(key)
-	if c.Key != "" {
-		return &c, nil
+// RequestPlatform returns the platform of the client from its User-Agent
+// header.
+func RequestPlatform(c *gin.Context) string {
+	lower := strings.ToLower(c.GetHeader("user-agent"))
+	if strings.Contains(lower, "android") {
+		return "android"
+	} else if strings.Contains(lower, "ios") {
+		return "ios"
+	} else if strings.Contains(lower, "web") {
+		return "web"
 	}
+	return ""
+}
 
-	opts := &mongolayer.QueryOptions{Conditions: bson.M{"key": key}}
-	result, err := data.FindAPIKey(opts)
-	if err != nil {
-		return nil, err
+// InvalidateAPIKey removes the API key with the given ID from memory, so
+// changes to it apply to the next request.
+func InvalidateAPIKey(id primitive.ObjectID) {
+	apiKeysTable.Lock()
+	for k, v := range apiKeysTable.m {
+		if v.apiKey.ID == id {
+			delete(apiKeysTable.m, k)
+		}
 	}
+	apiKeysTable.Unlock()
+}
 
-	if result.Key != "" {
-		apiKeysTable.put(result)
-	}
+// getAPIKey returns the active API key matching the given key.
+func getAPIKey(data persistence.DataAccessLayer, key string) (*models.APIKey, error) {
+	return cachedGetAPIKey("key:"+key, func() (*models.APIKey, error) {
+		return data.FindAPIKeyByKey(key)
+	})
+}
 
-	return result, err
+// getAPIKeyByID returns the active API key with the given ID.
+func getAPIKeyByID(data persistence.DataAccessLayer, id string) (*models.APIKey, error) {
+	return cachedGetAPIKey("id:"+id, func() (*models.APIKey, error) {
+		return data.GetAPIKey(id, data.DefaultQuery())
+	})
 }
 
-func (t *APIKeysTable) put(apiKey *models.APIKey) {
-	apiKeysTable.Lock()
-	apiKeysTable.m[apiKey.Key] = *apiKey
-	apiKeysTable.Unlock()
+func cachedGetAPIKey(k string, find func() (*models.APIKey, error)) (*models.APIKey, error) {
+	now := time.Now()
+	result, ok := apiKeysTable.get(k, now)
+	if !ok {
+		var err error
+		result, err = find()
+		if err != nil {
+			return nil, err
+		}
+		apiKeysTable.put(k, result, now)
+	}
+	if !result.IsActive(now) {
+		return nil, ErrInactiveAPIKey
+	}
+	return result, nil
 }
 
-func (t *APIKeysTable) get(key string) models.APIKey {
-	result := models.APIKey{}
+func (t *APIKeysTable) put(k string, apiKey *models.APIKey, now time.Time) {
+	t.Lock()
+	t.m[k] = cachedAPIKey{apiKey: *apiKey, fetchedAt: now}
+	t.Unlock()
+}
 
-	apiKeysTable.RLock()
-	result = apiKeysTable.m[key]
-	apiKeysTable.RUnlock()
+func (t *APIKeysTable) get(k string, now time.Time) (*models.APIKey, bool) {
+	t.RLock()
+	result, ok := t.m[k]
+	t.RUnlock()
 
-	return result
+	if !ok || now.Sub(result.fetchedAt) >= APIKeyCacheTTL {
+		return nil, false
+	}
+	return &result.apiKey, true
 }
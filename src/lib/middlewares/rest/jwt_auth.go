@@ -3,32 +3,69 @@ package rest
 import (
 	"errors"
 	"log"
+	"net/http"
 	"os"
+	"time"
 
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/tokens"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
 
 	"github.com/dgrijalva/jwt-go"
 	jwt_lib "github.com/dgrijalva/jwt-go"
 	"github.com/dgrijalva/jwt-go/request"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// JWTSecret ...
+// Issuer is the iss claim of tokens.
+const Issuer = "amenic"
+
+// JWTSecret verifies HS256 tokens. Tokens are signed with it only without
+// Keys.
 var JWTSecret string
 
+// Keys signs tokens and verifies the ones with a kid header.
+var Keys *tokens.Manager
+
+// KeyData is used to reject tokens of revoked, expired or rotated API keys.
+// Tokens are not checked without it.
+var KeyData persistence.DataAccessLayer
+
+// ErrRevokedToken is returned for revoked tokens and tokens of inactive or
+// rotated API keys.
+var ErrRevokedToken = errors.New("revoked token")
+
 // Claims ...
 type Claims struct {
-	Key      string   `json:"key"`      // Not using, forever?
+	Key      string   `json:"key"`      // ID of the API key, or the key itself in old tokens
 	Platform string   `json:"platform"` // Not using for now.
 	Type     string   `json:"type"`
 	Scopes   []string `json:"scopes"`
+	Session  string   `json:"sid,omitempty"` // Family of the refresh tokens issued with the token
 	jwt.StandardClaims
 }
 
+// ClaimsKey is the key of the claims of authorized requests in the context.
+const ClaimsKey = "claims"
+
+// parsedClaimsKey is the key of the result of RequestClaims in the context.
+const parsedClaimsKey = "parsedClaims"
+
+// parsedClaims is the result of ParseClaims of a request.
+type parsedClaims struct {
+	claims *Claims
+	err    error
+}
+
 // Endpoint ?
 type Endpoint struct {
 	AdminOnly bool
+	// Scope required by the endpoint, such as movies:write. Admins are
+	// always allowed. By default GET requests require api_read and POST,
+	// PUT and DELETE require api_write.
+	Scope string
 }
 
 // NewClaims ...
@@ -37,10 +74,10 @@ func NewClaims(apikey *models.APIKey, scopes []string) *Claims {
 		return nil
 	}
 
-	// scopes must be either api_read or api_write or both
+	// scopes must be known, see models.Scopes
 	valid := true
 	for _, s := range scopes {
-		if s != "api_read" && s != "api_write" {
+		if !models.IsScope(s) {
 			valid = false
 			break
 		}
@@ -51,10 +88,13 @@ func NewClaims(apikey *models.APIKey, scopes []string) *Claims {
 	}
 
 	return &Claims{
-		Key:      apikey.Key,
+		Key:      apikey.ID.Hex(),
 		Platform: apikey.Platform,
 		Type:     apikey.UserType,
 		Scopes:   scopes,
+		StandardClaims: jwt.StandardClaims{
+			IssuedAt: time.Now().Unix(),
+		},
 	}
 }
 
@@ -70,39 +110,137 @@ func (c *Claims) HasScope(scope string) bool {
 	return result
 }
 
+// IsAdmin checks whether the claims are of an admin allowed to modify data.
+func (c *Claims) IsAdmin() bool {
+	return c.Type == models.UserTypeAdmin && c.HasScope(models.ScopeAPIWrite)
+}
+
+// GetClaims returns the claims of the request authorized by JWTAuth.
+func GetClaims(c *gin.Context) *Claims {
+	if v, ok := c.Get(ClaimsKey); ok {
+		if claims, ok := v.(*Claims); ok {
+			return claims
+		}
+	}
+	return nil
+}
+
+// ParseClaims returns the claims of the valid token in the Authorization
+// header or the access_token parameter of the request.
+func ParseClaims(r *http.Request) (*Claims, error) {
+	token, err := request.ParseFromRequestWithClaims(r, request.OAuth2Extractor, &Claims{}, keyfunc)
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if _, ok := token.Header["kid"]; ok && claims.Issuer != Issuer {
+		return nil, errors.New("invalid issuer")
+	}
+	if KeyData != nil && (isTokenRevoked(claims) || !isKeyValid(claims)) {
+		return nil, ErrRevokedToken
+	}
+	return claims, nil
+}
+
+// RequestClaims returns the claims of the request like ParseClaims, but
+// parses them only once per request, so the rate limit, cache and
+// authentication middlewares share the result.
+func RequestClaims(c *gin.Context) (*Claims, error) {
+	if v, ok := c.Get(parsedClaimsKey); ok {
+		if parsed, ok := v.(parsedClaims); ok {
+			return parsed.claims, parsed.err
+		}
+	}
+	claims, err := ParseClaims(c.Request)
+	c.Set(parsedClaimsKey, parsedClaims{claims: claims, err: err})
+	return claims, err
+}
+
+// SignClaims signs the claims with the current key, or with JWTSecret
+// without Keys. The jti and iss claims are set.
+func SignClaims(claims *Claims) (string, error) {
+	if claims.Id == "" {
+		claims.Id = tokens.NewID()
+	}
+	claims.Issuer = Issuer
+	if Keys != nil {
+		return Keys.Sign(claims)
+	}
+	if JWTSecret == "" {
+		return "", errors.New("missing JWT_SECRET")
+	}
+	token := jwt_lib.NewWithClaims(jwt_lib.SigningMethodHS256, claims)
+	return token.SignedString([]byte(JWTSecret))
+}
+
+// keyfunc returns the key verifying the token. Tokens with a kid header are
+// verified by Keys, others are HS256 tokens signed with JWTSecret.
+func keyfunc(token *jwt_lib.Token) (interface{}, error) {
+	if _, ok := token.Header["kid"]; ok {
+		if Keys == nil {
+			return nil, errors.New("unknown key")
+		}
+		return Keys.Keyfunc(token)
+	}
+	if _, ok := token.Method.(*jwt_lib.SigningMethodHMAC); !ok || JWTSecret == "" {
+		return nil, errors.New("unexpected signing method")
+	}
+	return []byte(JWTSecret), nil
+}
+
+// isKeyValid tells whether the API key of the claims is active and wasn't
+// rotated after the token was issued.
+func isKeyValid(claims *Claims) bool {
+	var apikey *models.APIKey
+	var err error
+	if _, e := primitive.ObjectIDFromHex(claims.Key); e == nil {
+		apikey, err = getAPIKeyByID(KeyData, claims.Key)
+	} else {
+		apikey, err = getAPIKey(KeyData, claims.Key)
+	}
+	if err != nil {
+		return false
+	}
+	return apikey.RotatedAt == nil || claims.IssuedAt >= apikey.RotatedAt.Unix()
+}
+
 // JWTAuth ...
 func JWTAuth(endpoint *Endpoint) gin.HandlerFunc {
 
 	if JWTSecret == "" {
-		if secret := os.Getenv("JWT_SECRET"); secret == "" {
-			log.Fatal(errors.New("missing JWT_SECRET"))
-		} else {
+		if secret := os.Getenv("JWT_SECRET"); secret != "" {
 			JWTSecret = secret
+		} else if Keys == nil {
+			log.Fatal(errors.New("missing JWT_SECRET"))
 		}
 	}
 
 	return func(c *gin.Context) {
-		token, err := request.ParseFromRequestWithClaims(c.Request, request.OAuth2Extractor, &Claims{}, func(token *jwt_lib.Token) (interface{}, error) {
-			return []byte(JWTSecret), nil
-		})
+		claims, err := RequestClaims(c)
 
 		authorized := true
 		if err != nil {
 			// We don't care about the error.
 			authorized = false
 		} else {
-			if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-				if endpoint != nil && endpoint.AdminOnly {
-					authorized = claims.Type == models.UserTypeAdmin && claims.HasScope("api_write")
-				} else {
-					switch c.Request.Method {
-					case "GET":
-						authorized = claims.HasScope("api_read")
-					case "POST", "PUT", "DELETE":
-						authorized = claims.HasScope("api_write")
-					}
+			if endpoint != nil && endpoint.AdminOnly {
+				authorized = claims.IsAdmin()
+			} else if endpoint != nil && endpoint.Scope != "" {
+				authorized = claims.IsAdmin() || claims.HasScope(endpoint.Scope)
+			} else {
+				switch c.Request.Method {
+				case "GET":
+					authorized = claims.HasScope(models.ScopeAPIRead)
+				case "POST", "PUT", "DELETE":
+					authorized = claims.HasScope(models.ScopeAPIWrite)
 				}
 			}
+			if authorized {
+				c.Set(ClaimsKey, claims)
+			}
 		}
 
 		if !authorized {
@@ -0,0 +1,138 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/mongolayer"
+	"github.com/dsbezerra/amenic-lambda/src/lib/tokens"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConnection = "mongodb://localhost/amenic-test"
+
+func getTestingDAL(t *testing.T) persistence.DataAccessLayer {
+	data, err := mongolayer.NewMongoDAL(testConnection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data.Setup()
+	return data
+}
+
+func newTestClaims() *Claims {
+	return &Claims{
+		Type:           models.UserTypeClient,
+		Scopes:         []string{models.ScopeAPIRead},
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()},
+	}
+}
+
+func parseToken(token string) (*Claims, error) {
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return ParseClaims(r)
+}
+
+func TestParseClaims(t *testing.T) {
+	data := getTestingDAL(t)
+	defer data.Close()
+
+	JWTSecret = "secret"
+	Keys = tokens.NewManager(data, tokens.Options{Algorithm: tokens.AlgorithmEdDSA, Secret: "secret"})
+	defer func() { JWTSecret, Keys = "", nil }()
+
+	// Tokens with kid are verified by the keys.
+	token, err := SignClaims(newTestClaims())
+	assert.NoError(t, err)
+	claims, err := parseToken(token)
+	if assert.NoError(t, err) {
+		assert.Equal(t, Issuer, claims.Issuer)
+		assert.NotEmpty(t, claims.Id)
+	}
+
+	// Tokens with kid must be issued by us.
+	other := newTestClaims()
+	other.Issuer = "other"
+	token, err = Keys.Sign(other)
+	assert.NoError(t, err)
+	_, err = parseToken(token)
+	assert.Error(t, err)
+
+	// Tokens of unknown keys are rejected.
+	k, err := tokens.GenerateKey(tokens.AlgorithmEdDSA, time.Now(), time.Hour)
+	assert.NoError(t, err)
+	token, err = k.Sign(newTestClaims())
+	assert.NoError(t, err)
+	_, err = parseToken(token)
+	assert.Error(t, err)
+
+	// Tokens without kid are verified by the secret.
+	token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, newTestClaims()).SignedString([]byte(JWTSecret))
+	assert.NoError(t, err)
+	_, err = parseToken(token)
+	assert.NoError(t, err)
+	token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, newTestClaims()).SignedString([]byte("other"))
+	assert.NoError(t, err)
+	_, err = parseToken(token)
+	assert.Error(t, err)
+}
+
+func TestIsTokenRevoked(t *testing.T) {
+	data := getTestingDAL(t)
+	defer data.Close()
+
+	KeyData = data
+	defer func() { KeyData = nil }()
+
+	// Tokens without jti can't be revoked.
+	assert.False(t, isTokenRevoked(newTestClaims()))
+
+	claims := newTestClaims()
+	claims.Id = tokens.NewID()
+	assert.False(t, isTokenRevoked(claims))
+	assert.NoError(t, RevokeToken(data, claims))
+	assert.True(t, isTokenRevoked(claims))
+
+	// Tokens revoked by other instances are rejected once the cache expires.
+	claims.Id = tokens.NewID()
+	assert.False(t, isTokenRevoked(claims))
+	expiresAt := time.Unix(claims.ExpiresAt, 0)
+	assert.NoError(t, data.InsertRevokedToken(models.RevokedToken{ID: claims.Id, ExpiresAt: &expiresAt}))
+	assert.False(t, isTokenRevoked(claims))
+	revokedTokens.put(claims.Id, false, time.Now())
+	assert.True(t, isTokenRevoked(claims))
+}
+
+func TestRequestClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	JWTSecret = "secret"
+	defer func() { JWTSecret = "" }()
+
+	token, err := SignClaims(newTestClaims())
+	assert.NoError(t, err)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer "+token)
+	claims, err := RequestClaims(c)
+	if assert.NoError(t, err) {
+		assert.Equal(t, Issuer, claims.Issuer)
+	}
+
+	// The claims are parsed once per request.
+	again, err := RequestClaims(c)
+	assert.NoError(t, err)
+	assert.Same(t, claims, again)
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	_, err = RequestClaims(c)
+	assert.Error(t, err)
+}
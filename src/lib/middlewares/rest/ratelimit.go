@@ -0,0 +1,28 @@
+package rest
+
+import (
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// Identify returns a function that identifies the client of requests for rate
+// limiting, from the claims of its JWT token or its api_key parameter.
+// Requests without valid credentials have an empty identity.
+func Identify(data persistence.DataAccessLayer) func(c *gin.Context) ratelimit.Identity {
+	return func(c *gin.Context) ratelimit.Identity {
+		if claims, err := RequestClaims(c); err == nil {
+			return ratelimit.Identity{Key: claims.Key, UserType: claims.Type, Platform: claims.Platform}
+		}
+
+		key := c.Query("api_key")
+		if key == "" || data == nil {
+			return ratelimit.Identity{}
+		}
+		apiKey, err := getAPIKey(data, key)
+		if err != nil {
+			return ratelimit.Identity{}
+		}
+		return ratelimit.Identity{Key: apiKey.ID.Hex(), UserType: apiKey.UserType, Platform: apiKey.Platform}
+	}
+}
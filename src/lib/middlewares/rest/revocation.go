@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+)
+
+// RevokedTokenCacheTTL is how long tokens not revoked are kept in memory.
+// Tokens revoked by other instances are rejected after it.
+const RevokedTokenCacheTTL = 30 * time.Second
+
+// maxRevokedTokenEntries is the size the table is pruned at.
+const maxRevokedTokenEntries = 10000
+
+// revokedTokensTable keeps whether tokens are revoked in memory, to avoid
+// querying the database on every request. Revoked tokens are kept until they
+// expire.
+type revokedTokensTable struct {
+	sync.Mutex
+	m map[string]revokedTokenEntry
+}
+
+type revokedTokenEntry struct {
+	revoked bool
+	until   time.Time
+}
+
+var revokedTokens = &revokedTokensTable{
+	m: make(map[string]revokedTokenEntry),
+}
+
+// RevokeToken rejects the token with the given claims until it expires.
+func RevokeToken(data persistence.DataAccessLayer, claims *Claims) error {
+	if claims.Id == "" {
+		return nil
+	}
+	expiresAt := time.Unix(claims.ExpiresAt, 0)
+	err := data.InsertRevokedToken(models.RevokedToken{ID: claims.Id, ExpiresAt: &expiresAt})
+	if err != nil {
+		return err
+	}
+	revokedTokens.put(claims.Id, true, expiresAt)
+	return nil
+}
+
+// isTokenRevoked tells whether the token was revoked. Tokens without jti
+// can't be revoked.
+func isTokenRevoked(claims *Claims) bool {
+	if claims.Id == "" {
+		return false
+	}
+	now := time.Now()
+	if revoked, ok := revokedTokens.get(claims.Id, now); ok {
+		return revoked
+	}
+	revoked, err := KeyData.IsTokenRevoked(claims.Id)
+	if err != nil {
+		return true
+	}
+	until := now.Add(RevokedTokenCacheTTL)
+	if revoked {
+		until = time.Unix(claims.ExpiresAt, 0)
+	}
+	revokedTokens.put(claims.Id, revoked, until)
+	return revoked
+}
+
+func (t *revokedTokensTable) put(id string, revoked bool, until time.Time) {
+	t.Lock()
+	if len(t.m) >= maxRevokedTokenEntries {
+		now := time.Now()
+		for k, v := range t.m {
+			if !now.Before(v.until) {
+				delete(t.m, k)
+			}
+		}
+	}
+	t.m[id] = revokedTokenEntry{revoked: revoked, until: until}
+	t.Unlock()
+}
+
+// get returns whether the token is revoked. Outdated entries are removed.
+func (t *revokedTokensTable) get(id string, now time.Time) (bool, bool) {
+	t.Lock()
+	defer t.Unlock()
+	entry, ok := t.m[id]
+	if !ok {
+		return false, false
+	}
+	if !now.Before(entry.until) {
+		delete(t.m, id)
+		return false, false
+	}
+	return entry.revoked, true
+}
@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/openapi"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxBodySize is the largest body ValidateRequest reads, in bytes.
+const MaxBodySize = 1 << 20
+
+// ValidateRequest middleware checks the parameters and body of requests
+// against the operation of the matched route in the given document. Routes
+// without operation are not checked. Invalid requests get a
+// validation_failed error naming each invalid field and bodies larger than
+// MaxBodySize a request_too_large error.
+func ValidateRequest(doc *openapi.Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := doc.Operation(c.Request.Method, c.FullPath())
+		if op == nil {
+			return
+		}
+
+		query := c.Request.URL.Query()
+		errs := op.ValidateParameters(func(p *openapi.Parameter) (string, bool) {
+			switch p.In {
+			case openapi.InPath:
+				v := c.Param(p.Name)
+				return v, v != ""
+			case openapi.InQuery:
+				v, ok := query[p.Name]
+				if !ok {
+					return "", false
+				}
+				return v[0], true
+			case openapi.InHeader:
+				v := c.GetHeader(p.Name)
+				return v, v != ""
+			}
+			return "", false
+		})
+
+		if op.RequestBody != nil && c.Request.Body != nil {
+			body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxBodySize))
+			if err != nil {
+				if len(body) >= MaxBodySize {
+					apiutil.SendRequestTooLarge(c)
+				} else {
+					apiutil.SendBadRequest(c)
+				}
+				return
+			}
+			// Restore the body so handlers can bind it.
+			c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+			errs = append(errs, op.ValidateBody(c.ContentType(), body)...)
+		}
+
+		if len(errs) == 0 {
+			return
+		}
+		details := make([]*apiutil.APIErrorDetail, len(errs))
+		for i, err := range errs {
+			details[i] = &apiutil.APIErrorDetail{Field: err.Field, In: err.In, Message: err.Message}
+		}
+		apiutil.SendValidationError(c, details)
+	}
+}
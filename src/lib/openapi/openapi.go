@@ -0,0 +1,214 @@
+// Package openapi describes HTTP APIs with OpenAPI 3 documents and validates
+// requests against them.
+package openapi
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Version is the OpenAPI version of the documents.
+const Version = "3.0.3"
+
+// Parameter locations.
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+	InBody   = "body" // Only used by validation errors
+)
+
+// Schema types.
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeArray   = "array"
+	TypeObject  = "object"
+)
+
+type (
+	// Document is the root of an OpenAPI document.
+	Document struct {
+		OpenAPI    string               `json:"openapi"`
+		Info       Info                 `json:"info"`
+		Servers    []Server             `json:"servers,omitempty"`
+		Paths      map[string]*PathItem `json:"paths"`
+		Components *Components          `json:"components,omitempty"`
+		Security   []map[string][]string `json:"security,omitempty"`
+	}
+
+	// Info holds the metadata of the API.
+	Info struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version"`
+	}
+
+	// Server is a base URL of the API.
+	Server struct {
+		URL string `json:"url"`
+	}
+
+	// Components holds the reusable objects of the document.
+	Components struct {
+		Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// SecurityScheme describes how requests are authenticated.
+	SecurityScheme struct {
+		Type         string `json:"type"`
+		Scheme       string `json:"scheme,omitempty"`
+		BearerFormat string `json:"bearerFormat,omitempty"`
+		Name         string `json:"name,omitempty"`
+		In           string `json:"in,omitempty"`
+		Description  string `json:"description,omitempty"`
+	}
+
+	// PathItem holds the operations of a path, by lowercase HTTP method.
+	PathItem map[string]*Operation
+
+	// Operation describes a single route.
+	Operation struct {
+		OperationID string                `json:"operationId,omitempty"`
+		Summary     string                `json:"summary,omitempty"`
+		Tags        []string              `json:"tags,omitempty"`
+		Parameters  []*Parameter          `json:"parameters,omitempty"`
+		RequestBody *RequestBody          `json:"requestBody,omitempty"`
+		Responses   map[string]*Response  `json:"responses"`
+		Security    []map[string][]string `json:"security,omitempty"`
+	}
+
+	// Parameter is a path, query or header parameter of an operation.
+	Parameter struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required,omitempty"`
+		Schema      *Schema `json:"schema"`
+	}
+
+	// RequestBody describes the body of an operation.
+	RequestBody struct {
+		Description string                `json:"description,omitempty"`
+		Required    bool                  `json:"required,omitempty"`
+		Content     map[string]*MediaType `json:"content"`
+	}
+
+	// Response describes a response of an operation.
+	Response struct {
+		Description string                `json:"description"`
+		Content     map[string]*MediaType `json:"content,omitempty"`
+	}
+
+	// MediaType holds the schema of a body.
+	MediaType struct {
+		Schema *Schema `json:"schema,omitempty"`
+	}
+)
+
+// ContentJSON is the media type of JSON bodies.
+const ContentJSON = "application/json"
+
+var pathParamRegex = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// New creates an empty document.
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   map[string]*PathItem{},
+		Components: &Components{
+			Schemas:         map[string]*Schema{},
+			SecuritySchemes: map[string]*SecurityScheme{},
+		},
+	}
+}
+
+// Path converts a gin route path, eg: /movies/movie/:id, to its OpenAPI
+// form, eg: /movies/movie/{id}.
+func Path(route string) string {
+	return pathParamRegex.ReplaceAllString(route, "{$1}")
+}
+
+// AddOperation adds the operation of the given method and gin route path.
+// Path parameters that are not described by the operation are added as
+// required strings.
+func (d *Document) AddOperation(method, route string, op *Operation) {
+	path := Path(route)
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+
+	for _, m := range pathParamRegex.FindAllStringSubmatch(route, -1) {
+		if op.Parameter(m[1], InPath) == nil {
+			op.Parameters = append(op.Parameters, &Parameter{
+				Name:     m[1],
+				In:       InPath,
+				Required: true,
+				Schema:   &Schema{Type: TypeString},
+			})
+		}
+	}
+	if op.Responses == nil {
+		op.Responses = map[string]*Response{"200": {Description: "OK"}}
+	}
+	(*item)[strings.ToLower(method)] = op
+}
+
+// Operation returns the operation of the given method and gin route path,
+// or nil if there is none.
+func (d *Document) Operation(method, route string) *Operation {
+	item, ok := d.Paths[Path(route)]
+	if !ok {
+		return nil
+	}
+	return (*item)[strings.ToLower(method)]
+}
+
+// Parameter returns the parameter with the given name and location, or nil
+// if there is none.
+func (o *Operation) Parameter(name, in string) *Parameter {
+	for _, p := range o.Parameters {
+		if p.Name == name && p.In == in {
+			return p
+		}
+	}
+	return nil
+}
+
+// WithParameters adds the given parameters to the operation and returns it.
+// Parameters with the same name and location are replaced.
+func (o *Operation) WithParameters(params ...*Parameter) *Operation {
+	for _, p := range params {
+		replaced := false
+		for i, v := range o.Parameters {
+			if v.Name == p.Name && v.In == p.In {
+				o.Parameters[i] = p
+				replaced = true
+			}
+		}
+		if !replaced {
+			o.Parameters = append(o.Parameters, p)
+		}
+	}
+	return o
+}
+
+// WithBody sets the required JSON body of the operation and returns it.
+func (o *Operation) WithBody(schema *Schema) *Operation {
+	o.RequestBody = JSONBody(schema, true)
+	return o
+}
+
+// JSONBody creates a request body with the given JSON schema.
+func JSONBody(schema *Schema, required bool) *RequestBody {
+	return &RequestBody{
+		Required: required,
+		Content:  map[string]*MediaType{ContentJSON: {Schema: schema}},
+	}
+}
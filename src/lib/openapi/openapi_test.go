@@ -0,0 +1,87 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+)
+
+type testMovie struct {
+	Title       string     `json:"title"`
+	Runtime     int        `json:"runtime"`
+	Cast        []string   `json:"cast"`
+	ReleaseDate *time.Time `json:"releaseDate,omitempty"`
+	Secret      string     `json:"-"`
+}
+
+func TestPath(t *testing.T) {
+	if got := Path("/movies/movie/:id/sessions"); got != "/movies/movie/{id}/sessions" {
+		t.Errorf("Path() = %s", got)
+	}
+}
+
+func TestSchemaOf(t *testing.T) {
+	s := SchemaOf(testMovie{})
+	if s.Type != TypeObject {
+		t.Fatalf("expected object, got %s", s.Type)
+	}
+	if _, ok := s.Properties["Secret"]; ok {
+		t.Error("fields tagged with - must be skipped")
+	}
+	if p := s.Properties["runtime"]; p == nil || p.Type != TypeInteger {
+		t.Error("runtime must be an integer")
+	}
+	if p := s.Properties["releaseDate"]; p == nil || p.Format != "date-time" || !p.Nullable {
+		t.Error("releaseDate must be a nullable date-time")
+	}
+}
+
+func TestValidateBody(t *testing.T) {
+	op := &Operation{RequestBody: JSONBody(SchemaOf(testMovie{}).WithRequired("title"), true)}
+
+	if errs := op.ValidateBody(ContentJSON, []byte(`{"title":"Coringa","runtime":122,"cast":["Joaquin Phoenix"]}`)); len(errs) != 0 {
+		t.Errorf("expected valid body, got %v", errs)
+	}
+
+	errs := op.ValidateBody(ContentJSON, []byte(`{"runtime":"122","cast":[1]}`))
+	want := map[string]bool{"title": true, "runtime": true, "cast[0]": true}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+	for _, err := range errs {
+		if !want[err.Field] || err.In != InBody {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+
+	if errs := op.ValidateBody(ContentJSON, []byte(`{`)); len(errs) != 1 {
+		t.Errorf("expected invalid JSON error, got %v", errs)
+	}
+}
+
+func TestValidateParameters(t *testing.T) {
+	op := &Operation{Parameters: []*Parameter{
+		{Name: "id", In: InPath, Required: true, Schema: ObjectID()},
+		{Name: "page", In: InQuery, Schema: Integer().WithMinimum(1)},
+		{Name: "upcoming", In: InQuery, Schema: Boolean()},
+		{Name: "date", In: InQuery, Schema: Date()},
+	}}
+
+	values := map[string]string{
+		"id":       "5d9f6d3c8f1b2a0001a1b2c3",
+		"page":     "0",
+		"upcoming": "yes",
+		"date":     "2019-10-31",
+	}
+	errs := op.ValidateParameters(func(p *Parameter) (string, bool) {
+		v, ok := values[p.Name]
+		return v, ok
+	})
+	if len(errs) != 2 || errs[0].Field != "page" || errs[1].Field != "upcoming" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+
+	errs = op.ValidateParameters(func(p *Parameter) (string, bool) { return "", false })
+	if len(errs) != 1 || errs[0].Field != "id" {
+		t.Errorf("expected missing id error, got %v", errs)
+	}
+}
@@ -0,0 +1,214 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ObjectIDPattern matches MongoDB object IDs in hex format.
+const ObjectIDPattern = "^[0-9a-fA-F]{24}$"
+
+// DatePattern matches dates in YYYY-MM-DD format.
+const DatePattern = `^\d{4}-\d{2}-\d{2}$`
+
+// Schema is the subset of JSON Schema supported by OpenAPI 3 that this
+// package validates. References are not resolved, so they are only meant
+// for responses.
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Nullable    bool               `json:"nullable,omitempty"`
+	Default     interface{}        `json:"default,omitempty"`
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+)
+
+// Ref creates a reference to the schema with the given name in the
+// components of the document.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// String creates a string schema.
+func String() *Schema {
+	return &Schema{Type: TypeString}
+}
+
+// Integer creates an integer schema.
+func Integer() *Schema {
+	return &Schema{Type: TypeInteger}
+}
+
+// Boolean creates a boolean schema.
+func Boolean() *Schema {
+	return &Schema{Type: TypeBoolean}
+}
+
+// ObjectID creates the schema of object IDs in hex format.
+func ObjectID() *Schema {
+	return &Schema{Type: TypeString, Pattern: ObjectIDPattern}
+}
+
+// Date creates the schema of dates in YYYY-MM-DD format.
+func Date() *Schema {
+	return &Schema{Type: TypeString, Format: "date", Pattern: DatePattern}
+}
+
+// ArrayOf creates the schema of an array of the given items.
+func ArrayOf(items *Schema) *Schema {
+	return &Schema{Type: TypeArray, Items: items}
+}
+
+// WithMinimum sets the minimum of the schema and returns it.
+func (s *Schema) WithMinimum(min float64) *Schema {
+	s.Minimum = &min
+	return s
+}
+
+// WithMaximum sets the maximum of the schema and returns it.
+func (s *Schema) WithMaximum(max float64) *Schema {
+	s.Maximum = &max
+	return s
+}
+
+// WithPattern sets the pattern of the schema and returns it.
+func (s *Schema) WithPattern(pattern string) *Schema {
+	s.Pattern = pattern
+	return s
+}
+
+// WithEnum sets the allowed values of the schema and returns it.
+func (s *Schema) WithEnum(values ...interface{}) *Schema {
+	s.Enum = values
+	return s
+}
+
+// WithRequired marks the given properties as required and returns the
+// schema.
+func (s *Schema) WithRequired(names ...string) *Schema {
+	s.Required = append(s.Required, names...)
+	return s
+}
+
+// SchemaOf creates the schema of the JSON encoding of v, following its
+// json struct tags.
+func SchemaOf(v interface{}) *Schema {
+	return schemaOfType(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+func schemaOfType(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		nullable = true
+		t = t.Elem()
+	}
+
+	var result *Schema
+	switch {
+	case t == timeType:
+		result = &Schema{Type: TypeString, Format: "date-time"}
+	case t == objectIDType:
+		result = ObjectID()
+	default:
+		switch t.Kind() {
+		case reflect.Bool:
+			result = Boolean()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			result = Integer()
+		case reflect.Float32, reflect.Float64:
+			result = &Schema{Type: TypeNumber}
+		case reflect.String:
+			result = String()
+		case reflect.Slice, reflect.Array:
+			result = ArrayOf(schemaOfType(t.Elem(), seen))
+			nullable = nullable || t.Kind() == reflect.Slice
+		case reflect.Map:
+			result = &Schema{Type: TypeObject}
+			nullable = true
+		case reflect.Struct:
+			result = structSchema(t, seen)
+		default:
+			result = &Schema{}
+		}
+	}
+	result.Nullable = nullable
+	return result
+}
+
+func structSchema(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	result := &Schema{Type: TypeObject, Properties: map[string]*Schema{}}
+	// Recursive types stop at the first repetition.
+	if seen[t] {
+		return result
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded := structSchema(ft, seen)
+				for k, v := range embedded.Properties {
+					if _, ok := result.Properties[k]; !ok {
+						result.Properties[k] = v
+					}
+				}
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		result.Properties[name] = schemaOfType(field.Type, seen)
+	}
+	return result
+}
+
+// jsonName returns the name of the field in its json tag, and whether
+// the field is skipped by encoding/json.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	if field.PkgPath != "" && !field.Anonymous {
+		return "", true
+	}
+	name := strings.Split(tag, ",")[0]
+	return name, false
+}
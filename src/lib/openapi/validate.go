@@ -0,0 +1,269 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FieldError describes an invalid field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	In      string `json:"in"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.In, e.Field, e.Message)
+}
+
+var (
+	patternsMu sync.Mutex
+	patterns   = map[string]*regexp.Regexp{}
+)
+
+// ValidateParameters checks the parameters of the operation. The get
+// function returns the raw value of a parameter and whether it is present.
+func (o *Operation) ValidateParameters(get func(p *Parameter) (string, bool)) []*FieldError {
+	errs := make([]*FieldError, 0)
+	for _, p := range o.Parameters {
+		raw, ok := get(p)
+		if !ok || raw == "" {
+			if p.Required {
+				errs = append(errs, &FieldError{Field: p.Name, In: p.In, Message: "is required"})
+			}
+			continue
+		}
+		value, err := p.parse(raw)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, p.Schema.Validate(p.Name, p.In, value)...)
+	}
+	return errs
+}
+
+// ValidateBody checks the body of a request to the operation. Only JSON
+// bodies are checked.
+func (o *Operation) ValidateBody(contentType string, body []byte) []*FieldError {
+	if o.RequestBody == nil {
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if o.RequestBody.Required {
+			return []*FieldError{{Field: "", In: InBody, Message: "is required"}}
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" {
+		mediaType = ContentJSON
+	}
+	content, ok := o.RequestBody.Content[mediaType]
+	if !ok {
+		return []*FieldError{{Field: "", In: InBody, Message: fmt.Sprintf("unsupported content type %s", mediaType)}}
+	}
+	if content.Schema == nil || mediaType != ContentJSON {
+		return nil
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return []*FieldError{{Field: "", In: InBody, Message: "is not valid JSON"}}
+	}
+	return content.Schema.Validate("", InBody, value)
+}
+
+// parse converts the raw value of a parameter to the JSON value of its
+// schema. Arrays are comma separated.
+func (p *Parameter) parse(raw string) (interface{}, *FieldError) {
+	schema := p.Schema
+	if schema == nil {
+		return raw, nil
+	}
+	if schema.Type == TypeArray {
+		items := make([]interface{}, 0)
+		for _, v := range strings.Split(raw, ",") {
+			item, err := parseScalar(schema.Items, strings.TrimSpace(v))
+			if err != nil {
+				return nil, &FieldError{Field: p.Name, In: p.In, Message: err.Error()}
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	value, err := parseScalar(schema, raw)
+	if err != nil {
+		return nil, &FieldError{Field: p.Name, In: p.In, Message: err.Error()}
+	}
+	return value, nil
+}
+
+func parseScalar(schema *Schema, raw string) (interface{}, error) {
+	if schema == nil {
+		return raw, nil
+	}
+	switch schema.Type {
+	case TypeInteger:
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return json.Number(raw), nil
+	case TypeNumber:
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return json.Number(raw), nil
+	case TypeBoolean:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		return b, nil
+	}
+	return raw, nil
+}
+
+// Validate checks a JSON value decoded with json.Decoder.UseNumber against
+// the schema. The field is the path of the value, eg: cast[0].
+func (s *Schema) Validate(field, in string, value interface{}) []*FieldError {
+	if s == nil || s.Ref != "" {
+		return nil
+	}
+	fail := func(format string, args ...interface{}) []*FieldError {
+		return []*FieldError{{Field: field, In: in, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	if value == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return fail("must not be null")
+	}
+
+	switch s.Type {
+	case TypeString:
+		v, ok := value.(string)
+		if !ok {
+			return fail("must be a string")
+		}
+		if s.MinLength != nil && len(v) < *s.MinLength {
+			return fail("must have at least %d characters", *s.MinLength)
+		}
+		if s.Pattern != "" && !matchPattern(s.Pattern, v) {
+			return fail("must match %s", s.Pattern)
+		}
+	case TypeInteger, TypeNumber:
+		n, ok := value.(json.Number)
+		if !ok {
+			return fail("must be a %s", s.Type)
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return fail("must be a %s", s.Type)
+		}
+		if s.Type == TypeInteger {
+			if _, err := n.Int64(); err != nil {
+				return fail("must be an integer")
+			}
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			return fail("must be greater than or equal to %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			return fail("must be less than or equal to %v", *s.Maximum)
+		}
+	case TypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return fail("must be a boolean")
+		}
+	case TypeArray:
+		items, ok := value.([]interface{})
+		if !ok {
+			return fail("must be an array")
+		}
+		errs := make([]*FieldError, 0)
+		for i, item := range items {
+			errs = append(errs, s.Items.Validate(fmt.Sprintf("%s[%d]", field, i), in, item)...)
+		}
+		return errs
+	case TypeObject:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fail("must be an object")
+		}
+		return s.validateProperties(field, in, obj)
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		return fail("must be one of %s", enumString(s.Enum))
+	}
+	return nil
+}
+
+func (s *Schema) validateProperties(field, in string, obj map[string]interface{}) []*FieldError {
+	errs := make([]*FieldError, 0)
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			errs = append(errs, &FieldError{Field: join(field, name), In: in, Message: "is required"})
+		}
+	}
+
+	// Sorted so errors are reported in a stable order.
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if v, ok := obj[name]; ok {
+			errs = append(errs, s.Properties[name].Validate(join(field, name), in, v)...)
+		}
+	}
+	return errs
+}
+
+func join(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func matchPattern(pattern, value string) bool {
+	patternsMu.Lock()
+	re, ok := patterns[pattern]
+	if !ok {
+		re = regexp.MustCompile(pattern)
+		patterns[pattern] = re
+	}
+	patternsMu.Unlock()
+	return re.MatchString(value)
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, v := range enum {
+		if fmt.Sprint(v) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumString(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, v := range enum {
+		values[i] = fmt.Sprint(v)
+	}
+	return strings.Join(values, ", ")
+}
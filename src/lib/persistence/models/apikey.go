@@ -1,6 +1,10 @@
 package models
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"math"
 	"time"
 
@@ -13,17 +17,132 @@ const (
 
 	// UserTypeClient ...
 	UserTypeClient = "client"
+
+	// APIKeyPrefixLength is the length of the key prefix stored in plain text
+	// to find keys.
+	APIKeyPrefixLength = 8
 )
 
-// APIKey represents an API key document.
+// APIKey represents an API key document. Keys are stored as salted hashes,
+// only their prefix is kept in plain text.
 type APIKey struct {
-	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id"`             // Document id.
-	Key       string             `json:"key,omitempty" bson:"key"`             // Actual key.
-	Name      string             `json:"name,omitempty" bson:"name"`           // Name used to identify key.
-	UserType  string             `json:"user_type,omitempty" bson:"user_type"` // Which user type is this key such as admin, client or whatever else we need (which we will not).
-	Platform  string             `json:"platform,omitempty" bson:"platform"`   // Which platform is using this key
-	Owner     string             `json:"owner,omitempty" bson:"owner"`         // Whoever created this key, probably the username.
-	Timestamp *time.Time         `json:"iat,omitempty" bson:"iat"`             // The time when the key was created.
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id"`                       // Document id.
+	Key       string             `json:"-" bson:"key,omitempty"`                         // Actual key, only set in keys created before hashing.
+	Prefix    string             `json:"prefix,omitempty" bson:"prefix,omitempty"`       // First characters of the key, used to find it.
+	Hash      string             `json:"-" bson:"hash,omitempty"`                        // Salted SHA-256 hash of the key.
+	Salt      string             `json:"-" bson:"salt,omitempty"`                        // Random salt of the hash.
+	Name      string             `json:"name,omitempty" bson:"name"`                     // Name used to identify key.
+	UserType  string             `json:"user_type,omitempty" bson:"user_type"`           // Which user type is this key such as admin, client or whatever else we need (which we will not).
+	Platform  string             `json:"platform,omitempty" bson:"platform"`             // Which platform is using this key
+	Platforms []string           `json:"platforms,omitempty" bson:"platforms,omitempty"` // Platforms allowed to use this key, any if empty.
+	Role      string             `json:"role,omitempty" bson:"role,omitempty"`           // Role bundling the scopes of the key, defaults to the user type.
+	Scopes    []string           `json:"scopes,omitempty" bson:"scopes,omitempty"`       // Scopes granted besides the ones of the role.
+	Owner     string             `json:"owner,omitempty" bson:"owner"`                   // Whoever created this key, probably the username.
+	Timestamp *time.Time         `json:"iat,omitempty" bson:"iat"`                       // The time when the key was created.
+	ExpiresAt *time.Time         `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	RevokedAt *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	RotatedAt *time.Time         `json:"rotated_at,omitempty" bson:"rotated_at,omitempty"` // Tokens issued before the last rotation are invalid.
+}
+
+// GenerateAPIKey creates a random key.
+func GenerateAPIKey() (string, error) {
+	return randomHex(32)
+}
+
+// SetKey stores the hash and prefix of the given key, replacing the current
+// one.
+func (m *APIKey) SetKey(key string) error {
+	salt, err := randomHex(16)
+	if err != nil {
+		return err
+	}
+	m.Key = ""
+	m.Prefix = APIKeyPrefix(key)
+	m.Salt = salt
+	m.Hash = hashAPIKey(salt, key)
+	return nil
+}
+
+// Matches tells whether the given key is this one.
+func (m APIKey) Matches(key string) bool {
+	if m.Hash == "" {
+		return m.Key != "" && subtle.ConstantTimeCompare([]byte(m.Key), []byte(key)) == 1
+	}
+	return subtle.ConstantTimeCompare([]byte(m.Hash), []byte(hashAPIKey(m.Salt, key))) == 1
+}
+
+// IsActive tells whether the key is neither revoked nor expired.
+func (m APIKey) IsActive(now time.Time) bool {
+	if m.RevokedAt != nil {
+		return false
+	}
+	return m.ExpiresAt == nil || now.Before(*m.ExpiresAt)
+}
+
+// AllowsPlatform tells whether the given platform can use this key.
+func (m APIKey) AllowsPlatform(platform string) bool {
+	if len(m.Platforms) == 0 {
+		return true
+	}
+	for _, p := range m.Platforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantedScopes returns the scopes of the role of the key plus its own.
+// Unknown scopes and the ones its user type can't be granted are ignored.
+func (m APIKey) GrantedScopes() []string {
+	role := m.Role
+	if role == "" {
+		role = m.UserType
+	}
+	result := []string{}
+	for _, scopes := range [][]string{Roles[role], m.Scopes} {
+		for _, s := range scopes {
+			if IsScope(s) && AllowsScope(m.UserType, s) && !containsString(result, s) {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+// HasScope tells whether the key is granted the given scope.
+func (m APIKey) HasScope(scope string) bool {
+	return containsString(m.GrantedScopes(), scope)
+}
+
+// APIKeyPrefix returns the prefix of the given key.
+func APIKeyPrefix(key string) string {
+	if len(key) > APIKeyPrefixLength {
+		return key[:APIKeyPrefixLength]
+	}
+	return key
+}
+
+func hashAPIKey(salt, key string) string {
+	sum := sha256.Sum256([]byte(salt + key))
+	return hex.EncodeToString(sum[:])
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
 
 // UserTypeLevel ...
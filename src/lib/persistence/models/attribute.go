@@ -0,0 +1,115 @@
+package models
+
+// Attribute kinds
+const (
+	AttributeKindFormat        = "format"
+	AttributeKindVersion       = "version"
+	AttributeKindRoom          = "room"
+	AttributeKindAccessibility = "accessibility"
+)
+
+// Attribute IDs. Formats and versions keep the IDs of Format and Version
+// values so existing clients keep working.
+const (
+	Attribute2D               = Format2D
+	Attribute3D               = Format3D
+	AttributeIMAX             = "imax"
+	AttributeVIP              = "vip"
+	AttributeDBOX             = "dbox"
+	AttributeMagicD           = "magic_d"
+	AttributeDubbed           = VersionDubbed
+	AttributeSubtitled        = VersionSubtitled
+	AttributeNational         = VersionNational
+	AttributeAudioDescription = "audio_description"
+	AttributeLibras           = "libras"
+	AttributeClosedCaption    = "closed_caption"
+)
+
+// Attribute is a feature of a session, like its format or the room it is in.
+type Attribute struct {
+	ID   string `json:"_id"`
+	Kind string `json:"kind"`
+	Name string `json:"name"` // Name shown to users, in portuguese
+}
+
+// attributes is the catalog of attributes in the order they are displayed.
+var attributes = []Attribute{
+	{Attribute2D, AttributeKindFormat, "2D"},
+	{Attribute3D, AttributeKindFormat, "3D"},
+	{AttributeDubbed, AttributeKindVersion, "Dublado"},
+	{AttributeSubtitled, AttributeKindVersion, "Legendado"},
+	{AttributeNational, AttributeKindVersion, "Nacional"},
+	{AttributeIMAX, AttributeKindRoom, "IMAX"},
+	{AttributeVIP, AttributeKindRoom, "VIP"},
+	{AttributeDBOX, AttributeKindRoom, "D-BOX"},
+	{AttributeMagicD, AttributeKindRoom, "Magic D"},
+	{AttributeAudioDescription, AttributeKindAccessibility, "Audiodescrição"},
+	{AttributeLibras, AttributeKindAccessibility, "Libras"},
+	{AttributeClosedCaption, AttributeKindAccessibility, "Legenda descritiva"},
+}
+
+// formatWeights and roomWeights rank prices by their attributes, so prices of
+// premium rooms and formats are listed after regular ones.
+var (
+	formatWeights = map[string]uint{
+		Attribute2D: 1,
+		Attribute3D: 2,
+	}
+	roomWeights = map[string]uint{
+		AttributeMagicD: 2,
+		AttributeIMAX:   3,
+		AttributeVIP:    3,
+		AttributeDBOX:   3,
+	}
+)
+
+// GetAttributeList returns the attribute catalog.
+func GetAttributeList() []Attribute {
+	result := make([]Attribute, len(attributes))
+	copy(result, attributes)
+	return result
+}
+
+// GetAttribute returns the attribute with the given ID.
+func GetAttribute(id string) (Attribute, bool) {
+	for _, a := range attributes {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return Attribute{}, false
+}
+
+// SortAttributes sorts the attribute IDs in the catalog order. Unknown IDs
+// are dropped.
+func SortAttributes(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	result := make([]string, 0, len(ids))
+	for _, a := range attributes {
+		if seen[a.ID] {
+			result = append(result, a.ID)
+		}
+	}
+	return result
+}
+
+// GetAttributesWeight ranks the attribute IDs of a price: the heaviest format
+// plus the heaviest room. Prices without a format weigh zero.
+func GetAttributesWeight(ids []string) uint {
+	var format, room uint
+	for _, id := range ids {
+		if w := formatWeights[id]; w > format {
+			format = w
+		}
+		if w := roomWeights[id]; w > room {
+			room = w
+		}
+	}
+	if format == 0 {
+		return 0
+	}
+	return format + room
+}
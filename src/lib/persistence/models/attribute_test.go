@@ -0,0 +1,26 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAttributesWeight(t *testing.T) {
+	cases := []struct {
+		ids      []string
+		expected uint
+	}{
+		{[]string{Attribute2D}, 1},
+		{[]string{Attribute3D}, 2},
+		{[]string{Attribute2D, AttributeMagicD}, 3},
+		{[]string{Attribute3D, AttributeMagicD}, 4},
+		{[]string{Attribute2D, Attribute3D, AttributeVIP, AttributeMagicD}, 5},
+		{[]string{Attribute3D, AttributeSubtitled}, 2},
+		{[]string{AttributeVIP}, 0},
+		{nil, 0},
+	}
+	for _, test := range cases {
+		assert.Equal(t, test.expected, GetAttributesWeight(test.ids), "%v", test.ids)
+	}
+}
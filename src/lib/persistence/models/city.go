@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -15,3 +17,12 @@ type City struct {
 	CreatedAt *time.Time         `json:"createdAt,omitempty" bson:"createdAt"`
 	UpdatedAt *time.Time         `json:"updatedAt,omitempty" bson:"updatedAt"`
 }
+
+// Location returns the time zone of the city. Cities without one, or a nil
+// city, use the default time zone.
+func (c *City) Location() *time.Location {
+	if c == nil {
+		return timeutil.DefaultLocation()
+	}
+	return timeutil.LoadLocation(c.TimeZone)
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// DataVersion is a counter bumped whenever scrapers update the data served by
+// the API. Clients use it to tell whether cached responses are still fresh.
+type DataVersion struct {
+	ID        string     `json:"-" bson:"_id"`
+	Version   int64      `json:"version" bson:"version"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+}
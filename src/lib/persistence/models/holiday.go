@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Holiday scopes
+const (
+	HolidayNational  = "national"
+	HolidayState     = "state"
+	HolidayMunicipal = "municipal"
+)
+
+// Holiday is a holiday added by an admin on top of the built-in calendar.
+// Removed overrides mark a date of the calendar as a regular day.
+type Holiday struct {
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id"`
+	Date      string             `json:"date" bson:"date"` // Date in YYYY-MM-DD format
+	Name      string             `json:"name" bson:"name"`
+	State     State              `json:"state,omitempty" bson:"state,omitempty"`
+	CityID    primitive.ObjectID `json:"cityId,omitempty" bson:"cityId,omitempty"`
+	Removed   bool               `json:"removed" bson:"removed"`
+	CreatedAt *time.Time         `json:"createdAt,omitempty" bson:"createdAt"`
+	UpdatedAt *time.Time         `json:"updatedAt,omitempty" bson:"updatedAt"`
+}
+
+// Scope returns whether the holiday applies to the whole country, a state or a city.
+func (h *Holiday) Scope() string {
+	if !h.CityID.IsZero() {
+		return HolidayMunicipal
+	}
+	if h.State != "" {
+		return HolidayState
+	}
+	return HolidayNational
+}
@@ -43,5 +43,6 @@ type Movie struct {
 	Theaters      []Theater          `json:"cinemas,omitempty" bson:"theaters,omitempty"`
 	Scores        []Score            `json:"scores,omitempty" bson:"scores,omitempty"`
 	Sessions      []Session          `json:"sessions,omitempty" bson:"sessions,omitempty"`
+	SessionTypes  []string           `json:"sessionTypes,omitempty" bson:"sessionTypes,omitempty"` // SessionTypes of the now playing sessions
 	LockFlags     uint64             `json:"-" bson:"lockFlags,omitempty"`
 }
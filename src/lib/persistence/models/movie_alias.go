@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MovieAlias binds a title shown by theaters to a movie, so scraped movies
+// with that title are matched without review.
+type MovieAlias struct {
+	ID        primitive.ObjectID `json:"_id" bson:"_id"`               // ID is the document identifier
+	Alias     string             `json:"alias" bson:"alias"`           // Alias is the slug of the title without dashes
+	Title     string             `json:"title" bson:"title"`           // Title as shown by the theater
+	MovieID   primitive.ObjectID `json:"movie_id" bson:"movie_id"`     // MovieID is the movie the title refers to
+	CreatedAt *time.Time         `json:"created_at" bson:"created_at"` // CreatedAt is the time the alias was created
+}
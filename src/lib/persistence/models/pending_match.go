@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// PendingMatchPending indicates the match is waiting for an admin.
+	PendingMatchPending = "pending"
+	// PendingMatchConfirmed indicates the best candidate was confirmed.
+	PendingMatchConfirmed = "confirmed"
+	// PendingMatchReassigned indicates the movie was assigned to another movie.
+	PendingMatchReassigned = "reassigned"
+	// PendingMatchCreated indicates a new movie was created from the scraped one.
+	PendingMatchCreated = "created"
+)
+
+type (
+	// PendingMatch is a scraped movie whose best match wasn't good enough to
+	// be used without review.
+	PendingMatch struct {
+		ID         primitive.ObjectID `json:"_id" bson:"_id"`                                     // ID is the document identifier
+		Key        string             `json:"key" bson:"key"`                                     // Key identifies the scraped movie, it's the slug of its title without dashes
+		Movie      Movie              `json:"movie" bson:"movie"`                                 // Movie is the scraped movie
+		Candidates []MatchCandidate   `json:"candidates" bson:"candidates"`                       // Candidates sorted by score, best first
+		Status     string             `json:"status" bson:"status"`                               // Status is one of pending, confirmed, reassigned or created
+		MovieID    primitive.ObjectID `json:"movie_id,omitempty" bson:"movie_id,omitempty"`       // MovieID is the movie the scraped one was resolved to
+		CreatedAt  *time.Time         `json:"created_at" bson:"created_at"`                       // CreatedAt is the time the match was first reported
+		UpdatedAt  *time.Time         `json:"updated_at" bson:"updated_at"`                       // UpdatedAt is the time the match was last reported
+		ResolvedAt *time.Time         `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"` // ResolvedAt is the time an admin resolved the match
+	}
+
+	// MatchCandidate is a movie that may be the scraped one.
+	MatchCandidate struct {
+		MovieID primitive.ObjectID `json:"movie_id" bson:"movie_id"` // MovieID is the candidate movie
+		Title   string             `json:"title" bson:"title"`       // Title of the candidate movie
+		Score   float64            `json:"score" bson:"score"`       // Score is the match confidence, from 0 to 1
+		Signals map[string]float64 `json:"signals" bson:"signals"`   // Signals has the score of each compared field
+	}
+)
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RateLimitBucket is a token bucket limiting the requests of an API key or IP.
+type RateLimitBucket struct {
+	Key       string     `json:"key" bson:"_id"`                                 // Key identifies the client and route group
+	Tokens    float64    `json:"tokens" bson:"tokens"`                           // Tokens left after the last request
+	Allowed   bool       `json:"allowed" bson:"allowed"`                         // Allowed tells whether the last request took a token
+	UpdatedAt *time.Time `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"` // UpdatedAt is the time of the last request
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"` // ExpiresAt is when the bucket is full again and can be removed
+}
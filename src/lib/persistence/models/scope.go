@@ -0,0 +1,83 @@
+package models
+
+// Scopes granted to tokens. api_read and api_write grant access to every
+// resource, the others only to the routes requiring them.
+const (
+	ScopeAPIRead           = "api_read"
+	ScopeAPIWrite          = "api_write"
+	ScopeMoviesWrite       = "movies:write"
+	ScopeTheatersWrite     = "theaters:write"
+	ScopeScrapersRun       = "scrapers:run"
+	ScopeImagesUpload      = "images:upload"
+	ScopeNotificationsSend = "notifications:send"
+)
+
+// Roles bundling scopes.
+const (
+	// RoleAdmin can do anything. It's the default of admin keys.
+	RoleAdmin = "admin"
+
+	// RoleEditor can read everything, fix movie metadata and upload images.
+	RoleEditor = "editor"
+
+	// RoleClient can only read. It's the default of client keys.
+	RoleClient = "client"
+)
+
+// Scopes lists every scope.
+var Scopes = []string{
+	ScopeAPIRead,
+	ScopeAPIWrite,
+	ScopeMoviesWrite,
+	ScopeTheatersWrite,
+	ScopeScrapersRun,
+	ScopeImagesUpload,
+	ScopeNotificationsSend,
+}
+
+// Roles maps roles to the scopes they grant.
+var Roles = map[string][]string{
+	RoleAdmin:  Scopes,
+	RoleEditor: {ScopeAPIRead, ScopeMoviesWrite, ScopeImagesUpload},
+	RoleClient: {ScopeAPIRead},
+}
+
+// UserTypeScopes maps user types to the scopes their keys can be granted.
+// Client keys get tokens without credentials, so they can only read.
+var UserTypeScopes = map[string][]string{
+	UserTypeAdmin:  Scopes,
+	UserTypeClient: Roles[RoleClient],
+}
+
+// IsScope checks whether the given scope exists.
+func IsScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsScope tells whether keys of the given user type can be granted the
+// scope.
+func AllowsScope(userType, scope string) bool {
+	return containsString(UserTypeScopes[userType], scope)
+}
+
+// AllowsRole tells whether keys of the given user type can be granted every
+// scope of the role.
+func AllowsRole(userType, role string) bool {
+	for _, s := range Roles[role] {
+		if !AllowsScope(userType, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsRole checks whether the given role exists.
+func IsRole(role string) bool {
+	_, ok := Roles[role]
+	return ok
+}
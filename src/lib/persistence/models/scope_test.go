@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowsScope(t *testing.T) {
+	for _, s := range Scopes {
+		assert.True(t, AllowsScope(UserTypeAdmin, s), s)
+	}
+	assert.True(t, AllowsScope(UserTypeClient, ScopeAPIRead))
+	assert.False(t, AllowsScope(UserTypeClient, ScopeAPIWrite))
+	assert.False(t, AllowsScope(UserTypeClient, ScopeTheatersWrite))
+	assert.False(t, AllowsScope(UserTypeClient, ScopeScrapersRun))
+	assert.False(t, AllowsScope(UserTypeClient, ScopeImagesUpload))
+	assert.False(t, AllowsScope(UserTypeClient, ScopeNotificationsSend))
+	assert.False(t, AllowsScope("unknown", ScopeAPIRead))
+
+	assert.True(t, AllowsRole(UserTypeAdmin, RoleAdmin))
+	assert.True(t, AllowsRole(UserTypeAdmin, RoleEditor))
+	assert.True(t, AllowsRole(UserTypeClient, RoleClient))
+	assert.False(t, AllowsRole(UserTypeClient, RoleAdmin))
+	assert.False(t, AllowsRole(UserTypeClient, RoleEditor))
+}
+
+func TestGrantedScopes(t *testing.T) {
+	assert.ElementsMatch(t, Scopes, APIKey{UserType: UserTypeAdmin}.GrantedScopes())
+	assert.Equal(t, []string{ScopeAPIRead, ScopeMoviesWrite, ScopeImagesUpload}, APIKey{UserType: UserTypeAdmin, Role: RoleEditor}.GrantedScopes())
+
+	editor := APIKey{UserType: UserTypeAdmin, Role: RoleEditor, Scopes: []string{ScopeScrapersRun}}
+	assert.True(t, editor.HasScope(ScopeImagesUpload))
+	assert.True(t, editor.HasScope(ScopeScrapersRun))
+	assert.False(t, editor.HasScope(ScopeNotificationsSend))
+
+	// Client keys can't be granted more than their user type allows.
+	key := APIKey{UserType: UserTypeClient, Role: RoleAdmin, Scopes: []string{ScopeAPIWrite, "unknown"}}
+	assert.Equal(t, []string{ScopeAPIRead}, key.GrantedScopes())
+}
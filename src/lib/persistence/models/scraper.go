@@ -19,25 +19,82 @@ type (
 		LastRun    primitive.ObjectID `json:"last_run,omitempty" bson:"last_run,omitempty"` // LastRun contains informations about last run
 		LastRunDoc *ScraperRun        `json:"-" bson:"-"`                                   // LastRunDoc contains the last run document
 		Theater    *Theater           `json:"theater,omitempty" bson:"theater,omitempty"`   // Theater document
+		Rules      *ScraperRules      `json:"rules,omitempty" bson:"rules,omitempty"`       // Rules overrides the default sanity rules for this scraper's type
+		Retry      *RetryPolicy       `json:"retry,omitempty" bson:"retry,omitempty"`       // Retry overrides the default retry policy
+		Cron       []string           `json:"cron" bson:"cron"`                             // Cron specs of the scraper's own schedule, if empty it runs only through tasks
+		Enabled    *bool              `json:"enabled,omitempty" bson:"enabled,omitempty"`   // Enabled indicates whether scheduled runs are allowed, defaults to true
+		Windows    []RunWindow        `json:"windows" bson:"windows"`                       // Windows restricts scheduled runs to the given periods, if empty it runs any time
+	}
+
+	// RunWindow is a period of the day in which scheduled runs are allowed.
+	// Start and End use the HH:MM format in the scheduler's location. A window
+	// whose End is before Start ends in the next day.
+	RunWindow struct {
+		Weekdays []time.Weekday `json:"weekdays" bson:"weekdays"` // Weekdays in which the window applies, if empty it applies every day
+		Start    string         `json:"start" bson:"start"`       // Start of the window
+		End      string         `json:"end" bson:"end"`           // End of the window
+	}
+
+	// RetryPolicy defines how failed runs of a scraper are attempted again.
+	RetryPolicy struct {
+		MaxAttempts int      `json:"max_attempts" bson:"max_attempts"` // MaxAttempts is the maximum number of attempts, including the first one
+		Backoff     int      `json:"backoff" bson:"backoff"`           // Backoff is the delay in seconds before the first retry, doubled after each attempt
+		MaxBackoff  int      `json:"max_backoff" bson:"max_backoff"`   // MaxBackoff caps the delay in seconds between attempts (0 disables it)
+		RetryOn     []string `json:"retry_on" bson:"retry_on"`         // RetryOn lists the result codes that should be retried
+	}
+
+	// ScraperRules are sanity checks applied to the extracted data before it is persisted.
+	// A run that breaks any of these rules is quarantined instead of completed.
+	ScraperRules struct {
+		MaxDropPercent        float64  `json:"max_drop_percent" bson:"max_drop_percent"`                 // MaxDropPercent is the maximum allowed drop in extracted count relative to the last run (0 disables it)
+		MinCount              int      `json:"min_count" bson:"min_count"`                               // MinCount is the minimum number of extracted items (0 disables it)
+		RequiredFields        []string `json:"required_fields" bson:"required_fields"`                   // RequiredFields lists fields that must be filled in every extracted item
+		MaxOutsideWeekPercent float64  `json:"max_outside_week_percent" bson:"max_outside_week_percent"` // MaxOutsideWeekPercent is the maximum percentage of sessions outside the current week (0 disables it)
 	}
 
 	// ScraperRun is the result of any scraper operation.
 	ScraperRun struct {
-		ID             primitive.ObjectID `json:"_id" bson:"_id"`                         // ID is the document identifier
-		ScraperID      primitive.ObjectID `json:"scraper_id" bson:"scraper_id"`           // ScraperID indicates from which scraper this belongs
-		ResultCode     string             `json:"result_code" bson:"result_code"`         // ResultCode is a code in string format to make easier to know if run was successful or not
-		Error          string             `json:"error" bson:"error"`                     // Error is the possible error message or stack trace encounter in run
-		StartTime      *time.Time         `json:"start_time" bson:"start_time"`           // StartTime is the time the run started
-		CompleteTime   *time.Time         `json:"complete_time" bson:"complete_time"`     // CompleteTime is the time the run finished
-		ExtractedHash  string             `json:"extracted_hash" bson:"extracted_hash"`   // ExtractedHash is used to store the hash data so we can easily determine if it changed or not
-		ExtractedCount int                `json:"extracted_count" bson:"extracted_count"` // ExtractedCount indicates how many items were extracted
-		Scraper        *Scraper           `json:"-" bson:"-"`                             // Scraper scraper from which this run belongs
-		Movies         []Movie            `json:"-" bson:"-"`                             // Movies retrieved from a scraper's execution. (now_playing/upcoming)
-		Sessions       []Session          `json:"-" bson:"-"`                             // Sessions retrieved from a scraper's execution. (schedule)
-		Prices         []Price            `json:"-" bson:"-"`                             // Prices retrieved from a scraper's execution. (prices)
+		ID             primitive.ObjectID `json:"_id" bson:"_id"`                                   // ID is the document identifier
+		ScraperID      primitive.ObjectID `json:"scraper_id" bson:"scraper_id"`                     // ScraperID indicates from which scraper this belongs
+		ResultCode     string             `json:"result_code" bson:"result_code"`                   // ResultCode is a code in string format to make easier to know if run was successful or not
+		Error          string             `json:"error" bson:"error"`                               // Error is the possible error message or stack trace encounter in run
+		StartTime      *time.Time         `json:"start_time" bson:"start_time"`                     // StartTime is the time the run started
+		CompleteTime   *time.Time         `json:"complete_time" bson:"complete_time"`               // CompleteTime is the time the run finished
+		ExtractedHash  string             `json:"extracted_hash" bson:"extracted_hash"`             // ExtractedHash is used to store the hash data so we can easily determine if it changed or not
+		ExtractedCount int                `json:"extracted_count" bson:"extracted_count"`           // ExtractedCount indicates how many items were extracted
+		Attempt        int                `json:"attempt" bson:"attempt"`                           // Attempt is the attempt number of this run, starting at 1
+		RetryOf        primitive.ObjectID `json:"retry_of,omitempty" bson:"retry_of,omitempty"`     // RetryOf is the original run when this run is a retry
+		Scraper        *Scraper           `json:"-" bson:"-"`                                       // Scraper scraper from which this run belongs
+		Movies         []Movie            `json:"-" bson:"-"`                                       // Movies retrieved from a scraper's execution. (now_playing/upcoming)
+		Sessions       []Session          `json:"-" bson:"-"`                                       // Sessions retrieved from a scraper's execution. (schedule)
+		Prices         []Price            `json:"-" bson:"-"`                                       // Prices retrieved from a scraper's execution. (prices)
+		Quarantine     *RunQuarantine     `json:"quarantine,omitempty" bson:"quarantine,omitempty"` // Quarantine holds the extracted data of a run that broke a sanity rule
+		Unmatched      []UnmatchedMovie   `json:"unmatched,omitempty" bson:"unmatched,omitempty"`   // Unmatched lists scraped movies whose sessions have no movie. (schedule)
+	}
+
+	// UnmatchedMovie is a scraped movie that couldn't be found in the database.
+	UnmatchedMovie struct {
+		Title    string `json:"title" bson:"title"`       // Title as shown by the theater
+		Slug     string `json:"slug" bson:"slug"`         // Slug is the title slug without dashes
+		Sessions int    `json:"sessions" bson:"sessions"` // Sessions is the number of sessions without movie
+	}
+
+	// RunQuarantine keeps the data of a suspicious run until an admin approves or discards it.
+	RunQuarantine struct {
+		Status     string     `json:"status" bson:"status"`                               // Status is one of pending, approved or discarded
+		Reasons    []string   `json:"reasons" bson:"reasons"`                             // Reasons lists every rule the run broke
+		ReviewedAt *time.Time `json:"reviewed_at,omitempty" bson:"reviewed_at,omitempty"` // ReviewedAt is the time an admin approved or discarded the run
+		Movies     []Movie    `json:"movies,omitempty" bson:"movies,omitempty"`           // Movies extracted by the run. (now_playing/upcoming)
+		Sessions   []Session  `json:"sessions,omitempty" bson:"sessions,omitempty"`       // Sessions extracted by the run. (schedule)
+		Prices     []Price    `json:"prices,omitempty" bson:"prices,omitempty"`           // Prices extracted by the run. (prices)
 	}
 )
 
+// IsEnabled checks whether scheduled runs of the scraper are allowed.
+func (s *Scraper) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
 // Finish just adds the complete time.
 func (r *ScraperRun) Finish() {
 	end := time.Now().UTC()
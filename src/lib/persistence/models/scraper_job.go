@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// ScraperJobPending indicates the job is waiting for a worker.
+	ScraperJobPending = "pending"
+	// ScraperJobRunning indicates a worker is running the job.
+	ScraperJobRunning = "running"
+)
+
+// ScraperJob is a scraper run waiting in the work queue.
+//
+// There's at most one pending job per scraper, so queueing the same
+// scraper again only raises the priority of the existing one.
+type ScraperJob struct {
+	ID            primitive.ObjectID `json:"_id" bson:"_id"`                                   // ID is the document identifier
+	ScraperID     primitive.ObjectID `json:"scraper_id" bson:"scraper_id"`                     // ScraperID is the scraper to run
+	Host          string             `json:"host" bson:"host"`                                 // Host is the provider host used to limit concurrency
+	Status        string             `json:"status" bson:"status"`                             // Status is either pending or running
+	Priority      int                `json:"priority" bson:"priority"`                         // Priority of the job, higher runs first
+	IgnoreLastRun bool               `json:"ignore_last_run" bson:"ignore_last_run"`           // IgnoreLastRun forces the data to be persisted even if it's not modified
+	Attempt       int                `json:"attempt" bson:"attempt"`                           // Attempt is the attempt number of a retry
+	RetryOf       string             `json:"retry_of,omitempty" bson:"retry_of,omitempty"`     // RetryOf is the ID of the run being retried
+	NotBefore     *time.Time         `json:"not_before,omitempty" bson:"not_before,omitempty"` // NotBefore delays the job until the given time
+	CreatedAt     *time.Time         `json:"created_at" bson:"created_at"`                     // CreatedAt is the time the job was queued
+	StartedAt     *time.Time         `json:"started_at,omitempty" bson:"started_at,omitempty"` // StartedAt is the time a worker claimed the job
+}
@@ -1,8 +1,11 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -14,23 +17,179 @@ const (
 	VersionSubtitled = "subtitled"
 	VersionDubbed    = "dubbed"
 	VersionNational  = "national"
+
+	// SessionTypeRegular is an ordinary session.
+	SessionTypeRegular = "regular"
+	// SessionTypePreview is a session before the movie release (PRÉ-ESTREIA).
+	SessionTypePreview = "preview"
+	// SessionTypePremiere is a session in the release week of the movie (ESTREIA).
+	SessionTypePremiere = "premiere"
+	// SessionTypeSpecialEvent is a one-off screening, eg: a marathon or an opera.
+	SessionTypeSpecialEvent = "special_event"
+
+	// PremiereDays is the number of days since the release a session is a premiere.
+	PremiereDays = 7
 )
 
+// specialEventKeywords are found in titles of special events.
+var specialEventKeywords = []string{
+	"sessão especial",
+	"sessao especial",
+	"evento especial",
+	"maratona",
+	"transmissão ao vivo",
+	"transmissao ao vivo",
+}
+
 // Session ...
 type Session struct {
 	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Key         string             `json:"key,omitempty" bson:"key,omitempty"`
 	MovieID     primitive.ObjectID `json:"movieId,omitempty" bson:"movieId,omitempty"`
 	TheaterID   primitive.ObjectID `json:"theaterId,omitempty" bson:"theaterId,omitempty"`
 	MovieSlugs  Slugs              `json:"movieSlugs,omitempty" bson:"movieSlugs,omitempty"`
+	ScrapedSlug string             `json:"scrapedSlug,omitempty" bson:"scrapedSlug,omitempty"` // ScrapedSlug is the movie slug reported by the provider, kept when the movie is matched
 	Hidden      bool               `json:"hidden" bson:"hidden"`
 	Format      string             `json:"format" bson:"format"`
 	Version     string             `json:"version" bson:"version"`
 	Room        uint               `json:"room" bson:"room"`
+	Type        string             `json:"type,omitempty" bson:"type,omitempty"`             // Type is regular, preview, premiere or special_event
+	Attributes  []string           `json:"attributes,omitempty" bson:"attributes,omitempty"` // Attributes from the catalog, see GetAttributeList
 	TimeZone    string             `json:"timeZone,omitempty" bson:"timeZone,omitempty"`
 	OpeningTime string             `json:"openingTime,omitempty" bson:"openingTime,omitempty"`
 	Date        int                `json:"date,omitempty" bson:"date,omitempty"`
 	StartTime   *time.Time         `json:"startTime" bson:"startTime"`
+	Providers   []string           `json:"providers,omitempty" bson:"providers,omitempty"`
 	CreatedAt   *time.Time         `json:"createdAt,omitempty" bson:"createdAt"`
+	UpdatedAt   *time.Time         `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
 	Theater     *Theater           `json:"theater,omitempty" bson:"theater,omitempty"`
 	Movie       *Movie             `json:"movie,omitempty" bson:"movie,omitempty"`
 }
+
+// NaturalKey identifies the session regardless of the provider that
+// reported it: theater, room, start time, version, format and movie. Sessions
+// without movie use the scraped movie slug until they get one.
+func (s *Session) NaturalKey() string {
+	movie := s.MovieID.Hex()
+	if s.MovieID.IsZero() {
+		movie = s.ScrapedSlug
+		if movie == "" {
+			movie = s.MovieSlugs.NoDashes
+		}
+	}
+	start := ""
+	if s.StartTime != nil {
+		start = s.StartTime.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%s:%d:%s:%s:%s:%s", s.TheaterID.Hex(), s.Room, start, NormalizeVersion(s.Version), s.Format, movie)
+}
+
+// NormalizeVersion returns the version of the catalog for versions providers
+// name differently, eg: subbed is subtitled.
+func NormalizeVersion(version string) string {
+	if version == VersionSubbed {
+		return VersionSubtitled
+	}
+	return version
+}
+
+// VersionNames returns every name sessions may have stored for the version.
+func VersionNames(version string) []string {
+	version = NormalizeVersion(version)
+	if version == VersionSubtitled {
+		return []string{VersionSubtitled, VersionSubbed}
+	}
+	return []string{version}
+}
+
+// Location returns the time zone of the session. Sessions without one fall
+// back to the time zone of their theater city, when included, or the default
+// time zone.
+func (s *Session) Location() *time.Location {
+	if s.TimeZone == "" && s.Theater != nil && s.Theater.City != nil {
+		return s.Theater.City.Location()
+	}
+	return timeutil.LoadLocation(s.TimeZone)
+}
+
+// GetAttributes returns the attributes of the session. Sessions scraped
+// before attributes existed get them from their format and version.
+func (s *Session) GetAttributes() []string {
+	if len(s.Attributes) > 0 {
+		return s.Attributes
+	}
+	result := make([]string, 0, 2)
+	switch s.Format {
+	case Format2D, Format3D:
+		result = append(result, s.Format)
+	}
+	switch s.Version {
+	case VersionDubbed, VersionNational, VersionSubtitled:
+		result = append(result, s.Version)
+	case VersionSubbed:
+		result = append(result, VersionSubtitled)
+	}
+	return result
+}
+
+// ClassifySession finds the type of the session. The movie may be the scraped
+// one when the session has no movie yet. Days are compared in the session
+// time zone.
+func ClassifySession(s *Session, movie *Movie) string {
+	if movie == nil || s.StartTime == nil {
+		return SessionTypeRegular
+	}
+
+	title := strings.ToLower(movie.Title)
+	for _, k := range specialEventKeywords {
+		if strings.Contains(title, k) {
+			return SessionTypeSpecialEvent
+		}
+	}
+
+	if movie.ReleaseDate == nil || movie.ReleaseDate.IsZero() {
+		return SessionTypeRegular
+	}
+
+	day := timeutil.DateIn(*s.StartTime, s.Location())
+	// Release dates are stored at midnight UTC, so only the date matters.
+	release := timeutil.DateIn(*movie.ReleaseDate, time.UTC)
+	premiereEnd := timeutil.DateIn(movie.ReleaseDate.AddDate(0, 0, PremiereDays), time.UTC)
+
+	switch {
+	case day < release:
+		return SessionTypePreview
+	case day < premiereEnd:
+		return SessionTypePremiere
+	}
+	return SessionTypeRegular
+}
+
+// GetType returns the type of the session, classifying sessions scraped
+// before types existed.
+func (s *Session) GetType() string {
+	if s.Type != "" {
+		return s.Type
+	}
+	return ClassifySession(s, s.Movie)
+}
+
+// GetSessionsType summarizes the types of a movie sessions: preview if any
+// session is a preview, otherwise premiere if any is a premiere.
+func GetSessionsType(sessions []Session, movie *Movie) string {
+	result := SessionTypeRegular
+	for i := range sessions {
+		s := &sessions[i]
+		t := s.Type
+		if t == "" {
+			t = ClassifySession(s, movie)
+		}
+		switch t {
+		case SessionTypePreview:
+			return SessionTypePreview
+		case SessionTypePremiere:
+			result = SessionTypePremiere
+		}
+	}
+	return result
+}
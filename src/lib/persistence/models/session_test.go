@@ -0,0 +1,146 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNaturalKey(t *testing.T) {
+	start := time.Date(2019, time.November, 1, 21, 0, 0, 0, time.UTC)
+	scraped := Session{
+		TheaterID:   primitive.NewObjectID(),
+		Room:        2,
+		StartTime:   &start,
+		MovieSlugs:  Slugs{NoDashes: "coringa"},
+		ScrapedSlug: "coringa",
+		Version:     VersionSubtitled,
+		Format:      Format2D,
+	}
+	key := scraped.NaturalKey()
+
+	// Sessions without scraped slug use their movie slug.
+	legacy := scraped
+	legacy.ScrapedSlug = ""
+	assert.Equal(t, key, legacy.NaturalKey())
+
+	// Matched sessions are keyed by their movie, whatever the slug.
+	matched := scraped
+	matched.MovieID = primitive.NewObjectID()
+	matched.MovieSlugs = Slugs{NoDashes: "coringa2019", Year: "coringa-2019"}
+	assert.NotEqual(t, key, matched.NaturalKey())
+	other := matched
+	other.ScrapedSlug = "joker"
+	assert.Equal(t, matched.NaturalKey(), other.NaturalKey())
+
+	// Providers naming the version differently report the same session.
+	subbed := scraped
+	subbed.Version = VersionSubbed
+	assert.Equal(t, key, subbed.NaturalKey())
+
+	dubbed := scraped
+	dubbed.Version = VersionDubbed
+	assert.NotEqual(t, key, dubbed.NaturalKey())
+
+	format := scraped
+	format.Format = Format3D
+	assert.NotEqual(t, key, format.NaturalKey())
+
+	room := scraped
+	room.Room = 3
+	assert.NotEqual(t, key, room.NaturalKey())
+
+	later := scraped
+	next := start.Add(time.Hour)
+	later.StartTime = &next
+	assert.NotEqual(t, key, later.NaturalKey())
+
+	// The same instant in another zone is the same session.
+	local := scraped
+	inZone := start.In(time.FixedZone("BRT", -3*60*60))
+	local.StartTime = &inZone
+	assert.Equal(t, key, local.NaturalKey())
+}
+
+func TestVersionNames(t *testing.T) {
+	assert.Equal(t, []string{VersionSubtitled, VersionSubbed}, VersionNames(VersionSubbed))
+	assert.Equal(t, []string{VersionSubtitled, VersionSubbed}, VersionNames(VersionSubtitled))
+	assert.Equal(t, []string{VersionDubbed}, VersionNames(VersionDubbed))
+}
+
+func TestClassifySession(t *testing.T) {
+	release := time.Date(2019, time.November, 1, 0, 0, 0, 0, time.UTC)
+	movie := &Movie{Title: "Coringa", ReleaseDate: &release}
+	at := func(t time.Time) *Session {
+		return &Session{StartTime: &t, TimeZone: "America/Sao_Paulo"}
+	}
+	brt := time.FixedZone("BRT", -3*60*60)
+
+	cases := []struct {
+		name     string
+		session  *Session
+		movie    *Movie
+		expected string
+	}{
+		{"without movie", at(release), nil, SessionTypeRegular},
+		{"without start time", &Session{}, movie, SessionTypeRegular},
+		{"without release date", at(release), &Movie{Title: "Coringa"}, SessionTypeRegular},
+		{"day before release", at(time.Date(2019, time.October, 31, 21, 0, 0, 0, brt)), movie, SessionTypePreview},
+		{"release day", at(time.Date(2019, time.November, 1, 14, 0, 0, 0, brt)), movie, SessionTypePremiere},
+		{"last premiere day", at(time.Date(2019, time.November, 7, 21, 0, 0, 0, brt)), movie, SessionTypePremiere},
+		{"after premiere days", at(time.Date(2019, time.November, 8, 14, 0, 0, 0, brt)), movie, SessionTypeRegular},
+		// The day is the one in the session time zone, not in UTC.
+		{"late night before release", at(time.Date(2019, time.October, 31, 23, 30, 0, 0, brt)), movie, SessionTypePreview},
+		{"late night of last premiere day", at(time.Date(2019, time.November, 7, 23, 30, 0, 0, brt)), movie, SessionTypePremiere},
+		{"special event", at(time.Date(2019, time.October, 20, 21, 0, 0, 0, brt)),
+			&Movie{Title: "Maratona Vingadores", ReleaseDate: &release}, SessionTypeSpecialEvent},
+		{"special event without release date", at(release),
+			&Movie{Title: "Ópera: Sessão Especial"}, SessionTypeSpecialEvent},
+	}
+	for _, test := range cases {
+		assert.Equal(t, test.expected, ClassifySession(test.session, test.movie), test.name)
+	}
+}
+
+func TestGetType(t *testing.T) {
+	release := time.Date(2019, time.November, 1, 0, 0, 0, 0, time.UTC)
+	before := release.Add(-48 * time.Hour)
+
+	// Scraped sessions keep their type.
+	s := Session{Type: SessionTypeSpecialEvent, StartTime: &before, Movie: &Movie{Title: "Coringa", ReleaseDate: &release}}
+	assert.Equal(t, SessionTypeSpecialEvent, s.GetType())
+
+	// Older sessions are classified by their movie.
+	s.Type = ""
+	assert.Equal(t, SessionTypePreview, s.GetType())
+
+	s.Movie = nil
+	assert.Equal(t, SessionTypeRegular, s.GetType())
+}
+
+func TestGetSessionsType(t *testing.T) {
+	release := time.Date(2019, time.November, 1, 0, 0, 0, 0, time.UTC)
+	movie := &Movie{Title: "Coringa", ReleaseDate: &release}
+	session := func(days int, kind string) Session {
+		start := release.AddDate(0, 0, days).Add(21 * time.Hour)
+		return Session{StartTime: &start, Type: kind}
+	}
+
+	cases := []struct {
+		name     string
+		sessions []Session
+		expected string
+	}{
+		{"without sessions", nil, SessionTypeRegular},
+		{"regular only", []Session{session(10, ""), session(11, "")}, SessionTypeRegular},
+		{"any premiere", []Session{session(10, ""), session(PremiereDays-1, "")}, SessionTypePremiere},
+		{"any preview", []Session{session(0, ""), session(-1, ""), session(10, "")}, SessionTypePreview},
+		{"stored types first", []Session{session(-1, SessionTypeRegular), session(10, SessionTypePremiere)}, SessionTypePremiere},
+		{"special events only", []Session{session(10, SessionTypeSpecialEvent)}, SessionTypeRegular},
+	}
+	for _, test := range cases {
+		assert.Equal(t, test.expected, GetSessionsType(test.sessions, movie), test.name)
+	}
+}
@@ -20,7 +20,8 @@ type Theater struct {
 	AddressLine1 string             `json:"addressLine1,omitempty" bson:"addressLine1"`
 	AddressLine2 string             `json:"addressLine2,omitempty" bson:"addressLine2"`
 	Phones       []string           `json:"phones,omitempty" bson:"phones"`
-	Location     []string           `json:"location,omitempty" bson:"location"` // LatLng is a 2 size string array
+	Location     []string           `json:"location,omitempty" bson:"location"`     // LatLng is a 2 size string array
+	Rooms        []TheaterRoom      `json:"rooms,omitempty" bson:"rooms,omitempty"` // Rooms with attributes providers don't report
 	CreatedAt    *time.Time         `json:"createdAt,omitempty" bson:"createdAt"`
 	UpdatedAt    *time.Time         `json:"updatedAt,omitempty" bson:"updatedAt"`
 	City         *City              `json:"city,omitempty" bson:"city,omitempty"`
@@ -28,6 +29,22 @@ type Theater struct {
 	Sessions     []Session          `json:"sessions,omitempty" bson:"sessions,omitempty"`
 }
 
+// TheaterRoom has the attributes of a room of the theater, eg: a VIP room.
+type TheaterRoom struct {
+	Number     uint     `json:"number" bson:"number"`
+	Attributes []string `json:"attributes" bson:"attributes"`
+}
+
+// GetRoomAttributes returns the attributes of the room with the given number.
+func (t *Theater) GetRoomAttributes(number uint) []string {
+	for _, r := range t.Rooms {
+		if r.Number == number {
+			return r.Attributes
+		}
+	}
+	return nil
+}
+
 // TheaterImages ...
 type TheaterImages struct {
 	BackdropURL string `json:"backdrop,omitempty" bson:"backdrop"`
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// SigningKey is a key pair used to sign JWT tokens. Tokens signed with it are
+// valid until it expires.
+type SigningKey struct {
+	ID         string     `json:"kid" bson:"_id"`                                 // Key ID, set in the kid header of tokens
+	Algorithm  string     `json:"alg" bson:"algorithm"`                           // Either RS256 or EdDSA
+	PrivateKey string     `json:"-" bson:"privateKey"`                            // PEM encoded PKCS #8 private key, encrypted with the key secret
+	PublicKey  string     `json:"publicKey" bson:"publicKey"`                     // PEM encoded PKIX public key
+	Replaces   string     `json:"replaces,omitempty" bson:"replaces"`             // Key replaced by this one, unique so instances rotate keys once
+	CreatedAt  *time.Time `json:"createdAt,omitempty" bson:"createdAt,omitempty"` // New tokens are signed with the newest key
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"` // Tokens signed with the key are rejected after it
+}
+
+// RefreshToken is a long lived token exchanged for new access tokens. Each
+// token can be used once, and is replaced by a new one of the same family.
+type RefreshToken struct {
+	ID         string     `json:"-" bson:"_id"`                                 // SHA-256 hash of the token
+	Family     string     `json:"family" bson:"family"`                         // Tokens rotated from the same login share the family
+	APIKeyID   string     `json:"apiKeyId" bson:"apiKeyId"`                     // API key the tokens are issued for
+	Scopes     []string   `json:"scopes" bson:"scopes"`                         // Scopes of the access tokens
+	Platform   string     `json:"platform,omitempty" bson:"platform,omitempty"` // Platform of the client
+	CreatedAt  *time.Time `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+	ReplacedAt *time.Time `json:"replacedAt,omitempty" bson:"replacedAt,omitempty"` // When the token was exchanged
+	RevokedAt  *time.Time `json:"revokedAt,omitempty" bson:"revokedAt,omitempty"`   // When the family was revoked
+}
+
+// RevokedToken is an access token revoked before it expires.
+type RevokedToken struct {
+	ID        string     `json:"jti" bson:"_id"`                                 // ID of the token
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"` // When the token expires and can be removed
+}
@@ -2,11 +2,13 @@ package mongolayer
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // InsertAPIKey ...
@@ -14,6 +16,11 @@ func (m *MongoDAL) InsertAPIKey(apikey models.APIKey) error {
 	if apikey.Timestamp == nil {
 		apikey.Timestamp = getCurrentTime()
 	}
+	if apikey.Key != "" {
+		if err := apikey.SetKey(apikey.Key); err != nil {
+			return err
+		}
+	}
 	_, err := m.C(CollectionAPIKeys).InsertOne(context.Background(), apikey)
 	return err
 }
@@ -28,6 +35,34 @@ func (m *MongoDAL) FindAPIKey(query persistence.Query) (*models.APIKey, error) {
 	return &result, err
 }
 
+// FindAPIKeyByKey finds the APIKey matching the given key. Keys are found by
+// prefix and then compared by hash.
+func (m *MongoDAL) FindAPIKeyByKey(key string) (*models.APIKey, error) {
+	if key == "" {
+		return nil, mongo.ErrNoDocuments
+	}
+	conditions := bson.M{"$or": bson.A{
+		bson.M{"prefix": models.APIKeyPrefix(key)},
+		bson.M{"key": key},
+	}}
+	var candidates []models.APIKey
+	ctx := context.Background()
+	cursor, err := m.C(CollectionAPIKeys).Find(ctx, conditions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return nil, err
+	}
+	for _, c := range candidates {
+		if c.Matches(key) {
+			return &c, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
 // GetAPIKey ...
 func (m *MongoDAL) GetAPIKey(id string, query persistence.Query) (*models.APIKey, error) {
 	ID, err := primitive.ObjectIDFromHex(id)
@@ -50,6 +85,34 @@ func (m *MongoDAL) GetAPIKeys(query persistence.Query) ([]models.APIKey, error)
 	return result, err
 }
 
+// UpdateAPIKey ...
+func (m *MongoDAL) UpdateAPIKey(id string, apikey models.APIKey) (int64, error) {
+	ID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, err
+	}
+	// Keys created before hashing are hashed on their first update.
+	if apikey.Key != "" {
+		if err := apikey.SetKey(apikey.Key); err != nil {
+			return 0, err
+		}
+	}
+	apikey.ID = ID
+	unset := bson.M{"key": ""}
+	if apikey.Role == "" {
+		unset["role"] = ""
+	}
+	if len(apikey.Scopes) == 0 {
+		unset["scopes"] = ""
+	}
+	update := bson.M{"$set": apikey, "$unset": unset}
+	result, err := m.C(CollectionAPIKeys).UpdateOne(context.Background(), bson.M{"_id": ID}, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, err
+}
+
 // DeleteAPIKey ...
 func (m *MongoDAL) DeleteAPIKey(id string) error {
 	ID, err := primitive.ObjectIDFromHex(id)
@@ -59,3 +122,18 @@ func (m *MongoDAL) DeleteAPIKey(id string) error {
 	_, err = m.C(CollectionAPIKeys).DeleteOne(context.Background(), bson.M{"_id": ID})
 	return err
 }
+
+// hashAPIKeys replaces the plain text keys created before hashing with their
+// hashes.
+func (m *MongoDAL) hashAPIKeys() {
+	apikeys, err := m.GetAPIKeys(m.DefaultQuery().AddCondition("key", bson.M{"$exists": true}).SetLimit(-1))
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		return
+	}
+	for _, apikey := range apikeys {
+		if _, err := m.UpdateAPIKey(apikey.ID.Hex(), apikey); err != nil {
+			fmt.Printf("Error: %s\n", err.Error())
+		}
+	}
+}
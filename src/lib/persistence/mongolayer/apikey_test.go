@@ -2,6 +2,7 @@ package mongolayer
 
 import (
 	"testing"
+	"time"
 
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/stretchr/testify/assert"
@@ -26,9 +27,18 @@ func TestAPIKey(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Find one with conditions
-	apikey, err := data.FindAPIKey(DefaultOptions("").AddCondition("key", doc.Key))
+	apikey, err := data.FindAPIKey(DefaultOptions("").AddCondition("prefix", models.APIKeyPrefix(doc.Key)))
 	assert.NoError(t, err)
 	assert.NotEmpty(t, apikey)
+	assert.Empty(t, apikey.Key)
+	assert.NotEmpty(t, apikey.Hash)
+
+	// Find one by key
+	apikey, err = data.FindAPIKeyByKey(doc.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, doc.ID, apikey.ID)
+	_, err = data.FindAPIKeyByKey(doc.Key + "-wrong")
+	assert.Error(t, err)
 
 	// Find one by id
 	apikey, err = data.GetAPIKey(doc.ID.Hex(), DefaultOptions(""))
@@ -40,6 +50,33 @@ func TestAPIKey(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, apikeys)
 
+	// Update
+	now := time.Now()
+	apikey.RevokedAt = &now
+	n, err := data.UpdateAPIKey(doc.ID.Hex(), *apikey)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	apikey, err = data.FindAPIKeyByKey(doc.Key)
+	assert.NoError(t, err)
+	assert.False(t, apikey.IsActive(now))
+
+	// Roles and scopes are removed when emptied
+	apikey.Role = models.RoleEditor
+	apikey.Scopes = []string{models.ScopeTheatersWrite}
+	_, err = data.UpdateAPIKey(doc.ID.Hex(), *apikey)
+	assert.NoError(t, err)
+	apikey, err = data.GetAPIKey(doc.ID.Hex(), DefaultOptions(""))
+	assert.NoError(t, err)
+	assert.Contains(t, apikey.GrantedScopes(), models.ScopeMoviesWrite)
+	assert.Contains(t, apikey.GrantedScopes(), models.ScopeTheatersWrite)
+	apikey.Role = ""
+	apikey.Scopes = nil
+	_, err = data.UpdateAPIKey(doc.ID.Hex(), *apikey)
+	assert.NoError(t, err)
+	apikey, err = data.GetAPIKey(doc.ID.Hex(), DefaultOptions(""))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.ScopeAPIRead}, apikey.GrantedScopes())
+
 	// Delete
 	err = data.DeleteAPIKey(doc.ID.Hex())
 	assert.NoError(t, err)
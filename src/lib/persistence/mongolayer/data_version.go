@@ -0,0 +1,44 @@
+package mongolayer
+
+import (
+	"context"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// dataVersionID is the ID of the data version document in the meta collection.
+const dataVersionID = "data_version"
+
+// GetDataVersion ...
+func (m *MongoDAL) GetDataVersion() (*models.DataVersion, error) {
+	var result models.DataVersion
+	err := m.C(CollectionMeta).FindOne(context.Background(), bson.M{"_id": dataVersionID}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		// Nothing was scraped yet.
+		return &models.DataVersion{ID: dataVersionID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BumpDataVersion ...
+func (m *MongoDAL) BumpDataVersion() (*models.DataVersion, error) {
+	var result models.DataVersion
+	update := bson.M{
+		"$inc": bson.M{"version": 1},
+		"$set": bson.M{"updatedAt": getCurrentTime()},
+	}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+	err := m.C(CollectionMeta).FindOneAndUpdate(context.Background(), bson.M{"_id": dataVersionID}, update, opts).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
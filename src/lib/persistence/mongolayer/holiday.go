@@ -0,0 +1,79 @@
+package mongolayer
+
+import (
+	"context"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InsertHoliday ...
+func (m *MongoDAL) InsertHoliday(holiday models.Holiday) error {
+	if holiday.ID.IsZero() {
+		holiday.ID = primitive.NewObjectID()
+	}
+	if holiday.CreatedAt == nil {
+		holiday.CreatedAt = getCurrentTime()
+	}
+	_, err := m.C(CollectionHolidays).InsertOne(context.Background(), holiday)
+	return err
+}
+
+// FindHoliday ...
+func (m *MongoDAL) FindHoliday(query persistence.Query) (*models.Holiday, error) {
+	var result models.Holiday
+	err := m.C(CollectionHolidays).FindOne(context.Background(), query.GetConditions(), getFindOneOptions(query)).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, err
+}
+
+// GetHoliday ...
+func (m *MongoDAL) GetHoliday(id string, query persistence.Query) (*models.Holiday, error) {
+	ID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return m.FindHoliday(query.AddCondition("_id", ID))
+}
+
+// GetHolidays ...
+func (m *MongoDAL) GetHolidays(query persistence.Query) ([]models.Holiday, error) {
+	var result = []models.Holiday{}
+	var ctx = context.Background()
+	cursor, err := m.C(CollectionHolidays).Find(ctx, query.GetConditions(), getFindOptions(query))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	cursor.All(ctx, &result)
+	return result, err
+}
+
+// UpdateHoliday ...
+func (m *MongoDAL) UpdateHoliday(id string, holiday models.Holiday) (int64, error) {
+	ID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, err
+	}
+	holiday.ID = ID
+	holiday.UpdatedAt = getCurrentTime()
+	result, err := m.C(CollectionHolidays).UpdateOne(context.Background(), bson.M{"_id": ID}, bson.M{"$set": holiday})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, err
+}
+
+// DeleteHoliday ...
+func (m *MongoDAL) DeleteHoliday(id string) error {
+	ID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = m.C(CollectionHolidays).DeleteOne(context.Background(), bson.M{"_id": ID})
+	return err
+}
@@ -17,19 +17,28 @@ import (
 )
 
 const (
-	CollectionAdmins        = "admins"
-	CollectionAPIKeys       = "api_keys"
-	CollectionCities        = "cities"
-	CollectionImages        = "images"
-	CollectionMovies        = "movies"
-	CollectionNotifications = "notifications"
-	CollectionPrices        = "prices"
-	CollectionScores        = "scores"
-	CollectionScrapers      = "scrapers"
-	CollectionScraperRuns   = "scraper_runs"
-	CollectionSessions      = "sessions"
-	CollectionTasks         = "tasks"
-	CollectionTheaters      = "theaters"
+	CollectionAdmins         = "admins"
+	CollectionAPIKeys        = "api_keys"
+	CollectionCities         = "cities"
+	CollectionHolidays       = "holidays"
+	CollectionImages         = "images"
+	CollectionMeta           = "meta"
+	CollectionMovies         = "movies"
+	CollectionMovieAliases   = "movie_aliases"
+	CollectionNotifications  = "notifications"
+	CollectionPendingMatches = "pending_matches"
+	CollectionPrices         = "prices"
+	CollectionRateLimits     = "rate_limits"
+	CollectionRefreshTokens  = "refresh_tokens"
+	CollectionRevokedTokens  = "revoked_tokens"
+	CollectionScores         = "scores"
+	CollectionScrapers       = "scrapers"
+	CollectionScraperRuns    = "scraper_runs"
+	CollectionScraperJobs    = "scraper_jobs"
+	CollectionSessions       = "sessions"
+	CollectionSigningKeys    = "signing_keys"
+	CollectionTasks          = "tasks"
+	CollectionTheaters       = "theaters"
 )
 
 type (
@@ -214,9 +223,20 @@ func getFindOneOptions(query persistence.Query) *options.FindOneOptions {
 	return &options.FindOneOptions{Projection: query.GetFields()}
 }
 
+// textScoreSort prefixes sort fields holding the text search score.
+const textScoreSort = "$textScore:"
+
 func getFindOptions(query persistence.Query) *options.FindOptions {
-	sort := bson.M{}
+	sort := bson.D{}
 	for _, v := range query.GetSort() {
+		// Text search results are sorted by the score projected in the
+		// given field, eg: $textScore:score.
+		if strings.HasPrefix(v, textScoreSort) {
+			field := strings.TrimPrefix(v, textScoreSort)
+			sort = append(sort, bson.E{Key: field, Value: bson.M{"$meta": "textScore"}})
+			continue
+		}
+
 		value := 1
 		if v[0] == '-' {
 			value = -1
@@ -224,7 +244,7 @@ func getFindOptions(query persistence.Query) *options.FindOptions {
 		} else if v[0] == '+' {
 			v = v[1:]
 		}
-		sort[v] = value
+		sort = append(sort, bson.E{Key: v, Value: value})
 	}
 	opts := options.FindOptions{
 		Projection: query.GetFields(),
@@ -257,8 +277,10 @@ func (m *MongoDAL) Setup() {
 	EnsureUniqueIndex(apiKeysCollection, "key")
 	EnsureIndexes(apiKeysCollection, []string{
 		"owner",
+		"prefix",
 		"user_type",
 	})
+	m.hashAPIKeys()
 
 	// Movies
 	moviesCollection := m.C(CollectionMovies)
@@ -288,6 +310,14 @@ func (m *MongoDAL) Setup() {
 		"timeZone",
 	})
 
+	// Holiday overrides are looked up by date and location.
+	holidaysCollection := m.C(CollectionHolidays)
+	EnsureIndexes(holidaysCollection, []string{
+		"date",
+		"state",
+		"cityId",
+	})
+
 	// Theaters
 	theatersCollection := m.C(CollectionTheaters)
 	EnsureTextIndexes(theatersCollection, []string{
@@ -310,10 +340,44 @@ func (m *MongoDAL) Setup() {
 
 	// scraperRunsCollection := m.C(CollectionScraperRuns)
 
+	// Scraper jobs are deduplicated by scraper for each status.
+	scraperJobsCollection := m.C(CollectionScraperJobs)
+	ensureIndex(scraperJobsCollection, []string{"scraper_id", "status"}, true, true, false)
+	EnsureIndexes(scraperJobsCollection, []string{
+		"priority",
+		"host",
+		"not_before",
+	})
+
+	movieAliasesCollection := m.C(CollectionMovieAliases)
+	EnsureUniqueIndex(movieAliasesCollection, "alias")
+	EnsureIndex(movieAliasesCollection, "movie_id")
+
+	// Pending matches are looked up by scraped movie and status.
+	pendingMatchesCollection := m.C(CollectionPendingMatches)
+	EnsureIndexes(pendingMatchesCollection, []string{
+		"key",
+		"status",
+	})
+
+	// Rate limit buckets are removed once they are full again.
+	EnsureTTLIndex(m.C(CollectionRateLimits), "expiresAt")
+
+	// Tokens and signing keys are removed once they expire.
+	refreshTokensCollection := m.C(CollectionRefreshTokens)
+	EnsureIndex(refreshTokensCollection, "family")
+	EnsureTTLIndex(refreshTokensCollection, "expiresAt")
+	EnsureTTLIndex(m.C(CollectionRevokedTokens), "expiresAt")
+	signingKeysCollection := m.C(CollectionSigningKeys)
+	EnsureTTLIndex(signingKeysCollection, "expiresAt")
+	EnsureUniqueIndex(signingKeysCollection, "replaces")
+
 	scoresCollection := m.C(CollectionScores)
 	EnsureIndex(scoresCollection, "movieId")
 
+	// Sessions are unique by their natural key, see models.Session.NaturalKey.
 	sessionsCollection := m.C(CollectionSessions)
+	EnsureUniqueIndex(sessionsCollection, "key")
 	EnsureIndexes(sessionsCollection, []string{
 		"movieSlugs.noDashes",
 		"movieSlugs.year",
@@ -326,10 +390,13 @@ func (m *MongoDAL) Setup() {
 		"room",
 		"version",
 		"format",
+		"providers",
 	})
+	m.migrateSessionAttributes()
 
 	pricesCollection := m.C(CollectionPrices)
 	EnsureIndex(pricesCollection, "theaterId")
+	m.migratePriceAttributes()
 
 	// EnsureUniqueIndex(notificationsCollection, "nowPlaying")
 }
@@ -349,6 +416,18 @@ func EnsureTextIndexes(c *mongo.Collection, keys []string) {
 	}
 }
 
+// EnsureTTLIndex ensures that documents of a given collection are removed
+// once the time in the given key passes.
+func EnsureTTLIndex(c *mongo.Collection, key string) {
+	_, err := c.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{key: 1},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+	}
+}
+
 // EnsureIndex ensures that a given key in a given collection is an index.
 func EnsureIndex(c *mongo.Collection, key string) {
 	ensureIndex(c, []string{key}, false, true, true)
@@ -389,9 +468,10 @@ func ensureIndexes(c *mongo.Collection, keys []string, unique, bg, sparse bool)
 }
 
 func ensureIndex(c *mongo.Collection, keys []string, unique, bg, sparse bool) {
-	indexKeys := bson.M{}
+	// Keys of compound indexes are ordered.
+	indexKeys := bson.D{}
 	for _, k := range keys {
-		indexKeys[k] = 1
+		indexKeys = append(indexKeys, bson.E{Key: k, Value: 1})
 	}
 	model := mongo.IndexModel{
 		Keys: indexKeys,
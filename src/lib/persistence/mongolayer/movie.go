@@ -95,7 +95,7 @@ func (m *MongoDAL) GetMoviesByTitle(title string) ([]models.Movie, error) {
 		SetFields(bson.M{
 			"score": bson.M{"$meta": "textScore"},
 		}).
-		SetSort("$testScore:score")
+		SetSort("$textScore:score")
 	return m.GetMovies(opts)
 }
 
@@ -159,12 +159,18 @@ func (m *MongoDAL) GetNowPlayingMovies(query persistence.Query) ([]models.Movie,
 					"theaters": bson.M{
 						"$addToSet": "$theaterId",
 					},
+					"sessionTypes": bson.M{
+						"$addToSet": "$type",
+					},
 				},
 			})
 	} else {
 		p = append(p, bson.M{
 			"$group": bson.M{
 				"_id": "$movieId",
+				"sessionTypes": bson.M{
+					"$addToSet": "$type",
+				},
 			},
 		})
 	}
@@ -190,6 +196,11 @@ func (m *MongoDAL) GetNowPlayingMovies(query persistence.Query) ([]models.Movie,
 	}
 
 	p = append(p, bson.M{"$unwind": "$movie"})
+	p = append(p, bson.M{
+		"$addFields": bson.M{
+			"movie.sessionTypes": "$sessionTypes",
+		},
+	})
 
 	if includeTheaters {
 		p = append(p, bson.M{
@@ -234,6 +245,7 @@ func (m *MongoDAL) GetNowPlayingMovies(query persistence.Query) ([]models.Movie,
 	if includeTheaters {
 		project["movie.theaters"] = 1
 	}
+	project["movie.sessionTypes"] = 1
 
 	// project our desired fields to the final result
 	p = append(p, bson.M{"$project": project})
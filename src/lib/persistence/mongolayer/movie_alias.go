@@ -0,0 +1,67 @@
+package mongolayer
+
+import (
+	"context"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveMovieAlias inserts the alias or points an existing one to the given movie.
+func (m *MongoDAL) SaveMovieAlias(alias models.MovieAlias) error {
+	if alias.ID.IsZero() {
+		alias.ID = primitive.NewObjectID()
+	}
+	if alias.CreatedAt == nil {
+		alias.CreatedAt = getCurrentTime()
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"title":    alias.Title,
+			"movie_id": alias.MovieID,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        alias.ID,
+			"created_at": alias.CreatedAt,
+		},
+	}
+	opts := options.Update().SetUpsert(true)
+	_, err := m.C(CollectionMovieAliases).UpdateOne(context.Background(), bson.M{"alias": alias.Alias}, update, opts)
+	return err
+}
+
+// FindMovieAlias ...
+func (m *MongoDAL) FindMovieAlias(query persistence.Query) (*models.MovieAlias, error) {
+	var result models.MovieAlias
+	err := m.C(CollectionMovieAliases).FindOne(context.Background(), query.GetConditions(), getFindOneOptions(query)).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, err
+}
+
+// GetMovieAliases ...
+func (m *MongoDAL) GetMovieAliases(query persistence.Query) ([]models.MovieAlias, error) {
+	var result = []models.MovieAlias{}
+	var ctx = context.Background()
+	cursor, err := m.C(CollectionMovieAliases).Find(ctx, query.GetConditions(), getFindOptions(query))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	cursor.All(ctx, &result)
+	return result, err
+}
+
+// DeleteMovieAlias ...
+func (m *MongoDAL) DeleteMovieAlias(id string) error {
+	ID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = m.C(CollectionMovieAliases).DeleteOne(context.Background(), bson.M{"_id": ID})
+	return err
+}
@@ -0,0 +1,86 @@
+package mongolayer
+
+import (
+	"context"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SavePendingMatch inserts the match or, if the scraped movie is already
+// waiting for review, refreshes its candidates.
+func (m *MongoDAL) SavePendingMatch(match models.PendingMatch) error {
+	if match.ID.IsZero() {
+		match.ID = primitive.NewObjectID()
+	}
+	now := getCurrentTime()
+	if match.CreatedAt == nil {
+		match.CreatedAt = now
+	}
+	filter := bson.M{
+		"key":    match.Key,
+		"status": models.PendingMatchPending,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"movie":      match.Movie,
+			"candidates": match.Candidates,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        match.ID,
+			"created_at": match.CreatedAt,
+		},
+	}
+	opts := options.Update().SetUpsert(true)
+	_, err := m.C(CollectionPendingMatches).UpdateOne(context.Background(), filter, update, opts)
+	return err
+}
+
+// FindPendingMatch ...
+func (m *MongoDAL) FindPendingMatch(query persistence.Query) (*models.PendingMatch, error) {
+	var result models.PendingMatch
+	err := m.C(CollectionPendingMatches).FindOne(context.Background(), query.GetConditions(), getFindOneOptions(query)).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, err
+}
+
+// GetPendingMatch ...
+func (m *MongoDAL) GetPendingMatch(id string, query persistence.Query) (*models.PendingMatch, error) {
+	ID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return m.FindPendingMatch(query.AddCondition("_id", ID))
+}
+
+// GetPendingMatches ...
+func (m *MongoDAL) GetPendingMatches(query persistence.Query) ([]models.PendingMatch, error) {
+	var result = []models.PendingMatch{}
+	var ctx = context.Background()
+	cursor, err := m.C(CollectionPendingMatches).Find(ctx, query.GetConditions(), getFindOptions(query))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	cursor.All(ctx, &result)
+	return result, err
+}
+
+// UpdatePendingMatch ...
+func (m *MongoDAL) UpdatePendingMatch(id string, match models.PendingMatch) (int64, error) {
+	ID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, err
+	}
+	result, err := m.C(CollectionPendingMatches).UpdateOne(context.Background(), bson.M{"_id": ID}, bson.M{"$set": match})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, err
+}
@@ -2,6 +2,7 @@ package mongolayer
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
@@ -83,6 +84,20 @@ func (m *MongoDAL) DeletePrices(query persistence.Query) (int64, error) {
 	return result.DeletedCount, err
 }
 
+// UpdatePrice ...
+func (m *MongoDAL) UpdatePrice(id string, mp models.Price) (int64, error) {
+	ID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, err
+	}
+	mp.ID = ID
+	result, err := m.C(CollectionPrices).UpdateOne(context.Background(), bson.M{"_id": ID}, bson.M{"$set": mp})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, err
+}
+
 // BuildPriceQuery converts a map of query string to mongolayer syntax for Price model
 func (m *MongoDAL) BuildPriceQuery(q map[string]string) persistence.Query {
 	query := BuildQuery("", q)
@@ -96,3 +111,47 @@ func (m *MongoDAL) BuildPriceQuery(q map[string]string) persistence.Query {
 	}
 	return query
 }
+
+// legacyPriceAttributes maps the labels prices were scraped with before the
+// attribute catalog to attribute IDs. Labels mapped to an empty string don't
+// describe anything special.
+var legacyPriceAttributes = map[string]string{
+	"Magic D":              models.AttributeMagicD,
+	"Poltrona VIP":         models.AttributeVIP,
+	"Poltrona Tradicional": "",
+}
+
+// migratePriceAttributes replaces the labels of prices scraped before the
+// attribute catalog with attribute IDs and weighs them again.
+func (m *MongoDAL) migratePriceAttributes() {
+	labels := make([]string, 0, len(legacyPriceAttributes))
+	for label := range legacyPriceAttributes {
+		labels = append(labels, label)
+	}
+	prices, err := m.GetPrices(m.DefaultQuery().
+		AddCondition("attributes", bson.M{"$in": labels}).
+		SetLimit(-1))
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		return
+	}
+	for _, price := range prices {
+		attrs := make([]string, 0, len(price.Attributes))
+		for _, a := range price.Attributes {
+			if id, ok := legacyPriceAttributes[a]; ok {
+				a = id
+			}
+			attrs = append(attrs, a)
+		}
+		attrs = models.SortAttributes(attrs)
+		_, err := m.C(CollectionPrices).UpdateOne(context.Background(), bson.M{"_id": price.ID}, bson.M{
+			"$set": bson.M{
+				"attributes": attrs,
+				"weight":     models.GetAttributesWeight(attrs),
+			},
+		})
+		if err != nil {
+			fmt.Printf("Error: %s\n", err.Error())
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package mongolayer
+
+import (
+	"testing"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMigratePriceAttributes(t *testing.T) {
+	data, err := getTestingMongoDAL()
+	defer data.Close()
+	assert.NoError(t, err)
+
+	theaterID := primitive.NewObjectID()
+	opts := data.DefaultQuery().AddCondition("theaterId", theaterID)
+
+	legacy := models.Price{
+		ID:         primitive.NewObjectID(),
+		TheaterID:  theaterID,
+		Label:      "Magic D VIP",
+		Full:       40,
+		Attributes: []string{"2D", "3D", "Magic D", "Poltrona VIP"},
+		Weight:     5,
+	}
+	regular := models.Price{
+		ID:         primitive.NewObjectID(),
+		TheaterID:  theaterID,
+		Label:      "Magic D",
+		Full:       30,
+		Attributes: []string{"3D", "Magic D", "Poltrona Tradicional"},
+		Weight:     4,
+	}
+	err = data.InsertPrices(legacy, regular)
+	assert.NoError(t, err)
+
+	// Setup migrates the prices.
+	data.Setup()
+
+	price, err := data.GetPrice(legacy.ID.Hex(), data.DefaultQuery())
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{models.Attribute2D, models.Attribute3D, models.AttributeVIP, models.AttributeMagicD}, price.Attributes)
+		assert.Equal(t, uint(5), price.Weight)
+	}
+
+	price, err = data.GetPrice(regular.ID.Hex(), data.DefaultQuery())
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{models.Attribute3D, models.AttributeMagicD}, price.Attributes)
+		assert.Equal(t, uint(4), price.Weight)
+	}
+
+	_, err = data.DeletePrices(opts)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,53 @@
+package mongolayer
+
+import (
+	"context"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TakeRateLimitToken refills the bucket with the given key and takes a token
+// from it, if there is one, in a single update.
+func (m *MongoDAL) TakeRateLimitToken(key string, rate, burst float64, now time.Time) (*models.RateLimitBucket, error) {
+	// Time needed to refill an empty bucket, after which it can be removed.
+	expiresAt := now.Add(time.Duration(burst / rate * float64(time.Second)))
+
+	// Buckets start full. Date subtraction results in milliseconds.
+	elapsed := bson.M{"$divide": bson.A{
+		bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updatedAt", now}}}},
+		1000,
+	}}
+	refilled := bson.M{"$min": bson.A{
+		burst,
+		bson.M{"$add": bson.A{
+			bson.M{"$ifNull": bson.A{"$tokens", burst}},
+			bson.M{"$multiply": bson.A{bson.M{"$max": bson.A{elapsed, 0}}, rate}},
+		}},
+	}}
+	pipeline := bson.A{
+		bson.M{"$set": bson.M{"tokens": refilled, "updatedAt": now, "expiresAt": expiresAt}},
+		// Fields of a stage see the document before it, so allowed uses the
+		// refilled tokens.
+		bson.M{"$set": bson.M{
+			"allowed": bson.M{"$gte": bson.A{"$tokens", 1}},
+			"tokens": bson.M{"$cond": bson.A{
+				bson.M{"$gte": bson.A{"$tokens", 1}},
+				bson.M{"$subtract": bson.A{"$tokens", 1}},
+				"$tokens",
+			}},
+		}},
+	}
+
+	var result models.RateLimitBucket
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+	err := m.C(CollectionRateLimits).FindOneAndUpdate(context.Background(), bson.M{"_id": key}, pipeline, opts).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -61,6 +61,25 @@ func (m *MongoDAL) UpdateScraper(id string, ms models.Scraper) (int64, error) {
 	return result.ModifiedCount, err
 }
 
+// DeleteScraper ...
+func (m *MongoDAL) DeleteScraper(id string) error {
+	ID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = m.C(CollectionScrapers).DeleteOne(context.Background(), bson.M{"_id": ID})
+	return err
+}
+
+// DeleteScrapers ...
+func (m *MongoDAL) DeleteScrapers(query persistence.Query) (int64, error) {
+	result, err := m.C(CollectionScrapers).DeleteMany(context.Background(), query.GetConditions())
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, err
+}
+
 // BuildScraperQuery converts a map of query string to mongolayer syntax for Scraper model
 func (m *MongoDAL) BuildScraperQuery(q map[string]string) persistence.Query {
 	query := BuildQuery("", q)
@@ -0,0 +1,170 @@
+package mongolayer
+
+import (
+	"context"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnqueueScraperJob inserts the job or, if the scraper is already waiting in
+// the queue, raises the priority of the pending one and keeps ignore_last_run
+// set if any of the merged jobs had it.
+func (m *MongoDAL) EnqueueScraperJob(job models.ScraperJob) error {
+	if job.ID.IsZero() {
+		job.ID = primitive.NewObjectID()
+	}
+	if job.CreatedAt == nil {
+		job.CreatedAt = getCurrentTime()
+	}
+	filter := bson.M{
+		"scraper_id": job.ScraperID,
+		"status":     models.ScraperJobPending,
+	}
+	update := bson.M{
+		"$max": bson.M{
+			"priority":        job.Priority,
+			"ignore_last_run": job.IgnoreLastRun,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        job.ID,
+			"host":       job.Host,
+			"attempt":    job.Attempt,
+			"retry_of":   job.RetryOf,
+			"not_before": job.NotBefore,
+			"created_at": job.CreatedAt,
+		},
+	}
+	opts := options.Update().SetUpsert(true)
+	_, err := m.C(CollectionScraperJobs).UpdateOne(context.Background(), filter, update, opts)
+	return err
+}
+
+// maxClaimAttempts limits how many times a claim is retried when the next
+// job's scraper is already running somewhere else.
+const maxClaimAttempts = 5
+
+// ClaimScraperJob marks the next pending job as running and returns it.
+// Jobs of scrapers that are already running are skipped.
+func (m *MongoDAL) ClaimScraperJob(excludeHosts []string, excludeScrapers []string) (*models.ScraperJob, error) {
+	scrapers := make([]primitive.ObjectID, 0)
+	for _, s := range excludeScrapers {
+		ID, err := primitive.ObjectIDFromHex(s)
+		if err == nil {
+			scrapers = append(scrapers, ID)
+		}
+	}
+	if excludeHosts == nil {
+		excludeHosts = []string{}
+	}
+
+	now := time.Now().UTC()
+	filter := bson.M{
+		"status":     models.ScraperJobPending,
+		"host":       bson.M{"$nin": excludeHosts},
+		"scraper_id": bson.M{"$nin": scrapers},
+		"$or": []bson.M{
+			{"not_before": nil},
+			{"not_before": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     models.ScraperJobRunning,
+			"started_at": now,
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var err error
+	for attempt := 0; attempt < maxClaimAttempts; attempt++ {
+		var result models.ScraperJob
+		err = m.C(CollectionScraperJobs).FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&result)
+		if err == nil {
+			return &result, nil
+		}
+		if !isDuplicateKeyError(err) {
+			return nil, err
+		}
+		// Another worker is running the scraper of the next job, which is
+		// claimed once that run finishes.
+		running, err := m.GetScraperJobs(m.DefaultQuery().
+			AddCondition("status", models.ScraperJobRunning).
+			SetLimit(-1))
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range running {
+			scrapers = append(scrapers, job.ScraperID)
+		}
+		filter["scraper_id"] = bson.M{"$nin": scrapers}
+	}
+	return nil, err
+}
+
+// GetScraperJobs ...
+func (m *MongoDAL) GetScraperJobs(query persistence.Query) ([]models.ScraperJob, error) {
+	var result = []models.ScraperJob{}
+	var ctx = context.Background()
+	cursor, err := m.C(CollectionScraperJobs).Find(ctx, query.GetConditions(), getFindOptions(query))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	cursor.All(ctx, &result)
+	return result, err
+}
+
+// DeleteScraperJob ...
+func (m *MongoDAL) DeleteScraperJob(id string) error {
+	ID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = m.C(CollectionScraperJobs).DeleteOne(context.Background(), bson.M{"_id": ID})
+	return err
+}
+
+// ResetScraperJobs moves running jobs back to pending. Jobs of scrapers that
+// were queued again in the meantime are removed.
+func (m *MongoDAL) ResetScraperJobs() (int64, error) {
+	running, err := m.GetScraperJobs(m.DefaultQuery().
+		AddCondition("status", models.ScraperJobRunning).
+		SetLimit(-1))
+	if err != nil {
+		return 0, err
+	}
+
+	var result int64
+	var C = m.C(CollectionScraperJobs)
+	for _, job := range running {
+		_, err := C.UpdateOne(context.Background(),
+			bson.M{"_id": job.ID},
+			bson.M{
+				"$set":   bson.M{"status": models.ScraperJobPending},
+				"$unset": bson.M{"started_at": ""},
+			})
+		if err != nil {
+			if !isDuplicateKeyError(err) {
+				return result, err
+			}
+			_, err = C.DeleteOne(context.Background(), bson.M{"_id": job.ID})
+			if err != nil {
+				return result, err
+			}
+		}
+		result++
+	}
+	return result, nil
+}
+
+func isDuplicateKeyError(err error) bool {
+	return mongo.IsDuplicateKeyError(err)
+}
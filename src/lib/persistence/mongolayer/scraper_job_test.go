@@ -0,0 +1,30 @@
+package mongolayer
+
+import (
+	"testing"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEnqueueScraperJob(t *testing.T) {
+	data, err := getTestingMongoDAL()
+	defer data.Close()
+	assert.NoError(t, err)
+
+	scraperID := primitive.NewObjectID()
+
+	// Pending jobs of the same scraper are merged.
+	assert.NoError(t, data.EnqueueScraperJob(models.ScraperJob{ScraperID: scraperID, Status: models.ScraperJobPending, Priority: 1, IgnoreLastRun: true}))
+	assert.NoError(t, data.EnqueueScraperJob(models.ScraperJob{ScraperID: scraperID, Status: models.ScraperJobPending, Priority: 5}))
+	assert.NoError(t, data.EnqueueScraperJob(models.ScraperJob{ScraperID: scraperID, Status: models.ScraperJobPending, Priority: 2}))
+
+	jobs, err := data.GetScraperJobs(DefaultOptions("").AddCondition("scraper_id", scraperID))
+	assert.NoError(t, err)
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, 5, jobs[0].Priority)
+		assert.True(t, jobs[0].IgnoreLastRun)
+		assert.NoError(t, data.DeleteScraperJob(jobs[0].ID.Hex()))
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -14,7 +15,8 @@ func (m *MongoDAL) InsertScraperRun(scraperRun models.ScraperRun) error {
 	if err != nil {
 		return err
 	}
-	if scraperRun.Scraper != nil {
+	// Quarantined runs only become the scraper's last run once approved.
+	if scraperRun.Scraper != nil && scraperRun.Quarantine == nil {
 		scraperRun.Scraper.Theater = nil // Make sure we don't store theater...
 		scraperRun.Scraper.LastRun = scraperRun.ID
 		_, err = m.UpdateScraper(scraperRun.ScraperID.Hex(), *scraperRun.Scraper)
@@ -54,3 +56,16 @@ func (m *MongoDAL) GetScraperRuns(query persistence.Query) ([]models.ScraperRun,
 	cursor.All(ctx, &result)
 	return result, err
 }
+
+// UpdateScraperRun ...
+func (m *MongoDAL) UpdateScraperRun(id string, scraperRun models.ScraperRun) (int64, error) {
+	ID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, err
+	}
+	result, err := m.C(CollectionScraperRuns).UpdateOne(context.Background(), bson.M{"_id": ID}, bson.M{"$set": scraperRun})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, err
+}
@@ -2,6 +2,9 @@ package mongolayer
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
@@ -11,8 +14,16 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// CountSessions ...
+func (m *MongoDAL) CountSessions(query persistence.Query) (int64, error) {
+	return m.C(CollectionSessions).CountDocuments(context.Background(), query.GetConditions())
+}
+
 // InsertSession ...
 func (m *MongoDAL) InsertSession(session models.Session) error {
+	if session.Key == "" {
+		session.Key = session.NaturalKey()
+	}
 	_, err := m.C(CollectionSessions).InsertOne(context.Background(), session)
 	return err
 }
@@ -21,12 +32,137 @@ func (m *MongoDAL) InsertSession(session models.Session) error {
 func (m *MongoDAL) InsertSessions(sessions ...models.Session) error {
 	arr := make([]interface{}, len(sessions))
 	for i, p := range sessions {
+		if p.Key == "" {
+			p.Key = p.NaturalKey()
+		}
 		arr[i] = p
 	}
 	_, err := m.C(CollectionSessions).InsertMany(context.Background(), arr)
 	return err
 }
 
+// UpsertSessions ...
+func (m *MongoDAL) UpsertSessions(provider string, sessions ...models.Session) (int64, error) {
+	if len(sessions) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now().UTC()
+	writes := make([]mongo.WriteModel, len(sessions))
+	for i := range sessions {
+		s := &sessions[i]
+		if s.Key == "" {
+			s.Key = s.NaturalKey()
+		}
+		update := bson.M{
+			"$set":         mergeSession(s, now),
+			"$setOnInsert": bson.M{"createdAt": now},
+		}
+		if provider != "" {
+			update["$addToSet"] = bson.M{"providers": provider}
+		}
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"key": s.Key}).
+			SetUpdate(update).
+			SetUpsert(true)
+	}
+
+	result, err := m.C(CollectionSessions).BulkWrite(context.Background(), writes)
+	if err != nil {
+		return 0, err
+	}
+	return result.UpsertedCount, nil
+}
+
+// mergeSession builds the fields to set in an existing session. Fields the
+// provider didn't report are kept as they are.
+func mergeSession(s *models.Session, now time.Time) bson.M {
+	result := bson.M{
+		"theaterId": s.TheaterID,
+		"room":      s.Room,
+		"startTime": s.StartTime,
+		"version":   s.Version,
+		"format":    s.Format,
+		"updatedAt": now,
+	}
+	if !s.MovieID.IsZero() {
+		result["movieId"] = s.MovieID
+	}
+	if s.MovieSlugs.NoDashes != "" {
+		result["movieSlugs"] = s.MovieSlugs
+	}
+	if s.ScrapedSlug != "" {
+		result["scrapedSlug"] = s.ScrapedSlug
+	}
+	if s.TimeZone != "" {
+		result["timeZone"] = s.TimeZone
+	}
+	if s.OpeningTime != "" {
+		result["openingTime"] = s.OpeningTime
+	}
+	if s.Date != 0 {
+		result["date"] = s.Date
+	}
+	if s.Type != "" {
+		result["type"] = s.Type
+	}
+	// Attributes are the ones of the last provider, they already agree on
+	// version and format.
+	if len(s.Attributes) > 0 {
+		result["attributes"] = s.Attributes
+	}
+	return result
+}
+
+// migrateSessionAttributes fills the attributes of sessions scraped before
+// attributes existed from their format and version, so filtering by
+// attributes finds them too.
+func (m *MongoDAL) migrateSessionAttributes() {
+	ctx := context.Background()
+	legacy := bson.M{"attributes": bson.M{"$exists": false}}
+	cursor, err := m.C(CollectionSessions).Aggregate(ctx, []bson.M{
+		{"$match": legacy},
+		{"$group": bson.M{"_id": bson.M{"format": "$format", "version": "$version"}}},
+	})
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		return
+	}
+	var groups []struct {
+		ID struct {
+			Format  string `bson:"format"`
+			Version string `bson:"version"`
+		} `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		return
+	}
+
+	for _, g := range groups {
+		s := models.Session{Format: g.ID.Format, Version: g.ID.Version}
+		filter := bson.M{
+			"attributes": bson.M{"$exists": false},
+			"format":     orMissing(g.ID.Format),
+			"version":    orMissing(g.ID.Version),
+		}
+		_, err := m.C(CollectionSessions).UpdateMany(ctx, filter, bson.M{
+			"$set": bson.M{"attributes": s.GetAttributes()},
+		})
+		if err != nil {
+			fmt.Printf("Error: %s\n", err.Error())
+		}
+	}
+}
+
+// orMissing matches the value, or documents without the field if it's empty.
+func orMissing(value string) interface{} {
+	if value == "" {
+		return bson.M{"$in": []interface{}{"", nil}}
+	}
+	return value
+}
+
 // FindSession ...
 func (m *MongoDAL) FindSession(query persistence.Query) (*models.Session, error) {
 	var result models.Session
@@ -70,6 +206,15 @@ func (m *MongoDAL) GetSessions(query persistence.Query) ([]models.Session, error
 	return result, err
 }
 
+// UpdateSessions ...
+func (m *MongoDAL) UpdateSessions(query persistence.Query, update interface{}) (int64, error) {
+	result, err := m.C(CollectionSessions).UpdateMany(context.Background(), query.GetConditions(), update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, err
+}
+
 // DeleteSession ...
 func (m *MongoDAL) DeleteSession(id string) error {
 	ID, err := primitive.ObjectIDFromHex(id)
@@ -129,6 +274,19 @@ func (m *MongoDAL) BuildSessionQuery(q map[string]string) persistence.Query {
 			}
 		}
 
+		if attrs, ok := q["attributes"]; ok && attrs != "" {
+			query.AddCondition("attributes", bson.M{"$all": strings.Split(attrs, ",")})
+		}
+
+		// Providers name some versions differently.
+		if versions, ok := q["version"]; ok && versions != "" {
+			names := make([]string, 0)
+			for _, v := range strings.Split(versions, ",") {
+				names = append(names, models.VersionNames(v)...)
+			}
+			query.AddCondition("version", bson.M{"$in": names})
+		}
+
 		if movie, ok := q["movieId"]; ok {
 			value, err := primitive.ObjectIDFromHex(movie)
 			if err == nil {
@@ -0,0 +1,107 @@
+package mongolayer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUpsertSessions(t *testing.T) {
+	data, err := getTestingMongoDAL()
+	defer data.Close()
+	assert.NoError(t, err)
+
+	theaterID := primitive.NewObjectID()
+	opts := data.DefaultQuery().AddCondition("theaterId", theaterID)
+	_, err = data.DeleteSessions(opts)
+	assert.NoError(t, err)
+
+	start := time.Now().UTC().Truncate(time.Minute).Add(time.Hour)
+	scraped := models.Session{
+		TheaterID:   theaterID,
+		Room:        1,
+		StartTime:   &start,
+		MovieSlugs:  models.Slugs{NoDashes: "coringa"},
+		ScrapedSlug: "coringa",
+		Attributes:  []string{"2d"},
+	}
+
+	// Both providers matched the movie.
+	matched := scraped
+	matched.MovieID = primitive.NewObjectID()
+	matched.MovieSlugs = models.Slugs{NoDashes: "coringa2019"}
+	inserted, err := data.UpsertSessions("cinemais", matched)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), inserted)
+
+	other := matched
+	other.ScrapedSlug = "joker"
+	other.Attributes = []string{"2d", "subtitled"}
+	inserted, err = data.UpsertSessions("ibicinemas", other)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), inserted)
+
+	sessions, err := data.GetSessions(opts)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		s := sessions[0]
+		assert.Equal(t, matched.NaturalKey(), s.Key)
+		assert.Equal(t, matched.MovieID, s.MovieID)
+		assert.ElementsMatch(t, []string{"cinemais", "ibicinemas"}, s.Providers)
+		// Attributes are replaced, not merged.
+		assert.Equal(t, []string{"2d", "subtitled"}, s.Attributes)
+	}
+
+	inserted, err = data.UpsertSessions("cinemais", matched)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), inserted)
+	sessions, err = data.GetSessions(opts)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, []string{"2d"}, sessions[0].Attributes)
+	}
+
+	// Another room is another session.
+	room := matched
+	room.Room = 2
+	inserted, err = data.UpsertSessions("cinemais", room)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), inserted)
+
+	count, err := data.CountSessions(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	_, err = data.DeleteSessions(opts)
+	assert.NoError(t, err)
+}
+
+func TestMigrateSessionAttributes(t *testing.T) {
+	data, err := getTestingMongoDAL()
+	defer data.Close()
+	assert.NoError(t, err)
+
+	theaterID := primitive.NewObjectID()
+	opts := data.DefaultQuery().AddCondition("theaterId", theaterID)
+	start := time.Now().UTC().Truncate(time.Minute)
+	legacy := models.Session{
+		TheaterID:   theaterID,
+		StartTime:   &start,
+		ScrapedSlug: "coringa",
+		Format:      models.Format3D,
+		Version:     models.VersionSubbed,
+	}
+	assert.NoError(t, data.InsertSession(legacy))
+
+	data.(*MongoDAL).migrateSessionAttributes()
+	sessions, err := data.GetSessions(opts.AddCondition("attributes", bson.M{"$all": []string{"3D", "subtitled"}}))
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+
+	_, err = data.DeleteSessions(data.DefaultQuery().AddCondition("theaterId", theaterID))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,104 @@
+package mongolayer
+
+import (
+	"context"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InsertSigningKey ...
+func (m *MongoDAL) InsertSigningKey(key models.SigningKey) (bool, error) {
+	if key.CreatedAt == nil {
+		key.CreatedAt = getCurrentTime()
+	}
+	_, err := m.C(CollectionSigningKeys).InsertOne(context.Background(), key)
+	if isDuplicateKeyError(err) {
+		// Another instance rotated the key first.
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetSigningKeys ...
+func (m *MongoDAL) GetSigningKeys(query persistence.Query) ([]models.SigningKey, error) {
+	var result = []models.SigningKey{}
+	var ctx = context.Background()
+	cursor, err := m.C(CollectionSigningKeys).Find(ctx, query.GetConditions(), getFindOptions(query))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	err = cursor.All(ctx, &result)
+	return result, err
+}
+
+// InsertRefreshToken ...
+func (m *MongoDAL) InsertRefreshToken(token models.RefreshToken) error {
+	if token.CreatedAt == nil {
+		token.CreatedAt = getCurrentTime()
+	}
+	_, err := m.C(CollectionRefreshTokens).InsertOne(context.Background(), token)
+	return err
+}
+
+// GetRefreshToken ...
+func (m *MongoDAL) GetRefreshToken(id string) (*models.RefreshToken, error) {
+	var result models.RefreshToken
+	err := m.C(CollectionRefreshTokens).FindOne(context.Background(), bson.M{"_id": id}).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UseRefreshToken marks the refresh token with the given id as replaced, if
+// it's still valid. The check and update are atomic, so tokens can't be
+// used twice.
+func (m *MongoDAL) UseRefreshToken(id string, now time.Time) (*models.RefreshToken, error) {
+	var result models.RefreshToken
+	conditions := bson.M{
+		"_id":        id,
+		"replacedAt": bson.M{"$exists": false},
+		"revokedAt":  bson.M{"$exists": false},
+		"expiresAt":  bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"replacedAt": now}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := m.C(CollectionRefreshTokens).FindOneAndUpdate(context.Background(), conditions, update, opts).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RevokeRefreshTokens ...
+func (m *MongoDAL) RevokeRefreshTokens(family string, now time.Time) (int64, error) {
+	conditions := bson.M{"family": family, "revokedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revokedAt": now}}
+	result, err := m.C(CollectionRefreshTokens).UpdateMany(context.Background(), conditions, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// InsertRevokedToken ...
+func (m *MongoDAL) InsertRevokedToken(token models.RevokedToken) error {
+	opts := options.Replace().SetUpsert(true)
+	_, err := m.C(CollectionRevokedTokens).ReplaceOne(context.Background(), bson.M{"_id": token.ID}, token, opts)
+	return err
+}
+
+// IsTokenRevoked ...
+func (m *MongoDAL) IsTokenRevoked(id string) (bool, error) {
+	err := m.C(CollectionRevokedTokens).FindOne(context.Background(), bson.M{"_id": id}).Err()
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	return err == nil, err
+}
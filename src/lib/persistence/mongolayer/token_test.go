@@ -0,0 +1,58 @@
+package mongolayer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSigningKey(t *testing.T) {
+	data, err := getTestingMongoDAL()
+	defer data.Close()
+	assert.NoError(t, err)
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(time.Hour)
+	replaces := primitive.NewObjectID().Hex()
+	first := models.SigningKey{ID: primitive.NewObjectID().Hex(), Replaces: replaces, ExpiresAt: &expiresAt}
+	second := models.SigningKey{ID: primitive.NewObjectID().Hex(), Replaces: replaces, ExpiresAt: &expiresAt}
+
+	// Only one key replaces each key.
+	inserted, err := data.InsertSigningKey(first)
+	assert.NoError(t, err)
+	assert.True(t, inserted)
+	inserted, err = data.InsertSigningKey(second)
+	assert.NoError(t, err)
+	assert.False(t, inserted)
+
+	keys, err := data.GetSigningKeys(DefaultOptions("").AddCondition("replaces", replaces))
+	assert.NoError(t, err)
+	if assert.Len(t, keys, 1) {
+		assert.Equal(t, first.ID, keys[0].ID)
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	data, err := getTestingMongoDAL()
+	defer data.Close()
+	assert.NoError(t, err)
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(time.Hour)
+	token := models.RefreshToken{ID: primitive.NewObjectID().Hex(), Family: primitive.NewObjectID().Hex(), ExpiresAt: &expiresAt}
+	assert.NoError(t, data.InsertRefreshToken(token))
+
+	// Tokens can be used once.
+	used, err := data.UseRefreshToken(token.ID, now)
+	assert.NoError(t, err)
+	assert.NotNil(t, used.ReplacedAt)
+	_, err = data.UseRefreshToken(token.ID, now)
+	assert.Error(t, err)
+
+	n, err := data.RevokeRefreshTokens(token.Family, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
@@ -1,6 +1,8 @@
 package persistence
 
 import (
+	"time"
+
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 )
 
@@ -52,6 +54,10 @@ type DataAccessLayer interface {
 	// @param	query{Query}  - Options used to retrieve data
 	FindAPIKey(query Query) (*models.APIKey, error)
 
+	// FindAPIKeyByKey retrieves the APIKey resource matching the given key
+	// @param	key{string}  - The key given by clients
+	FindAPIKeyByKey(key string) (*models.APIKey, error)
+
 	// GetAPIKey retrieves a APIKey resource by ID
 	// @param	id{string} 		- APIKey identifier
 	// @param	query{Query}  - Options used to retrieve data
@@ -61,6 +67,11 @@ type DataAccessLayer interface {
 	// @param	query{Query} - Options used to retrieve data
 	GetAPIKeys(query Query) ([]models.APIKey, error)
 
+	// UpdateAPIKey updates a single APIKey matching the given id
+	// @param	id{string} 		- APIKey identifier
+	// @param	apikey{models.APIKey} - Updated APIKey
+	UpdateAPIKey(id string, apikey models.APIKey) (int64, error)
+
 	// DeleteAPIKey removes a single APIKey matching the given id
 	// @param	id{string} 		- APIKey identifier
 	DeleteAPIKey(id string) error
@@ -182,6 +193,25 @@ type DataAccessLayer interface {
 	// TODO:
 	UpdateMovie(id string, m models.Movie) (int64, error)
 
+	// ------ Movie Alias ------
+
+	// SaveMovieAlias inserts a MovieAlias resource or, if the alias already
+	// exists, points it to the given movie
+	// @param alias{models.MovieAlias} - A MovieAlias resource to be saved
+	SaveMovieAlias(alias models.MovieAlias) error
+
+	// FindMovieAlias retrieves a MovieAlias resource matching the given Query
+	// @param	query{Query}  - Options used to retrieve data
+	FindMovieAlias(query Query) (*models.MovieAlias, error)
+
+	// GetMovieAliases retrieves all MovieAlias resources matching the given Query
+	// @param	query{Query} - Options used to retrieve data
+	GetMovieAliases(query Query) ([]models.MovieAlias, error)
+
+	// DeleteMovieAlias removes a single MovieAlias matching the given id
+	// @param	id{string} - MovieAlias identifier
+	DeleteMovieAlias(id string) error
+
 	// ------ Notification ------
 
 	// InsertNotification inserts a single Notification resource
@@ -209,6 +239,31 @@ type DataAccessLayer interface {
 	// @param	query{Query} - Options used to retrieve data
 	DeleteNotifications(query Query) (int64, error)
 
+	// ------ Pending Match ------
+
+	// SavePendingMatch inserts a PendingMatch resource. If the scraped movie is
+	// already waiting for review, its candidates are refreshed instead
+	// @param match{models.PendingMatch} - A PendingMatch resource to be saved
+	SavePendingMatch(match models.PendingMatch) error
+
+	// FindPendingMatch retrieves a PendingMatch resource matching the given Query
+	// @param	query{Query}  - Options used to retrieve data
+	FindPendingMatch(query Query) (*models.PendingMatch, error)
+
+	// GetPendingMatch retrieves a PendingMatch resource by ID
+	// @param	id{string} 		- PendingMatch identifier
+	// @param	query{Query}  - Options used to retrieve data
+	GetPendingMatch(id string, query Query) (*models.PendingMatch, error)
+
+	// GetPendingMatches retrieves all PendingMatch resources matching the given Query
+	// @param	query{Query} - Options used to retrieve data
+	GetPendingMatches(query Query) ([]models.PendingMatch, error)
+
+	// UpdatePendingMatch updates a single PendingMatch matching the given id
+	// @param	id{string} 		- PendingMatch identifier
+	// @param	match{models.PendingMatch} - PendingMatch data
+	UpdatePendingMatch(id string, match models.PendingMatch) (int64, error)
+
 	// ------ Price ------
 
 	// InsertPrice inserts a single Price resource
@@ -236,6 +291,11 @@ type DataAccessLayer interface {
 	// @param	query{Query} - Options used to retrieve data
 	DeletePrices(query Query) (int64, error)
 
+	// UpdatePrice updates a single Price matching the given id
+	// @param	id{string} 		- Price identifier
+	// @param	price{models.Price} - Price data
+	UpdatePrice(id string, price models.Price) (int64, error)
+
 	// ------ Score ------
 
 	// InsertScore inserts a single Score resource
@@ -283,11 +343,11 @@ type DataAccessLayer interface {
 
 	// DeleteScraper removes a single Scraper matching the given id
 	// @param	id{string} - Scraper identifier
-	// DeleteScraper(id string) error
+	DeleteScraper(id string) error
 
 	// DeleteScrapers removes all Scrapers matching the given Query
 	// @param	query{Query} - Options used to retrieve data
-	// DeleteScrapers(query Query) error
+	DeleteScrapers(query Query) (int64, error)
 
 	// TODO:
 	UpdateScraper(id string, s models.Scraper) (int64, error)
@@ -303,7 +363,40 @@ type DataAccessLayer interface {
 	// @param	query{Query}  - Options used to retrieve data
 	GetScraperRun(id string, query Query) (*models.ScraperRun, error)
 
+	// GetScraperRuns retrieves all ScraperRun resources matching the given Query
+	// @param	query{Query} - Options used to retrieve data
+	GetScraperRuns(query Query) ([]models.ScraperRun, error)
+
+	// UpdateScraperRun updates a single ScraperRun matching the given id
+	// @param	id{string} 		- ScraperRun identifier
+	// @param	scraperRun{models.ScraperRun} - ScraperRun data
+	UpdateScraperRun(id string, scraperRun models.ScraperRun) (int64, error)
+
+	// ------ Scraper Job ------
+
+	// EnqueueScraperJob adds a ScraperJob to the work queue. If the scraper is
+	// already pending, only its priority and ignore_last_run are raised.
+	// @param job{models.ScraperJob} - A ScraperJob resource to be queued
+	EnqueueScraperJob(job models.ScraperJob) error
+
+	// ClaimScraperJob marks the next pending ScraperJob as running and returns it
+	// @param	excludeHosts{[]string}    - Provider hosts that are at their concurrency limit
+	// @param	excludeScrapers{[]string} - Scraper identifiers that are already running
+	ClaimScraperJob(excludeHosts []string, excludeScrapers []string) (*models.ScraperJob, error)
+
+	// GetScraperJobs retrieves all ScraperJob resources matching the given Query
+	// @param	query{Query} - Options used to retrieve data
+	GetScraperJobs(query Query) ([]models.ScraperJob, error)
+
+	// DeleteScraperJob removes a single ScraperJob matching the given id
+	// @param	id{string} - ScraperJob identifier
+	DeleteScraperJob(id string) error
+
+	// ResetScraperJobs moves jobs interrupted while running back to pending
+	ResetScraperJobs() (int64, error)
+
 	// ------ Session ------
+	CountSessions(query Query) (int64, error)
 
 	// InsertSession inserts a single Session resource
 	// @param session{models.Session} - A Session resource to insert
@@ -313,6 +406,13 @@ type DataAccessLayer interface {
 	// @param sessions{[]models.Session} - A list of Session resources to insert
 	InsertSessions(sessions ...models.Session) error
 
+	// UpsertSessions inserts sessions that don't exist yet and merges the
+	// ones that already exist, matching them by their natural key. The
+	// provider is added to the list of providers reporting each session.
+	// @param provider{string}           - Provider reporting the sessions
+	// @param sessions{[]models.Session} - A list of Session resources to upsert
+	UpsertSessions(provider string, sessions ...models.Session) (int64, error)
+
 	// GetSession retrieves a Session resource by ID
 	// @param	id{string} 		- Session identifier
 	// @param	query{Query}  - Options used to retrieve data
@@ -322,6 +422,11 @@ type DataAccessLayer interface {
 	// @param	query{Query} - Options used to retrieve data
 	GetSessions(query Query) ([]models.Session, error)
 
+	// UpdateSessions applies the update to all Sessions matching the given Query
+	// @param	query{Query} 					- Options used to find sessions
+	// @param	update{interface{}}   - Update data
+	UpdateSessions(query Query, update interface{}) (int64, error)
+
 	// DeleteSession removes a single Session matching the given id
 	// @param	id{string} - Session identifier
 	DeleteSession(id string) error
@@ -382,6 +487,92 @@ type DataAccessLayer interface {
 	// @param	query{Query} - Options used to retrieve data
 	DeleteCities(query Query) (int64, error)
 
+	// ------ Data Version ------
+
+	// GetDataVersion retrieves the current version of the scraped data
+	GetDataVersion() (*models.DataVersion, error)
+
+	// BumpDataVersion increments the version of the scraped data. Must be
+	// called whenever scrapers change the data served by the API
+	BumpDataVersion() (*models.DataVersion, error)
+
+	// ------ Signing Key ------
+
+	// InsertSigningKey inserts a single SigningKey resource, unless another
+	// key already replaces the same key. Returns whether it was inserted
+	// @param	key{models.SigningKey} - SigningKey to be inserted
+	InsertSigningKey(key models.SigningKey) (bool, error)
+
+	// GetSigningKeys retrieves all SigningKey resources matching the given Query
+	// @param	query{Query} - Options used to retrieve data
+	GetSigningKeys(query Query) ([]models.SigningKey, error)
+
+	// ------ Refresh Token ------
+
+	// InsertRefreshToken inserts a single RefreshToken resource
+	// @param	token{models.RefreshToken} - RefreshToken to be inserted
+	InsertRefreshToken(token models.RefreshToken) error
+
+	// GetRefreshToken retrieves a RefreshToken resource by ID
+	// @param	id{string} - Hash of the token
+	GetRefreshToken(id string) (*models.RefreshToken, error)
+
+	// UseRefreshToken marks the RefreshToken with the given ID as replaced, if
+	// it's not replaced, revoked or expired yet
+	// @param	id{string} 			- Hash of the token
+	// @param	now{time.Time}	- Current time
+	UseRefreshToken(id string, now time.Time) (*models.RefreshToken, error)
+
+	// RevokeRefreshTokens revokes all RefreshTokens of the given family
+	// @param	family{string} 	- Family identifier
+	// @param	now{time.Time}	- Current time
+	RevokeRefreshTokens(family string, now time.Time) (int64, error)
+
+	// ------ Revoked Token ------
+
+	// InsertRevokedToken revokes an access token until it expires
+	// @param	token{models.RevokedToken} - RevokedToken to be inserted
+	InsertRevokedToken(token models.RevokedToken) error
+
+	// IsTokenRevoked tells whether the access token with the given ID was revoked
+	// @param	id{string} - Token identifier
+	IsTokenRevoked(id string) (bool, error)
+
+	// ------ Rate Limit ------
+
+	// TakeRateLimitToken refills the token bucket with the given key at rate
+	// tokens per second, up to burst tokens, and takes a token from it if
+	// there is one. Missing buckets start full
+	// @param	key{string}			- Bucket identifier
+	// @param	rate{float64}		- Tokens added per second
+	// @param	burst{float64}	- Bucket capacity
+	// @param	now{time.Time}	- Time of the request
+	TakeRateLimitToken(key string, rate, burst float64, now time.Time) (*models.RateLimitBucket, error)
+
+	// ------ Holiday ------
+
+	// InsertHoliday inserts a single Holiday override
+	// @param holiday{models.Holiday} - A Holiday resource to be inserted
+	InsertHoliday(holiday models.Holiday) error
+
+	// GetHoliday retrieves a Holiday override by ID
+	// @param	id{string} 		- Holiday identifier
+	// @param	query{Query}  - Options used to retrieve data
+	GetHoliday(id string, query Query) (*models.Holiday, error)
+
+	// GetHolidays retrieves all Holiday overrides matching the given Query
+	// @param	query{Query} - Options used to retrieve data
+	GetHolidays(query Query) ([]models.Holiday, error)
+
+	// UpdateHoliday replaces the Holiday override with the given id
+	// @param	id{string} 								- Holiday identifier
+	// @param	holiday{models.Holiday}  - Holiday data
+	UpdateHoliday(id string, holiday models.Holiday) (int64, error)
+
+	// DeleteHoliday removes a single Holiday override matching the given id
+	// @param	id{string} - Holiday identifier
+	DeleteHoliday(id string) error
+
 	// ------ State ------
 
 	// InsertState inserts a single State resource
@@ -0,0 +1,233 @@
+// Package ratelimit limits request volume with token buckets kept in a
+// pluggable store.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Wildcard matches any user type or platform in parsed rules.
+const Wildcard = "*"
+
+type (
+	// Limit is the capacity and refill rate of a token bucket. Buckets are
+	// refilled with Requests tokens per Period.
+	Limit struct {
+		Requests int
+		Period   time.Duration
+		Burst    int // Burst is the bucket capacity, defaults to Requests
+	}
+
+	// Result is the state of a bucket after taking a token.
+	Result struct {
+		Allowed    bool
+		Limit      Limit
+		Remaining  int           // Remaining tokens
+		Reset      time.Duration // Reset is the time until the bucket is full
+		RetryAfter time.Duration // RetryAfter is the time until the next token, if denied
+	}
+
+	// Store keeps token buckets.
+	Store interface {
+		// Take refills the bucket with the given key and takes a token from
+		// it, if there is one. Missing buckets start full.
+		Take(key string, limit Limit, now time.Time) (Result, error)
+	}
+
+	// Identity identifies the client of a request.
+	Identity struct {
+		Key      string // Key is the API key, or the key claim of JWT tokens
+		UserType string
+		Platform string
+	}
+
+	// Rule limits the requests of clients matching its non-empty fields.
+	Rule struct {
+		UserType string
+		Platform string
+		Route    string // Route is a prefix of gin route paths
+		Limit    Limit
+	}
+
+	// Rules is a set of rules. The most specific rule matching a request
+	// applies.
+	Rules []Rule
+)
+
+// PerMinute creates a limit of n requests per minute.
+func PerMinute(n int) Limit {
+	return Limit{Requests: n, Period: time.Minute}
+}
+
+// IsZero tells whether the limit is unset.
+func (l Limit) IsZero() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+// Rate returns the tokens added per second.
+func (l Limit) Rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Capacity returns the maximum tokens of the bucket.
+func (l Limit) Capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// String returns the limit in the format read by ParseLimit.
+func (l Limit) String() string {
+	result := fmt.Sprintf("%d/%s", l.Requests, l.Period)
+	if l.Burst > 0 {
+		result += fmt.Sprintf("+%d", l.Burst)
+	}
+	return result
+}
+
+// ParseLimit parses a limit in requests/period[+burst] format, eg: 120/1m or
+// 120/1m+20.
+func ParseLimit(value string) (Limit, error) {
+	var result Limit
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return result, fmt.Errorf("invalid limit %s", value)
+	}
+	requests, err := strconv.Atoi(parts[0])
+	if err != nil || requests <= 0 {
+		return result, fmt.Errorf("invalid requests in limit %s", value)
+	}
+
+	period := parts[1]
+	if i := strings.Index(period, "+"); i >= 0 {
+		burst, err := strconv.Atoi(period[i+1:])
+		if err != nil || burst <= 0 {
+			return result, fmt.Errorf("invalid burst in limit %s", value)
+		}
+		result.Burst = burst
+		period = period[:i]
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return result, fmt.Errorf("invalid period in limit %s", value)
+	}
+
+	result.Requests, result.Period = requests, d
+	return result, nil
+}
+
+// ParseRules parses comma separated rules in userType:platform:route=limit
+// format, eg: client:android:/v2/sessions=120/1m. Use * for any user type or
+// platform, and omit the route to match every route.
+func ParseRules(value string) (Rules, error) {
+	result := make(Rules, 0)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid rule %s", entry)
+		}
+		limit, err := ParseLimit(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.SplitN(parts[0], ":", 3)
+		if len(fields) < 2 {
+			return nil, errors.New("rules must have user type and platform")
+		}
+		rule := Rule{UserType: wildcard(fields[0]), Platform: wildcard(fields[1]), Limit: limit}
+		if len(fields) == 3 {
+			rule.Route = fields[2]
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+func wildcard(value string) string {
+	if value == Wildcard {
+		return ""
+	}
+	return value
+}
+
+// Matches tells whether the rule applies to the identity and route.
+func (r Rule) Matches(id Identity, route string) bool {
+	return (r.UserType == "" || r.UserType == id.UserType) &&
+		(r.Platform == "" || r.Platform == id.Platform) &&
+		strings.HasPrefix(route, r.Route)
+}
+
+// specificity orders matching rules. Longer routes win, then rules with
+// platform, then rules with user type.
+func (r Rule) specificity() int {
+	result := len(r.Route) * 4
+	if r.Platform != "" {
+		result += 2
+	}
+	if r.UserType != "" {
+		result++
+	}
+	return result
+}
+
+// Match returns the most specific rule for the identity and route.
+func (r Rules) Match(id Identity, route string) (Rule, bool) {
+	var result Rule
+	best := -1
+	for _, rule := range r {
+		if rule.Matches(id, route) && rule.specificity() > best {
+			result, best = rule, rule.specificity()
+		}
+	}
+	return result, best >= 0
+}
+
+// Merge returns the rules replaced or extended by the given ones.
+func (r Rules) Merge(other Rules) Rules {
+	result := append(Rules{}, r...)
+	for _, o := range other {
+		replaced := false
+		for i, rule := range result {
+			if rule.UserType == o.UserType && rule.Platform == o.Platform && rule.Route == o.Route {
+				result[i] = o
+				replaced = true
+			}
+		}
+		if !replaced {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// newResult computes the result of a take from the tokens left.
+func newResult(limit Limit, tokens float64, allowed bool) Result {
+	rate := limit.Rate()
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((limit.Capacity() - tokens) / rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
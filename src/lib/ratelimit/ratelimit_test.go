@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 60, Period: time.Minute, Burst: 2}
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if r, _ := store.Take("key", limit, now); !r.Allowed || r.Remaining != 1-i {
+			t.Fatalf("expected take %d to be allowed, got %+v", i, r)
+		}
+	}
+	r, _ := store.Take("key", limit, now)
+	if r.Allowed || r.RetryAfter != time.Second {
+		t.Errorf("expected denied take retrying after 1s, got %+v", r)
+	}
+	if r, _ = store.Take("other", limit, now); !r.Allowed {
+		t.Errorf("expected buckets to be independent")
+	}
+
+	// One token per second.
+	if r, _ = store.Take("key", limit, now.Add(time.Second)); !r.Allowed || r.Remaining != 0 {
+		t.Errorf("expected refilled token, got %+v", r)
+	}
+	if r, _ = store.Take("key", limit, now.Add(time.Hour)); r.Remaining != 1 || r.Reset != time.Second {
+		t.Errorf("expected bucket capped at burst, got %+v", r)
+	}
+}
+
+func TestRules(t *testing.T) {
+	rules, err := ParseRules("client:*=300/1m, client:web=120/1m, *:*:/v2/sessions=60/1m+10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseRules("client=1/1m"); err == nil {
+		t.Error("expected error for rule without platform")
+	}
+	if _, err := ParseLimit("10/never"); err == nil {
+		t.Error("expected error for invalid period")
+	}
+
+	tests := []struct {
+		id       Identity
+		route    string
+		expected string
+	}{
+		{Identity{UserType: "client", Platform: "android"}, "/v2/movies", "300/1m0s"},
+		{Identity{UserType: "client", Platform: "web"}, "/v2/movies", "120/1m0s"},
+		{Identity{UserType: "client", Platform: "web"}, "/v2/sessions", "60/1m0s+10"},
+	}
+	for _, test := range tests {
+		rule, ok := rules.Match(test.id, test.route)
+		if !ok || rule.Limit.String() != test.expected {
+			t.Errorf("expected %s for %+v %s, got %s", test.expected, test.id, test.route, rule.Limit)
+		}
+	}
+	if _, ok := rules.Match(Identity{UserType: "admin"}, "/v2/movies"); ok {
+		t.Error("expected no rule for admin")
+	}
+
+	merged := rules.Merge(Rules{{UserType: "client", Platform: "web", Limit: PerMinute(10)}})
+	if len(merged) != 3 || merged[1].Limit.Requests != 10 || rules[1].Limit.Requests != 120 {
+		t.Errorf("expected merge to replace rule in a copy, got %+v", merged)
+	}
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+)
+
+// pruneEvery is how many takes the memory store waits before removing full
+// buckets.
+const pruneEvery = 1000
+
+type (
+	// MemoryStore keeps buckets in memory. Buckets are not shared between
+	// instances, so it's meant for long running servers.
+	MemoryStore struct {
+		mu      sync.Mutex
+		buckets map[string]*bucket
+		takes   int
+	}
+
+	bucket struct {
+		tokens  float64
+		updated time.Time
+		full    time.Time // full is when the bucket is full again
+	}
+
+	// DataStore keeps buckets in the database, so every Lambda instance
+	// shares them.
+	DataStore struct {
+		data persistence.DataAccessLayer
+	}
+)
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}}
+}
+
+// Take implements Store.
+func (s *MemoryStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.takes++
+	if s.takes%pruneEvery == 0 {
+		s.prune(now)
+	}
+
+	capacity := limit.Capacity()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+
+	elapsed := math.Max(now.Sub(b.updated).Seconds(), 0)
+	b.tokens = math.Min(capacity, b.tokens+elapsed*limit.Rate())
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	result := newResult(limit, b.tokens, allowed)
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+
+// prune removes full buckets, which are the same as missing ones.
+func (s *MemoryStore) prune(now time.Time) {
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// NewDataStore creates a store backed by the given data access layer.
+func NewDataStore(data persistence.DataAccessLayer) *DataStore {
+	return &DataStore{data: data}
+}
+
+// Take implements Store.
+func (s *DataStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	b, err := s.data.TakeRateLimitToken(key, limit.Rate(), limit.Capacity(), now)
+	if err != nil {
+		return Result{}, err
+	}
+	return newResult(limit, b.Tokens, b.Allowed), nil
+}
@@ -0,0 +1,65 @@
+package tokens
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"sort"
+)
+
+type (
+	// JWK is the public part of a key in JSON Web Key format (RFC 7517).
+	JWK struct {
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		Kid string `json:"kid"`
+		Alg string `json:"alg"`
+		N   string `json:"n,omitempty"`   // RSA modulus
+		E   string `json:"e,omitempty"`   // RSA exponent
+		Crv string `json:"crv,omitempty"` // OKP curve
+		X   string `json:"x,omitempty"`   // OKP public key
+	}
+
+	// JWKS is a JSON Web Key Set, which services use to verify tokens.
+	JWKS struct {
+		Keys []JWK `json:"keys"`
+	}
+)
+
+// JWK returns the public part of the key.
+func (k *Key) JWK() JWK {
+	result := JWK{Use: "sig", Kid: k.ID, Alg: k.Algorithm}
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		result.Kty = "RSA"
+		result.N = encodeBase64(public.N.Bytes())
+		result.E = encodeBase64(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		result.Kty = "OKP"
+		result.Crv = "Ed25519"
+		result.X = encodeBase64(public)
+	}
+	return result
+}
+
+// JWKS returns the public keys of the set, newest first.
+func (s *KeySet) JWKS() JWKS {
+	keys := make([]*Key, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+
+	result := JWKS{Keys: make([]JWK, len(keys))}
+	for i, k := range keys {
+		result.Keys[i] = k.JWK()
+	}
+	return result
+}
+
+func encodeBase64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,301 @@
+// Package tokens signs and verifies JWT tokens with rotating asymmetric keys,
+// and creates the opaque refresh tokens exchanged for them.
+package tokens
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+)
+
+// Signing algorithms of keys.
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// rsaKeyBits is the size of generated RSA keys.
+const rsaKeyBits = 2048
+
+// encryptedKeyType is the PEM type of private keys encrypted with AES-GCM,
+// authenticated with the key ID.
+const encryptedKeyType = "ENCRYPTED SIGNING KEY"
+
+// ErrUnknownAlgorithm is returned for keys of unsupported algorithms.
+var ErrUnknownAlgorithm = errors.New("unknown signing algorithm")
+
+// SigningMethodEdDSA signs tokens with Ed25519 keys, which jwt-go doesn't
+// support.
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+type signingMethodEdDSA struct{}
+
+func init() {
+	jwt.RegisterSigningMethod(AlgorithmEdDSA, func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (m *signingMethodEdDSA) Alg() string {
+	return AlgorithmEdDSA
+}
+
+func (m *signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(public, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
+
+func (m *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(private, []byte(signingString))), nil
+}
+
+type (
+	// Key is a key pair signing tokens.
+	Key struct {
+		ID        string
+		Algorithm string
+		CreatedAt time.Time
+		ExpiresAt time.Time // ExpiresAt is when tokens signed with the key are rejected
+		private   crypto.Signer
+		public    crypto.PublicKey
+	}
+
+	// KeySet is the set of keys verifying tokens. New tokens are signed with
+	// the newest key.
+	KeySet struct {
+		keys    map[string]*Key
+		current *Key
+	}
+)
+
+// GenerateKey creates a key of the given algorithm, valid until now plus ttl.
+func GenerateKey(algorithm string, now time.Time, ttl time.Duration) (*Key, error) {
+	result := &Key{
+		ID:        NewID(),
+		Algorithm: algorithm,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	switch algorithm {
+	case AlgorithmRS256:
+		private, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		result.private, result.public = private, private.Public()
+	case AlgorithmEdDSA:
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		result.private, result.public = private, public
+	default:
+		return nil, ErrUnknownAlgorithm
+	}
+	return result, nil
+}
+
+// KeyFromModel decodes a stored key, decrypting its private key with the
+// given secret. Keys stored before encryption are in plain text.
+func KeyFromModel(m models.SigningKey, secret []byte) (*Key, error) {
+	block, _ := pem.Decode([]byte(m.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("invalid private key of %s", m.ID)
+	}
+	der := block.Bytes
+	if block.Type == encryptedKeyType {
+		var err error
+		if der, err = openPrivateKey(der, m.ID, secret); err != nil {
+			return nil, fmt.Errorf("failed to decrypt private key of %s: %v", m.ID, err)
+		}
+	}
+	private, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("invalid private key of %s", m.ID)
+	}
+
+	result := &Key{ID: m.ID, Algorithm: m.Algorithm, private: signer, public: signer.Public()}
+	if m.CreatedAt != nil {
+		result.CreatedAt = *m.CreatedAt
+	}
+	if m.ExpiresAt != nil {
+		result.ExpiresAt = *m.ExpiresAt
+	}
+	if result.Method() == nil {
+		return nil, ErrUnknownAlgorithm
+	}
+	return result, nil
+}
+
+// Model encodes the key to be stored, encrypting its private key with the
+// given secret. Without secret, the private key is stored in plain text.
+func (k *Key) Model(secret []byte) (models.SigningKey, error) {
+	private, err := x509.MarshalPKCS8PrivateKey(k.private)
+	if err != nil {
+		return models.SigningKey{}, err
+	}
+	privateType := "PRIVATE KEY"
+	if len(secret) > 0 {
+		if private, err = sealPrivateKey(private, k.ID, secret); err != nil {
+			return models.SigningKey{}, err
+		}
+		privateType = encryptedKeyType
+	}
+	public, err := x509.MarshalPKIXPublicKey(k.public)
+	if err != nil {
+		return models.SigningKey{}, err
+	}
+	createdAt, expiresAt := k.CreatedAt, k.ExpiresAt
+	return models.SigningKey{
+		ID:         k.ID,
+		Algorithm:  k.Algorithm,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: privateType, Bytes: private})),
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public})),
+		CreatedAt:  &createdAt,
+		ExpiresAt:  &expiresAt,
+	}, nil
+}
+
+// SecretKey derives the AES-256 key encrypting private keys from the given
+// secret, such as the one in the JWT_KEY_SECRET variable.
+func SecretKey(secret string) []byte {
+	if secret == "" {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
+
+// sealPrivateKey encrypts the DER encoded private key of the given key ID.
+// The nonce is prepended to the result.
+func sealPrivateKey(der []byte, id string, secret []byte) ([]byte, error) {
+	gcm, err := newGCM(secret)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, der, []byte(id)), nil
+}
+
+// openPrivateKey decrypts a private key encrypted by sealPrivateKey.
+func openPrivateKey(sealed []byte, id string, secret []byte) ([]byte, error) {
+	if len(secret) == 0 {
+		return nil, ErrMissingSecret
+	}
+	gcm, err := newGCM(secret)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("invalid encrypted key")
+	}
+	n := gcm.NonceSize()
+	return gcm.Open(nil, sealed[:n], sealed[n:], []byte(id))
+}
+
+func newGCM(secret []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Method returns the signing method of the key.
+func (k *Key) Method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		return SigningMethodEdDSA
+	}
+	return nil
+}
+
+// Sign signs the claims with the key, setting its ID in the kid header.
+func (k *Key) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.Method(), claims)
+	token.Header["kid"] = k.ID
+	return token.SignedString(k.private)
+}
+
+// NewKeySet creates a set of the given keys. Expired keys are ignored.
+func NewKeySet(keys []*Key, now time.Time) *KeySet {
+	result := &KeySet{keys: map[string]*Key{}}
+	for _, k := range keys {
+		if !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt) {
+			continue
+		}
+		result.keys[k.ID] = k
+		if result.current == nil || k.CreatedAt.After(result.current.CreatedAt) {
+			result.current = k
+		}
+	}
+	return result
+}
+
+// Current returns the key signing new tokens, nil for empty sets.
+func (s *KeySet) Current() *Key {
+	return s.current
+}
+
+// Get returns the key with the given ID.
+func (s *KeySet) Get(id string) (*Key, bool) {
+	k, ok := s.keys[id]
+	return k, ok
+}
+
+// Sign signs the claims with the current key.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if s.current == nil {
+		return "", errors.New("no signing key")
+	}
+	return s.current.Sign(claims)
+}
+
+// Keyfunc returns the public key verifying the token, found by its kid
+// header. Tokens must use the algorithm of their key.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+	k, ok := s.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s", id)
+	}
+	if token.Method.Alg() != k.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return k.public, nil
+}
@@ -0,0 +1,219 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrMissingSecret is returned when creating keys without the secret
+// encrypting them.
+var ErrMissingSecret = errors.New("missing key secret")
+
+const (
+	// DefaultRotation is how long keys sign new tokens before being replaced.
+	DefaultRotation = 30 * 24 * time.Hour
+
+	// reloadInterval is how long keys are kept in memory.
+	reloadInterval = 5 * time.Minute
+
+	// minReloadInterval is the minimum time between loads caused by tokens
+	// signed with unknown keys, which may have been created by other
+	// instances.
+	minReloadInterval = 10 * time.Second
+)
+
+type (
+	// Options configures a Manager.
+	Options struct {
+		Algorithm string        // Algorithm of new keys, defaults to RS256
+		Rotation  time.Duration // Rotation defaults to DefaultRotation
+		Secret    string        // Secret encrypting the stored private keys, required to create keys
+	}
+
+	// Manager keeps the signing keys in the database, so every instance
+	// shares them, and rotates them. Keys verify tokens for two rotations
+	// after created, so tokens signed just before a rotation stay valid.
+	// Private keys are stored encrypted with the secret.
+	Manager struct {
+		data     persistence.DataAccessLayer
+		opts     Options
+		secret   []byte
+		mu       sync.Mutex
+		keys     *KeySet
+		loadedAt time.Time
+	}
+)
+
+// NewManager creates a manager of the keys stored in the given data access
+// layer.
+func NewManager(data persistence.DataAccessLayer, opts Options) *Manager {
+	if opts.Algorithm == "" {
+		opts.Algorithm = AlgorithmRS256
+	}
+	if opts.Rotation == 0 {
+		opts.Rotation = DefaultRotation
+	}
+	return &Manager{data: data, opts: opts, secret: SecretKey(opts.Secret)}
+}
+
+// KeySet returns the current keys, creating a new one if the newest is due
+// for rotation.
+func (m *Manager) KeySet() (*KeySet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if m.keys == nil || now.Sub(m.loadedAt) >= reloadInterval {
+		if err := m.load(now); err != nil {
+			return nil, err
+		}
+	}
+	current := m.keys.Current()
+	if current == nil || current.Algorithm != m.opts.Algorithm || now.Sub(current.CreatedAt) >= m.opts.Rotation {
+		if _, err := m.rotate(now); err != nil {
+			return nil, err
+		}
+	}
+	return m.keys, nil
+}
+
+// Rotate creates a new key signing new tokens. Tokens signed with previous
+// keys stay valid until they expire.
+func (m *Manager) Rotate() (*Key, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if err := m.load(now); err != nil {
+		return nil, err
+	}
+	return m.rotate(now)
+}
+
+// Sign signs the claims with the current key.
+func (m *Manager) Sign(claims jwt.Claims) (string, error) {
+	keys, err := m.KeySet()
+	if err != nil {
+		return "", err
+	}
+	return keys.Sign(claims)
+}
+
+// Keyfunc returns the public key verifying the token. Keys are loaded again
+// when tokens are signed with unknown keys.
+func (m *Manager) Keyfunc(token *jwt.Token) (interface{}, error) {
+	keys, err := m.KeySet()
+	if err != nil {
+		return nil, err
+	}
+	id, _ := token.Header["kid"].(string)
+	if _, ok := keys.Get(id); !ok {
+		m.mu.Lock()
+		if now := time.Now(); now.Sub(m.loadedAt) >= minReloadInterval {
+			if err := m.load(now); err != nil {
+				m.mu.Unlock()
+				return nil, err
+			}
+		}
+		keys = m.keys
+		m.mu.Unlock()
+	}
+	return keys.Keyfunc(token)
+}
+
+func (m *Manager) load(now time.Time) error {
+	stored, err := m.data.GetSigningKeys(m.data.DefaultQuery().
+		AddCondition("expiresAt", bson.M{"$gt": now}).
+		SetLimit(-1))
+	if err != nil {
+		return err
+	}
+	keys := make([]*Key, 0, len(stored))
+	for _, s := range stored {
+		k, err := KeyFromModel(s, m.secret)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, k)
+	}
+	m.keys, m.loadedAt = NewKeySet(keys, now), now
+	return nil
+}
+
+// rotate creates a key replacing the current one. Only one key can replace
+// each key, so when instances rotate at the same time, the ones losing the
+// race use the key of the first one.
+func (m *Manager) rotate(now time.Time) (*Key, error) {
+	if len(m.secret) == 0 {
+		return nil, ErrMissingSecret
+	}
+	k, err := GenerateKey(m.opts.Algorithm, now, 2*m.opts.Rotation)
+	if err != nil {
+		return nil, err
+	}
+	model, err := k.Model(m.secret)
+	if err != nil {
+		return nil, err
+	}
+	model.Replaces = m.replaces(now)
+	inserted, err := m.data.InsertSigningKey(model)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.load(now); err != nil {
+		return nil, err
+	}
+	if !inserted {
+		if k = m.keys.Current(); k == nil {
+			return nil, errors.New("no signing key")
+		}
+	}
+	return k, nil
+}
+
+// replaces returns what new keys replace: the current key or, without keys,
+// the current rotation period, so instances starting together create a
+// single key.
+func (m *Manager) replaces(now time.Time) string {
+	if m.keys != nil {
+		if current := m.keys.Current(); current != nil {
+			return current.ID
+		}
+	}
+	return fmt.Sprintf("period:%d", now.Truncate(m.opts.Rotation).Unix())
+}
+
+// NewID creates a random identifier for keys and tokens.
+func NewID() string {
+	return randomHex(16)
+}
+
+// NewRefreshToken creates a random refresh token and the hash it's stored
+// by.
+func NewRefreshToken() (token, hash string) {
+	token = randomHex(32)
+	return token, HashToken(token)
+}
+
+// HashToken returns the hash opaque tokens are stored by.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// Reading from crypto/rand doesn't fail on supported platforms.
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,122 @@
+package tokens
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+func TestKeySet(t *testing.T) {
+	now := time.Now()
+	for _, alg := range []string{AlgorithmRS256, AlgorithmEdDSA} {
+		old, err := GenerateKey(alg, now.Add(-time.Hour), 2*time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expired, _ := GenerateKey(alg, now.Add(-2*time.Hour), time.Hour)
+
+		// Keys are stored encoded.
+		model, err := old.Model(SecretKey("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := KeyFromModel(model, SecretKey("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		current, _ := GenerateKey(alg, now, 2*time.Hour)
+
+		keys := NewKeySet([]*Key{decoded, expired, current}, now)
+		if keys.Current() != current {
+			t.Errorf("%s: expected newest key to be current", alg)
+		}
+		if _, ok := keys.Get(expired.ID); ok {
+			t.Errorf("%s: expected expired key to be ignored", alg)
+		}
+		if jwks := keys.JWKS(); len(jwks.Keys) != 2 || jwks.Keys[0].Kid != current.ID || jwks.Keys[0].Alg != alg {
+			t.Errorf("%s: unexpected JWKS %+v", alg, jwks)
+		}
+
+		// Tokens signed with previous keys are still valid.
+		for _, k := range []*Key{old, current} {
+			signed, err := k.Sign(&jwt.StandardClaims{Subject: "key", ExpiresAt: now.Add(time.Minute).Unix()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := jwt.ParseWithClaims(signed, &jwt.StandardClaims{}, keys.Keyfunc)
+			if err != nil || !token.Valid || token.Claims.(*jwt.StandardClaims).Subject != "key" {
+				t.Errorf("%s: expected valid token, got %v", alg, err)
+			}
+		}
+
+		signed, _ := expired.Sign(&jwt.StandardClaims{})
+		if _, err := jwt.Parse(signed, keys.Keyfunc); err == nil {
+			t.Errorf("%s: expected token of expired key to be invalid", alg)
+		}
+	}
+
+	// Tokens can't switch the algorithm of their key.
+	k, _ := GenerateKey(AlgorithmRS256, now, time.Hour)
+	keys := NewKeySet([]*Key{k}, now)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.StandardClaims{})
+	token.Header["kid"] = k.ID
+	signed, _ := token.SignedString([]byte("secret"))
+	if _, err := jwt.Parse(signed, keys.Keyfunc); err == nil {
+		t.Error("expected token with other algorithm to be invalid")
+	}
+}
+
+func TestKeyEncryption(t *testing.T) {
+	k, err := GenerateKey(AlgorithmEdDSA, time.Now(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := SecretKey("secret")
+
+	model, err := k.Model(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(model.PrivateKey, encryptedKeyType) {
+		t.Errorf("expected encrypted private key, got %s", model.PrivateKey)
+	}
+	if _, err := KeyFromModel(model, nil); !strings.Contains(fmt.Sprint(err), ErrMissingSecret.Error()) {
+		t.Errorf("expected missing secret error, got %v", err)
+	}
+	if _, err := KeyFromModel(model, SecretKey("other")); err == nil {
+		t.Error("expected key encrypted with other secret to be invalid")
+	}
+
+	// Keys are bound to their ID.
+	moved := model
+	moved.ID = NewID()
+	if _, err := KeyFromModel(moved, secret); err == nil {
+		t.Error("expected key stored with other ID to be invalid")
+	}
+
+	decoded, err := KeyFromModel(model, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, _ := k.Sign(&jwt.StandardClaims{})
+	if _, err := jwt.Parse(signed, NewKeySet([]*Key{decoded}, time.Now()).Keyfunc); err != nil {
+		t.Errorf("expected decrypted key to verify tokens, got %v", err)
+	}
+
+	// Keys stored before encryption are still read.
+	plain, _ := k.Model(nil)
+	if _, err := KeyFromModel(plain, secret); err != nil {
+		t.Errorf("expected plain key to be read, got %v", err)
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	token, hash := NewRefreshToken()
+	other, _ := NewRefreshToken()
+	if token == other || hash != HashToken(token) || hash == token {
+		t.Error("expected random tokens stored by hash")
+	}
+}
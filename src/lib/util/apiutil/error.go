@@ -12,7 +12,15 @@ import (
 
 // APIError ...
 type APIError struct {
-	Code    string `json:"code"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details []*APIErrorDetail `json:"details,omitempty"`
+}
+
+// APIErrorDetail names an invalid field of a request and why it is invalid.
+type APIErrorDetail struct {
+	Field   string `json:"field"`
+	In      string `json:"in"` // Either path, query, header or body
 	Message string `json:"message"`
 }
 
@@ -24,6 +32,9 @@ var (
 	apiInternalServerError     = NewAPIError("internal_server_error", "An internal server error occurred")
 	apiErrorProtectedResource  = NewAPIError("protected", "This resource is protected and cannot be deleted or modified")
 	apiErrorInvalidCredentials = NewAPIError("invalid_credentials", "Invalid credentials")
+	apiErrorTooManyRequests    = NewAPIError("rate_limited", "Too many requests, try again later")
+	apiErrorConflict           = NewAPIError("conflict", "Resource is in use or already exists")
+	apiErrorRequestTooLarge    = NewAPIError("request_too_large", "Request body is too large")
 )
 
 // HandleError main handler for errors in the API.
@@ -41,6 +41,18 @@ func SendBadRequest(c *gin.Context) {
 	c.Abort()
 }
 
+// SendValidationError is a helper for sending validation_failed error
+// responses with the invalid fields of the request.
+func SendValidationError(c *gin.Context, details []*APIErrorDetail) {
+	err := NewAPIError("validation_failed", "Request has invalid fields")
+	err.Details = details
+	c.SecureJSON(http.StatusBadRequest, &APIResponse{
+		Status: http.StatusBadRequest,
+		Error:  err,
+	})
+	c.Abort()
+}
+
 // SendUnauthorized is a helper for sending unauthorized error response.
 func SendUnauthorized(c *gin.Context) {
 	c.SecureJSON(http.StatusUnauthorized, &APIResponse{
@@ -76,3 +88,32 @@ func SendProtectedResource(c *gin.Context) {
 	})
 	c.Abort()
 }
+
+// SendTooManyRequests is a helper for sending rate_limited error responses.
+func SendTooManyRequests(c *gin.Context) {
+	c.SecureJSON(http.StatusTooManyRequests, &APIResponse{
+		Status: http.StatusTooManyRequests,
+		Error:  apiErrorTooManyRequests,
+	})
+	c.Abort()
+}
+
+// SendConflict is a helper for sending conflict error responses, eg: when
+// deleting resources other resources still depend on.
+func SendConflict(c *gin.Context) {
+	c.SecureJSON(http.StatusConflict, &APIResponse{
+		Status: http.StatusConflict,
+		Error:  apiErrorConflict,
+	})
+	c.Abort()
+}
+
+// SendRequestTooLarge is a helper for sending request_too_large error
+// responses.
+func SendRequestTooLarge(c *gin.Context) {
+	c.SecureJSON(http.StatusRequestEntityTooLarge, &APIResponse{
+		Status: http.StatusRequestEntityTooLarge,
+		Error:  apiErrorRequestTooLarge,
+	})
+	c.Abort()
+}
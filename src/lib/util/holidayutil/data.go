@@ -0,0 +1,106 @@
+package holidayutil
+
+import (
+	_ "embed" // holidays.json
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+)
+
+// holidaysData has the state and municipal holidays with a fixed date. Admins
+// can add the missing ones or remove wrong ones through the API.
+//
+//go:embed holidays.json
+var holidaysData []byte
+
+type (
+	// data is the format of the holidays data file.
+	data struct {
+		States map[models.State][]dataHoliday `json:"states"`
+		Cities []dataCity                     `json:"cities"`
+	}
+
+	dataCity struct {
+		State    models.State  `json:"state"`
+		City     string        `json:"city"`
+		Holidays []dataHoliday `json:"holidays"`
+	}
+
+	dataHoliday struct {
+		Date  string `json:"date"` // Date in MM-DD format
+		Name  string `json:"name"`
+		Since int    `json:"since,omitempty"`
+	}
+)
+
+var (
+	// states holidays with a fixed date.
+	states map[models.State][]fixed
+	// cities holidays with a fixed date, indexed by CityKey.
+	cities map[string][]fixed
+)
+
+func init() {
+	var err error
+	states, cities, err = parseData(holidaysData)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// parseData reads the state and municipal holidays of the data file.
+func parseData(b []byte) (map[models.State][]fixed, map[string][]fixed, error) {
+	var d data
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil, nil, err
+	}
+
+	byState := make(map[models.State][]fixed, len(d.States))
+	for state, holidays := range d.States {
+		if _, ok := models.GetState(string(state)); !ok {
+			return nil, nil, fmt.Errorf("holidays: unknown state %s", state)
+		}
+		f, err := parseHolidays(holidays)
+		if err != nil {
+			return nil, nil, err
+		}
+		byState[state] = f
+	}
+
+	byCity := make(map[string][]fixed, len(d.Cities))
+	for _, c := range d.Cities {
+		if _, ok := models.GetState(string(c.State)); !ok {
+			return nil, nil, fmt.Errorf("holidays: unknown state %s of %s", c.State, c.City)
+		}
+		f, err := parseHolidays(c.Holidays)
+		if err != nil {
+			return nil, nil, err
+		}
+		key := CityKey(c.State, c.City)
+		byCity[key] = append(byCity[key], f...)
+	}
+	return byState, byCity, nil
+}
+
+func parseHolidays(holidays []dataHoliday) ([]fixed, error) {
+	result := make([]fixed, 0, len(holidays))
+	for _, h := range holidays {
+		// Parsed in a leap year so February 29 is valid.
+		date, err := time.Parse(DateFormat, "2000-"+h.Date)
+		if err != nil {
+			return nil, fmt.Errorf("holidays: invalid date of %s: %v", h.Name, err)
+		}
+		if h.Name == "" {
+			return nil, fmt.Errorf("holidays: missing name of %s", h.Date)
+		}
+		result = append(result, fixed{
+			Month: date.Month(),
+			Day:   date.Day(),
+			Name:  h.Name,
+			Since: h.Since,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,225 @@
+package holidayutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/stringutil"
+)
+
+// DateFormat is the format of holiday dates.
+const DateFormat = "2006-01-02"
+
+type (
+	// Holiday is a day without work in a city.
+	Holiday struct {
+		Date  string       `json:"date"`            // Date in YYYY-MM-DD format
+		Name  string       `json:"name"`            // Name of the holiday in portuguese
+		Scope string       `json:"scope"`           // Scope is national, state or municipal
+		State models.State `json:"state,omitempty"` // State of state and municipal holidays
+		City  string       `json:"city,omitempty"`  // City of municipal holidays
+	}
+
+	// Calendar knows which dates are holidays in each city.
+	Calendar struct {
+		overrides []models.Holiday
+	}
+
+	// fixed is a holiday celebrated every year in the same day.
+	fixed struct {
+		Month time.Month
+		Day   int
+		Name  string
+		Since int // Since is the first year of the holiday, zero if unknown
+	}
+)
+
+// national holidays with a fixed date.
+var national = []fixed{
+	{time.January, 1, "Confraternização Universal", 0},
+	{time.April, 21, "Tiradentes", 0},
+	{time.May, 1, "Dia do Trabalho", 0},
+	{time.September, 7, "Independência do Brasil", 0},
+	{time.October, 12, "Nossa Senhora Aparecida", 0},
+	{time.November, 2, "Finados", 0},
+	{time.November, 15, "Proclamação da República", 0},
+	{time.November, 20, "Dia Nacional de Zumbi e da Consciência Negra", 2024},
+	{time.December, 25, "Natal", 0},
+}
+
+// NewCalendar creates a calendar with the given admin overrides.
+func NewCalendar(overrides []models.Holiday) *Calendar {
+	return &Calendar{overrides: overrides}
+}
+
+// Easter returns the Easter Sunday of the given year.
+func Easter(year int) time.Time {
+	// Anonymous Gregorian algorithm.
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// National returns the national holidays of the given year, including
+// Easter and the ones that depend on it.
+func National(year int) []Holiday {
+	result := make([]Holiday, 0, len(national)+6)
+	for _, f := range national {
+		if f.Since > year {
+			continue
+		}
+		result = append(result, f.holiday(year, models.HolidayNational))
+	}
+
+	easter := Easter(year)
+	movable := []struct {
+		days int
+		name string
+	}{
+		{-48, "Carnaval"},
+		{-47, "Carnaval"},
+		{-2, "Sexta-feira Santa"},
+		{0, "Páscoa"},
+		{60, "Corpus Christi"},
+	}
+	for _, m := range movable {
+		result = append(result, Holiday{
+			Date:  easter.AddDate(0, 0, m.days).Format(DateFormat),
+			Name:  m.name,
+			Scope: models.HolidayNational,
+		})
+	}
+	sortHolidays(result)
+	return result
+}
+
+// Year returns every holiday of the given year in the city.
+func (c *Calendar) Year(year int, city *models.City) []Holiday {
+	result := National(year)
+	if city != nil {
+		for _, f := range states[city.State] {
+			if f.Since > year {
+				continue
+			}
+			h := f.holiday(year, models.HolidayState)
+			h.State = city.State
+			result = append(result, h)
+		}
+		for _, f := range cities[CityKey(city.State, city.Name)] {
+			if f.Since > year {
+				continue
+			}
+			h := f.holiday(year, models.HolidayMunicipal)
+			h.State = city.State
+			h.City = city.Name
+			result = append(result, h)
+		}
+	}
+
+	prefix := fmt.Sprintf("%04d-", year)
+	for _, o := range c.overrides {
+		if !strings.HasPrefix(o.Date, prefix) || !applies(o, city) {
+			continue
+		}
+		if o.Removed {
+			result = removeHoliday(result, o.Date, o.Scope())
+			continue
+		}
+		h := Holiday{
+			Date:  o.Date,
+			Name:  o.Name,
+			Scope: o.Scope(),
+			State: o.State,
+		}
+		if h.Scope == models.HolidayMunicipal && city != nil {
+			h.State = city.State
+			h.City = city.Name
+		}
+		result = append(result, h)
+	}
+
+	sortHolidays(result)
+	return result
+}
+
+// On returns the holidays of the day of t in the city. The day is taken in
+// the location of t.
+func (c *Calendar) On(t time.Time, city *models.City) []Holiday {
+	date := t.Format(DateFormat)
+	result := make([]Holiday, 0)
+	for _, h := range c.Year(t.Year(), city) {
+		if h.Date == date {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+// IsHoliday checks whether the day of t is a holiday in the city.
+func (c *Calendar) IsHoliday(t time.Time, city *models.City) bool {
+	return len(c.On(t, city)) > 0
+}
+
+// CityKey is the key of a city in the municipal holidays data.
+func CityKey(state models.State, name string) string {
+	return string(state) + "/" + normalize(name)
+}
+
+// applies checks whether the override covers the city. Overrides without
+// state or city are national.
+func applies(o models.Holiday, city *models.City) bool {
+	if !o.CityID.IsZero() {
+		return city != nil && city.ID == o.CityID
+	}
+	if o.State != "" {
+		return city != nil && city.State == o.State
+	}
+	return true
+}
+
+// removeHoliday removes the holidays of the date with the given scope. A
+// state override doesn't remove a national holiday on the same date.
+func removeHoliday(holidays []Holiday, date, scope string) []Holiday {
+	result := holidays[:0]
+	for _, h := range holidays {
+		if h.Date != date || h.Scope != scope {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+func sortHolidays(holidays []Holiday) {
+	sort.SliceStable(holidays, func(i, j int) bool {
+		return holidays[i].Date < holidays[j].Date
+	})
+}
+
+func (f fixed) holiday(year int, scope string) Holiday {
+	return Holiday{
+		Date:  time.Date(year, f.Month, f.Day, 0, 0, 0, 0, time.UTC).Format(DateFormat),
+		Name:  f.Name,
+		Scope: scope,
+	}
+}
+
+// normalize lowercases the name and removes accents and spaces.
+func normalize(name string) string {
+	result, _ := stringutil.OnlyAlphaLetters(name)
+	return result
+}
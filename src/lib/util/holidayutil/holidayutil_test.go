@@ -0,0 +1,116 @@
+package holidayutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 12, 0, 0, 0, time.UTC)
+}
+
+func TestEaster(t *testing.T) {
+	expected := map[int]string{
+		2019: "2019-04-21",
+		2020: "2020-04-12",
+		2024: "2024-03-31",
+		2025: "2025-04-20",
+	}
+	for year, date := range expected {
+		assert.Equal(t, date, Easter(year).Format(DateFormat))
+	}
+}
+
+func TestNational(t *testing.T) {
+	names := map[string]string{}
+	for _, h := range National(2020) {
+		names[h.Date] = h.Name
+		assert.Equal(t, models.HolidayNational, h.Scope)
+	}
+	assert.Equal(t, "Carnaval", names["2020-02-24"])
+	assert.Equal(t, "Carnaval", names["2020-02-25"])
+	assert.Equal(t, "Sexta-feira Santa", names["2020-04-10"])
+	assert.Equal(t, "Páscoa", names["2020-04-12"])
+	assert.Equal(t, "Corpus Christi", names["2020-06-11"])
+}
+
+func TestCalendar(t *testing.T) {
+	city := &models.City{ID: primitive.NewObjectID(), State: models.SP, Name: "São Paulo"}
+
+	c := NewCalendar(nil)
+	cases := []struct {
+		date     time.Time
+		expected bool
+	}{
+		{day(2020, time.February, 25), true},  // Carnaval
+		{day(2020, time.June, 11), true},      // Corpus Christi
+		{day(2020, time.July, 9), true},       // Revolução Constitucionalista
+		{day(2020, time.January, 25), true},   // Aniversário de São Paulo
+		{day(2020, time.November, 20), false}, // Consciência Negra before 2024
+		{day(2024, time.November, 20), true},
+		{day(2020, time.March, 3), false},
+	}
+	for _, test := range cases {
+		assert.Equal(t, test.expected, c.IsHoliday(test.date, city), test.date.Format(DateFormat))
+	}
+
+	// State holidays apply only to their state.
+	assert.False(t, c.IsHoliday(day(2020, time.July, 9), &models.City{State: models.RJ, Name: "Rio de Janeiro"}))
+
+	c = NewCalendar([]models.Holiday{
+		{Date: "2020-03-03", Name: "Feriado municipal", CityID: city.ID},
+		{Date: "2020-07-09", State: models.SP, Removed: true},
+	})
+	assert.True(t, c.IsHoliday(day(2020, time.March, 3), city))
+	assert.False(t, c.IsHoliday(day(2020, time.March, 3), &models.City{State: models.SP, Name: "Campinas"}))
+	assert.False(t, c.IsHoliday(day(2020, time.July, 9), city))
+}
+
+func TestCalendarRemovedScope(t *testing.T) {
+	city := &models.City{ID: primitive.NewObjectID(), State: models.AL, Name: "Maceió"}
+
+	// A state holiday on the same date of a national one.
+	c := NewCalendar([]models.Holiday{
+		{Date: "2020-09-07", Name: "Feriado estadual", State: models.AL},
+		{Date: "2020-09-07", State: models.AL, Removed: true},
+	})
+	holidays := c.On(day(2020, time.September, 7), city)
+	if assert.Len(t, holidays, 1) {
+		assert.Equal(t, models.HolidayNational, holidays[0].Scope)
+	}
+
+	// National removals don't remove the ones of the state.
+	c = NewCalendar([]models.Holiday{
+		{Date: "2020-06-24", Removed: true},
+	})
+	holidays = c.On(day(2020, time.June, 24), city)
+	if assert.Len(t, holidays, 1) {
+		assert.Equal(t, "São João", holidays[0].Name)
+		assert.Equal(t, models.HolidayState, holidays[0].Scope)
+	}
+
+	c = NewCalendar([]models.Holiday{
+		{Date: "2020-06-24", State: models.AL, Removed: true},
+	})
+	assert.False(t, c.IsHoliday(day(2020, time.June, 24), city))
+}
+
+func TestParseData(t *testing.T) {
+	s, c, err := parseData(holidaysData)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, s[models.SP])
+	assert.NotEmpty(t, c[CityKey(models.SP, "São Paulo")])
+
+	_, _, err = parseData([]byte(`{"states": {"XX": [{"date": "01-01", "name": "Feriado"}]}}`))
+	assert.Error(t, err)
+
+	_, _, err = parseData([]byte(`{"states": {"SP": [{"date": "13-01", "name": "Feriado"}]}}`))
+	assert.Error(t, err)
+
+	_, _, err = parseData([]byte(`{"cities": [{"state": "SP", "city": "Campinas", "holidays": [{"date": "01-01"}]}]}`))
+	assert.Error(t, err)
+}
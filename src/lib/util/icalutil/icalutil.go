@@ -0,0 +1,205 @@
+package icalutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ContentType is the media type of iCalendar documents.
+const ContentType = "text/calendar; charset=utf-8"
+
+// ProductID identifies us as the creator of the calendars.
+const ProductID = "-//Amenic//Amenic API//PT"
+
+const (
+	dateTimeFormat    = "20060102T150405"
+	utcDateTimeFormat = "20060102T150405Z"
+	maxLineLength     = 75
+)
+
+type (
+	// Calendar is an iCalendar document with events in a single time zone.
+	Calendar struct {
+		Name     string
+		Location *time.Location // Location is the time zone of the events
+		Refresh  time.Duration  // Refresh is how often subscribers should update the calendar
+		Events   []Event
+	}
+
+	// Event is a VEVENT of a calendar.
+	Event struct {
+		UID         string // UID must be stable so updates replace the event
+		Summary     string
+		Description string
+		Location    string
+		URL         string
+		Start       time.Time
+		End         time.Time
+		Updated     time.Time
+	}
+)
+
+// Write writes the calendar in iCalendar format to w.
+func (c *Calendar) Write(w io.Writer) error {
+	loc := c.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	b := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeLine(b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", ProductID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", Escape(c.Name))
+	}
+	line("X-WR-TIMEZONE", loc.String())
+	if c.Refresh > 0 {
+		d := duration(c.Refresh)
+		line("REFRESH-INTERVAL;VALUE=DURATION", d)
+		line("X-PUBLISHED-TTL", d)
+	}
+
+	if loc != time.UTC {
+		writeTimeZone(b, loc, c.Events)
+	}
+
+	stamp := time.Now().UTC().Format(utcDateTimeFormat)
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", stamp)
+		writeLine(b, dateTime("DTSTART", e.Start, loc))
+		writeLine(b, dateTime("DTEND", e.End, loc))
+		line("SUMMARY", Escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", Escape(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", Escape(e.Location))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		if !e.Updated.IsZero() {
+			line("LAST-MODIFIED", e.Updated.UTC().Format(utcDateTimeFormat))
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return b.Flush()
+}
+
+// String returns the calendar in iCalendar format.
+func (c *Calendar) String() string {
+	sb := strings.Builder{}
+	c.Write(&sb)
+	return sb.String()
+}
+
+// Escape escapes a text value.
+func Escape(value string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return r.Replace(value)
+}
+
+// dateTime returns the property name and value of t in the given location,
+// eg: DTSTART;TZID=America/Sao_Paulo:20191101T190000.
+func dateTime(name string, t time.Time, loc *time.Location) string {
+	if loc == time.UTC {
+		return name + ":" + t.UTC().Format(utcDateTimeFormat)
+	}
+	return fmt.Sprintf("%s;TZID=%s:%s", name, loc.String(), t.In(loc).Format(dateTimeFormat))
+}
+
+// writeTimeZone writes the VTIMEZONE of loc. Every offset the events use is
+// written as a standard time observance starting at the first event with it.
+func writeTimeZone(b *bufio.Writer, loc *time.Location, events []Event) {
+	type observance struct {
+		name   string
+		offset int
+		start  time.Time
+	}
+
+	observances := make([]observance, 0, 1)
+	seen := map[int]bool{}
+	for _, e := range events {
+		name, offset := e.Start.In(loc).Zone()
+		if seen[offset] {
+			continue
+		}
+		seen[offset] = true
+		observances = append(observances, observance{name, offset, e.Start.In(loc)})
+	}
+	if len(observances) == 0 {
+		name, offset := time.Now().In(loc).Zone()
+		observances = append(observances, observance{name, offset, time.Date(1970, time.January, 1, 0, 0, 0, 0, loc)})
+	}
+
+	writeLine(b, "BEGIN:VTIMEZONE")
+	writeLine(b, "TZID:"+loc.String())
+	for _, o := range observances {
+		writeLine(b, "BEGIN:STANDARD")
+		writeLine(b, "DTSTART:"+o.start.Format(dateTimeFormat))
+		writeLine(b, "TZOFFSETFROM:"+offset(o.offset))
+		writeLine(b, "TZOFFSETTO:"+offset(o.offset))
+		writeLine(b, "TZNAME:"+o.name)
+		writeLine(b, "END:STANDARD")
+	}
+	writeLine(b, "END:VTIMEZONE")
+}
+
+// writeLine writes a content line folded at 75 octets, as required by
+// RFC 5545, without breaking UTF-8 sequences.
+func writeLine(b *bufio.Writer, line string) {
+	// Continuation lines start with a space, which counts in their length.
+	max := maxLineLength
+	for len(line) > max {
+		i := max
+		for i > 0 && !isRuneStart(line[i]) {
+			i--
+		}
+		b.WriteString(line[:i])
+		b.WriteString("\r\n ")
+		line = line[i:]
+		max = maxLineLength - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func offset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, (seconds%3600)/60)
+}
+
+func duration(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("PT%dH", d/time.Hour)
+	}
+	return fmt.Sprintf("PT%dM", d/time.Minute)
+}
@@ -0,0 +1,60 @@
+package icalutil
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	loc, err := time.LoadLocation("America/Manaus")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	start := time.Date(2019, time.November, 1, 23, 30, 0, 0, time.UTC)
+	c := Calendar{
+		Name:     "Cinemais Manaus",
+		Location: loc,
+		Refresh:  time.Hour,
+		Events: []Event{
+			{
+				UID:      "session@amenic",
+				Summary:  "Coringa (Legendado, 2D)",
+				Location: "Av. Djalma Batista, 482; Manaus",
+				Start:    start,
+				End:      start.Add(122 * time.Minute),
+			},
+		},
+	}
+	result := c.String()
+
+	expected := []string{
+		"BEGIN:VCALENDAR\r\n",
+		"TZID:America/Manaus\r\n",
+		"TZOFFSETTO:-0400\r\n",
+		"DTSTART;TZID=America/Manaus:20191101T193000\r\n",
+		"DTEND;TZID=America/Manaus:20191101T213200\r\n",
+		"SUMMARY:Coringa (Legendado\\, 2D)\r\n",
+		"LOCATION:Av. Djalma Batista\\, 482\\; Manaus\r\n",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H\r\n",
+		"END:VCALENDAR\r\n",
+	}
+	for _, e := range expected {
+		if !strings.Contains(result, e) {
+			t.Fatalf("Expected calendar to contain %q but got:\n%s", e, result)
+		}
+	}
+}
+
+func TestWriteLineFolding(t *testing.T) {
+	sb := strings.Builder{}
+	c := Calendar{Events: []Event{{UID: "1", Summary: strings.Repeat("ação ", 40)}}}
+	c.Write(&sb)
+
+	for _, line := range strings.Split(sb.String(), "\r\n") {
+		if len(line) > maxLineLength {
+			t.Fatalf("Expected lines of at most %d octets but got %d: %q", maxLineLength, len(line), line)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package priceutil
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/holidayutil"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrNoPrice is returned when none of the theater prices applies to the session.
+var ErrNoPrice = errors.New("no price applies to the session")
+
+// Day kinds a price can match.
+const (
+	DayWeekday = "weekday"
+	DayHoliday = "holiday"
+	DayPreview = "preview"
+	DayAny     = "any"
+)
+
+type (
+	// Session is what the resolver needs to know about a session.
+	Session struct {
+		StartTime  time.Time    // StartTime in the time zone of the theater city
+		Format     string       // Format of the session, eg: 2D or 3D
+		Attributes []string     // Attributes of the room, eg: VIP
+		Preview    bool         // Preview indicates the session is before the movie release
+		City       *models.City // City of the theater, used to check holidays
+	}
+
+	// Resolution is the price of a session and why it applies.
+	Resolution struct {
+		PriceID    primitive.ObjectID `json:"priceId"`
+		Label      string             `json:"label"`
+		Full       float32            `json:"full"`
+		Half       float32            `json:"half"`
+		Day        string             `json:"day"`                // Day is the kind of day the price matched
+		Holidays   []string           `json:"holidays,omitempty"` // Holidays in the day of the session
+		Preview    bool               `json:"preview"`
+		Attributes []string           `json:"attributes"` // Attributes of the price the session has
+		Rule       string             `json:"rule"`       // Rule explains why the price applies
+	}
+
+	candidate struct {
+		price *models.Price
+		day   string
+	}
+)
+
+// Resolve finds which of the theater prices applies to the session. Prices
+// with more matching attributes win, then the ones matching holidays or
+// previews over regular weekdays.
+func Resolve(prices []models.Price, s Session, calendar *holidayutil.Calendar) (*Resolution, error) {
+	var holidays []string
+	if calendar != nil {
+		for _, h := range calendar.On(s.StartTime, s.City) {
+			holidays = append(holidays, h.Name)
+		}
+	}
+	holiday := len(holidays) > 0
+
+	attrs := map[string]bool{}
+	if s.Format != "" {
+		attrs[normalize(s.Format)] = true
+	}
+	for _, a := range s.Attributes {
+		attrs[normalize(a)] = true
+	}
+
+	candidates := make([]candidate, 0)
+	for i := range prices {
+		p := &prices[i]
+		if (holiday && p.ExceptHolidays) || (s.Preview && p.ExceptPreviews) {
+			continue
+		}
+		if !hasAttributes(attrs, p.Attributes) {
+			continue
+		}
+		day := matchDay(p, s.StartTime.Weekday(), holiday, s.Preview)
+		if day == "" {
+			continue
+		}
+		candidates = append(candidates, candidate{price: p, day: day})
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoPrice
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if len(a.price.Attributes) != len(b.price.Attributes) {
+			return len(a.price.Attributes) > len(b.price.Attributes)
+		}
+		if dayPriority(a.day) != dayPriority(b.day) {
+			return dayPriority(a.day) > dayPriority(b.day)
+		}
+		// Ties don't depend on the order of the prices.
+		if a.price.Weight != b.price.Weight {
+			return a.price.Weight > b.price.Weight
+		}
+		return a.price.ID.Hex() < b.price.ID.Hex()
+	})
+
+	best := candidates[0]
+	return &Resolution{
+		PriceID:    best.price.ID,
+		Label:      best.price.Label,
+		Full:       best.price.Full,
+		Half:       best.price.Half,
+		Day:        best.day,
+		Holidays:   holidays,
+		Preview:    s.Preview,
+		Attributes: best.price.Attributes,
+		Rule:       explain(best, s.StartTime.Weekday(), holidays),
+	}, nil
+}
+
+func matchDay(p *models.Price, weekday time.Weekday, holiday, preview bool) string {
+	if holiday && p.IncludingHolidays {
+		return DayHoliday
+	}
+	if preview && p.IncludingPreviews {
+		return DayPreview
+	}
+	if len(p.Weekdays) == 0 && !p.IncludingHolidays && !p.IncludingPreviews {
+		return DayAny
+	}
+	for _, w := range p.Weekdays {
+		if w == weekday {
+			return DayWeekday
+		}
+	}
+	return ""
+}
+
+func dayPriority(day string) int {
+	switch day {
+	case DayHoliday, DayPreview:
+		return 2
+	case DayWeekday:
+		return 1
+	}
+	return 0
+}
+
+func hasAttributes(attrs map[string]bool, required []string) bool {
+	for _, a := range required {
+		if !attrs[normalize(a)] {
+			return false
+		}
+	}
+	return true
+}
+
+func explain(c candidate, weekday time.Weekday, holidays []string) string {
+	var day string
+	switch c.day {
+	case DayHoliday:
+		day = fmt.Sprintf("applies on holidays (%s)", strings.Join(holidays, ", "))
+	case DayPreview:
+		day = "applies on previews"
+	case DayWeekday:
+		day = fmt.Sprintf("applies on %s", weekday)
+	default:
+		day = "applies on any day"
+	}
+
+	result := fmt.Sprintf("'%s' %s", c.price.Label, day)
+	if len(c.price.Attributes) > 0 {
+		result += fmt.Sprintf(" and matches %s", strings.Join(c.price.Attributes, ", "))
+	}
+	return result
+}
+
+func normalize(attr string) string {
+	return strings.ToLower(strings.TrimSpace(attr))
+}
@@ -0,0 +1,68 @@
+package priceutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/holidayutil"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestResolve(t *testing.T) {
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday}
+	weekend := []time.Weekday{time.Friday, time.Saturday, time.Sunday}
+	prices := []models.Price{
+		{Label: "2D", Full: 20, Half: 10, Weekdays: weekdays, Attributes: []string{"2D"}},
+		{Label: "2D Fim de semana", Full: 24, Half: 12, Weekdays: weekend, IncludingHolidays: true, Attributes: []string{"2D"}},
+		{Label: "3D", Full: 28, Half: 14, Weekdays: append(weekdays, weekend...), ExceptPreviews: true, Attributes: []string{"3D"}},
+		{Label: "2D VIP", Full: 40, Half: 20, Weekdays: append(weekdays, weekend...), Attributes: []string{"2D", "VIP"}},
+	}
+	calendar := holidayutil.NewCalendar(nil)
+	city := &models.City{State: models.SP, Name: "São Paulo"}
+
+	tests := []struct {
+		session  Session
+		expected string
+		day      string
+		err      error
+	}{
+		// Tuesday
+		{Session{StartTime: time.Date(2020, time.March, 3, 20, 0, 0, 0, time.UTC), Format: "2D", City: city}, "2D", DayWeekday, nil},
+		// Saturday
+		{Session{StartTime: time.Date(2020, time.March, 7, 20, 0, 0, 0, time.UTC), Format: "2D", City: city}, "2D Fim de semana", DayWeekday, nil},
+		// Carnaval on a Tuesday
+		{Session{StartTime: time.Date(2020, time.February, 25, 20, 0, 0, 0, time.UTC), Format: "2D", City: city}, "2D Fim de semana", DayHoliday, nil},
+		{Session{StartTime: time.Date(2020, time.March, 3, 20, 0, 0, 0, time.UTC), Format: "2D", Attributes: []string{"vip"}, City: city}, "2D VIP", DayWeekday, nil},
+		{Session{StartTime: time.Date(2020, time.March, 3, 20, 0, 0, 0, time.UTC), Format: "3D", City: city}, "3D", DayWeekday, nil},
+		{Session{StartTime: time.Date(2020, time.March, 3, 20, 0, 0, 0, time.UTC), Format: "3D", Preview: true, City: city}, "", "", ErrNoPrice},
+	}
+	for _, test := range tests {
+		actual, err := Resolve(prices, test.session, calendar)
+		if err != test.err {
+			t.Fatalf("Expected error %v but got %v", test.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		if actual.Label != test.expected || actual.Day != test.day {
+			t.Fatalf("Expected %s (%s) but got %s (%s)", test.expected, test.day, actual.Label, actual.Day)
+		}
+	}
+
+	// Ties are broken by weight and then by ID, whatever the order.
+	first, second := primitive.NewObjectID(), primitive.NewObjectID()
+	tied := []models.Price{
+		{ID: second, Label: "Second", Full: 20, Weight: 1, Attributes: []string{"2D"}},
+		{ID: first, Label: "First", Full: 22, Weight: 1, Attributes: []string{"2D"}},
+		{ID: primitive.NewObjectID(), Label: "Light", Full: 18, Attributes: []string{"2D"}},
+	}
+	session := Session{StartTime: time.Date(2020, time.March, 3, 20, 0, 0, 0, time.UTC), Format: "2D", City: city}
+	for i := 0; i < 2; i++ {
+		actual, err := Resolve(tied, session, calendar)
+		if err != nil || actual.PriceID != first {
+			t.Fatalf("Expected First but got %v (%v)", actual, err)
+		}
+		tied[0], tied[1] = tied[1], tied[0]
+	}
+}
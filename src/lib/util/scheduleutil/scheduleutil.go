@@ -37,11 +37,11 @@ func GetWeekPeriodID(now *time.Time) string {
 	return ""
 }
 
-// GetWeekPeriod returns current period of movie screening for the given time
+// GetWeekPeriod returns current period of movie screening for the given time.
+// Days are computed in the location of the given time.
 func GetWeekPeriod(t *time.Time) *Period {
 	if t == nil {
-		loc, _ := time.LoadLocation("America/Sao_Paulo")
-		now := time.Now().In(loc)
+		now := timeutil.NowIn(timeutil.DefaultLocation())
 		t = &now
 	}
 
@@ -57,6 +57,16 @@ func GetWeekPeriod(t *time.Time) *Period {
 	return &Period{Start: s, End: e}
 }
 
+// GetWeekPeriodIn returns the period of movie screening of t in the given
+// location.
+func GetWeekPeriodIn(t time.Time, loc *time.Location) *Period {
+	if loc == nil {
+		loc = timeutil.DefaultLocation()
+	}
+	t = t.In(loc)
+	return GetWeekPeriod(&t)
+}
+
 // DaysUntilNextWednesday calculates how many days we are to next wednesday
 func DaysUntilNextWednesday(now *time.Time) int {
 	result := -1
@@ -1,13 +1,22 @@
 package scraperutil
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/robfig/cron"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 const (
+	// ProviderCinemais is the provider of Cinemais theaters.
+	ProviderCinemais = "cinemais"
+
+	// ProviderIbicinemas is the provider of Ibicinemas theaters.
+	ProviderIbicinemas = "ibicinemas"
+
 	// TypeNowPlaying indicates the scraper is getting Now Playing movies data
 	TypeNowPlaying = "now_playing"
 
@@ -30,10 +39,192 @@ const (
 	RunResultNotFound = "not_found"
 
 	// RunResultTimeout indicates the run encountered a timeout error during its execution.
-	// TODO: detect this!
 	RunResultTimeout = "server_timeout"
+
+	// RunResultNetworkError indicates the provider couldn't be reached.
+	RunResultNetworkError = "network_error"
+
+	// RunResultHTTPError indicates the provider responded with an unexpected HTTP status.
+	RunResultHTTPError = "http_error"
+
+	// RunResultParseError indicates the provider's response couldn't be parsed.
+	RunResultParseError = "parse_error"
+
+	// RunResultTheaterNotFound indicates the theater of the scraper couldn't be found.
+	RunResultTheaterNotFound = "theater_not_found"
+
+	// RunResultError indicates the run failed with an unknown error.
+	RunResultError = "error"
+
+	// RunResultQuarantined indicates the run broke a sanity rule and its data is waiting for review.
+	RunResultQuarantined = "quarantined"
+
+	// QuarantinePending indicates the quarantined run wasn't reviewed yet.
+	QuarantinePending = "pending"
+
+	// QuarantineApproved indicates an admin approved the quarantined data and it was persisted.
+	QuarantineApproved = "approved"
+
+	// QuarantineDiscarded indicates an admin discarded the quarantined data.
+	QuarantineDiscarded = "discarded"
 )
 
+// Providers maps each registered provider to the host its data is scraped
+// from.
+var Providers = map[string]string{
+	ProviderCinemais:   "www.cinemais.com.br",
+	ProviderIbicinemas: "www.ibicinemas.com.br",
+}
+
+// Types lists every scraper type.
+var Types = []string{TypeNowPlaying, TypeUpcoming, TypeSchedule, TypePrices}
+
+// ErrInvalidWindow is returned when a run window has a malformed start or end.
+var ErrInvalidWindow = errors.New("run window start and end must be in HH:MM format")
+
+// IsProvider checks whether the given provider is registered.
+func IsProvider(name string) bool {
+	_, ok := Providers[name]
+	return ok
+}
+
+// IsType checks whether the given scraper type exists.
+func IsType(scraperType string) bool {
+	for _, t := range Types {
+		if t == scraperType {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSchedule checks the cron specs and run windows of the given scraper.
+func ValidateSchedule(scraper models.Scraper) error {
+	for _, spec := range scraper.Cron {
+		if _, err := cron.Parse(spec); err != nil {
+			return fmt.Errorf("invalid cron spec '%s': %s", spec, err.Error())
+		}
+	}
+	for _, w := range scraper.Windows {
+		if _, err := ParseClock(w.Start); err != nil {
+			return err
+		}
+		if _, err := ParseClock(w.End); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseClock converts a HH:MM string to minutes since midnight.
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, ErrInvalidWindow
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// FreshnessSLA returns how long the data of the given scraper type is
+// considered fresh after its last successful run.
+func FreshnessSLA(scraperType string) time.Duration {
+	switch scraperType {
+	case TypeSchedule, TypeNowPlaying:
+		return 24 * time.Hour
+	case TypeUpcoming:
+		return 48 * time.Hour
+	case TypePrices:
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// IsSuccessful checks whether the given result code means the run data is up to date.
+func IsSuccessful(resultCode string) bool {
+	return resultCode == RunResultSuccess || resultCode == RunResultNotModified
+}
+
+// DefaultRules returns the sanity rules applied to the given scraper type
+// when the scraper doesn't define its own.
+func DefaultRules(scraperType string) models.ScraperRules {
+	switch scraperType {
+	case TypeSchedule:
+		return models.ScraperRules{
+			MaxDropPercent:        50,
+			RequiredFields:        []string{"theaterId", "startTime", "movieSlugs"},
+			MaxOutsideWeekPercent: 10,
+		}
+	case TypePrices:
+		return models.ScraperRules{
+			MaxDropPercent: 50,
+			RequiredFields: []string{"theaterId", "full"},
+		}
+	case TypeNowPlaying, TypeUpcoming:
+		return models.ScraperRules{
+			MaxDropPercent: 70,
+			RequiredFields: []string{"title"},
+		}
+	}
+	return models.ScraperRules{}
+}
+
+// GetRules returns the sanity rules for the given scraper.
+func GetRules(scraper *models.Scraper) models.ScraperRules {
+	if scraper.Rules != nil {
+		return *scraper.Rules
+	}
+	return DefaultRules(scraper.Type)
+}
+
+// DefaultRetryPolicy returns the retry policy used by scrapers that don't define their own.
+func DefaultRetryPolicy() models.RetryPolicy {
+	return models.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     60,
+		MaxBackoff:  15 * 60,
+		RetryOn: []string{
+			RunResultTimeout,
+			RunResultNetworkError,
+			RunResultHTTPError,
+		},
+	}
+}
+
+// GetRetryPolicy returns the retry policy for the given scraper.
+func GetRetryPolicy(scraper *models.Scraper) models.RetryPolicy {
+	if scraper.Retry != nil {
+		return *scraper.Retry
+	}
+	return DefaultRetryPolicy()
+}
+
+// ShouldRetry checks whether the given failed run should be attempted again.
+func ShouldRetry(policy models.RetryPolicy, run *models.ScraperRun) bool {
+	if run == nil || run.Attempt >= policy.MaxAttempts {
+		return false
+	}
+	for _, code := range policy.RetryOn {
+		if code == run.ResultCode {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryDelay calculates how long to wait before the next attempt using an
+// exponential backoff. The first retry waits Backoff seconds.
+func RetryDelay(policy models.RetryPolicy, attempt int) time.Duration {
+	delay := time.Duration(policy.Backoff) * time.Second
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+	}
+	max := time.Duration(policy.MaxBackoff) * time.Second
+	if max > 0 && delay > max {
+		delay = max
+	}
+	return delay
+}
+
 // NewScraperRun creates an instance of ScraperRun for a given theater and op
 func NewScraperRun(operation string) *models.ScraperRun {
 	if operation == "" {
@@ -44,6 +235,7 @@ func NewScraperRun(operation string) *models.ScraperRun {
 	result = &models.ScraperRun{
 		ID:        primitive.NewObjectID(),
 		StartTime: &start,
+		Attempt:   1,
 	}
 	return result
 }
@@ -36,7 +36,7 @@ func IsToday(t *time.Time) bool {
 // FloorToDay round down the given time to start of the day.
 func FloorToDay(t *time.Time, loc *time.Location) time.Time {
 	if loc == nil {
-		loc, _ = time.LoadLocation("America/Sao_Paulo")
+		loc = DefaultLocation()
 	}
 
 	if t == nil {
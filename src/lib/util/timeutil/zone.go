@@ -0,0 +1,86 @@
+package timeutil
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultTimeZone is used when a city has no time zone.
+const DefaultTimeZone = "America/Sao_Paulo"
+
+// DateFormat is the layout of dates received in query strings.
+const DateFormat = "2006-01-02"
+
+var (
+	locationsMu sync.RWMutex
+	locations   = map[string]*time.Location{}
+)
+
+// LoadLocation returns the location with the given name. Empty or unknown
+// names fall back to DefaultTimeZone and then to UTC if the system has no
+// time zone database.
+func LoadLocation(name string) *time.Location {
+	if name == "" {
+		name = DefaultTimeZone
+	}
+
+	locationsMu.RLock()
+	loc, ok := locations[name]
+	locationsMu.RUnlock()
+	if ok {
+		return loc
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		if name != DefaultTimeZone {
+			return LoadLocation(DefaultTimeZone)
+		}
+		loc = time.UTC
+	}
+
+	locationsMu.Lock()
+	locations[name] = loc
+	locationsMu.Unlock()
+	return loc
+}
+
+// DefaultLocation returns the location of DefaultTimeZone.
+func DefaultLocation() *time.Location {
+	return LoadLocation(DefaultTimeZone)
+}
+
+// NowIn returns the current time in the given location.
+func NowIn(loc *time.Location) time.Time {
+	if loc == nil {
+		loc = DefaultLocation()
+	}
+	return time.Now().In(loc)
+}
+
+// DateIn returns the day of t in the given location as a YYYYMMDD number.
+func DateIn(t time.Time, loc *time.Location) int {
+	if loc == nil {
+		loc = DefaultLocation()
+	}
+	y, m, d := t.In(loc).Date()
+	result, _ := strconv.Atoi(fmt.Sprintf("%d%02d%02d", y, int(m), d))
+	return result
+}
+
+// ParseDateIn parses a YYYY-MM-DD date as the start of that day in the given
+// location.
+func ParseDateIn(value string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = DefaultLocation()
+	}
+	return time.ParseInLocation(DateFormat, value, loc)
+}
+
+// EndOfDay returns the last instant of the day of t in its location.
+func EndOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location()).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
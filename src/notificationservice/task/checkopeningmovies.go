@@ -5,6 +5,7 @@ import (
 
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
 	"github.com/dsbezerra/amenic-lambda/src/notificationservice/fcm"
 	"github.com/dsbezerra/amenic-lambda/src/notificationservice/weekreleases"
 	"github.com/pkg/errors"
@@ -49,11 +50,7 @@ func GetWeekReleases(data persistence.DataAccessLayer) ([]models.Movie, error) {
 
 	// Now we check which movies releases in the current now playing week with a limit of 2 days
 	// because we don't want to generate a notification after the weekend.
-	loc, err := time.LoadLocation("America/Sao_Paulo")
-	if err != nil {
-		loc = time.UTC
-	}
-	now := time.Now().In(loc)
+	now := timeutil.NowIn(timeutil.DefaultLocation())
 
 	for _, m := range playing {
 		if m.Hidden || m.ReleaseDate == nil {
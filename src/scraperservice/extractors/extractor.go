@@ -19,7 +19,8 @@ type Extractor interface {
 	// TODO: DOC
 	Execute() error
 
-	// TODO: DOC
+	// Complete persists the extracted data of the run. It never uses the
+	// provider, so it also works for extractors created without one.
 	Complete()
 }
 
@@ -2,24 +2,19 @@ package extractors
 
 import (
 	"errors"
-	"fmt"
-	"log"
-	"os"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/agnivade/levenshtein"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/movieutil"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/matcher"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/metadata"
 	"github.com/dsbezerra/amenic-lambda/src/scraperservice/provider"
-	tmdb "github.com/ryanbradynd05/go-tmdb"
 	"github.com/sirupsen/logrus"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -31,14 +26,15 @@ type (
 		Type     string
 		Run      *models.ScraperRun
 		Logger   *logrus.Entry
-		TMDb     *tmdb.TMDb
+		Metadata metadata.MetadataSource
+		Matcher  *matcher.Matcher
 		Movies   []models.Movie
 	}
 )
 
 // Errors
-var errTmdbSearchMatch = errors.New("tmdb search match error")
-var errTmdbMovieNotFound = errors.New("tmdb search movie not found")
+var errMetadataSearchMatch = errors.New("metadata search match error")
+var errMetadataMovieNotFound = errors.New("metadata search movie not found")
 
 // NewMovieExtractor creates a new extractor configured to insert movies in the Movie collection
 func NewMovieExtractor(data persistence.DataAccessLayer, p provider.Provider, s *models.ScraperRun) *MovieExtractor {
@@ -48,16 +44,10 @@ func NewMovieExtractor(data persistence.DataAccessLayer, p provider.Provider, s
 		Data:     data,
 		Provider: p,
 		Run:      s,
+		Metadata: metadata.Default(),
+		Matcher:  matcher.New(data),
+		Movies:   s.Movies,
 	}
-
-	apiKey := os.Getenv("TMDB_API_KEY")
-	if apiKey == "" {
-		// TODO: Don't fetch metadata if this is missing
-		log.Fatal("missing TMDB_API_KEY env variable")
-	}
-
-	result.TMDb = tmdb.Init(tmdb.Config{APIKey: apiKey})
-
 	return result
 }
 
@@ -74,6 +64,7 @@ func (e *MovieExtractor) Execute() error {
 		err = errors.New("MovieExtractor supports only now_playing and upcoming types")
 	}
 	e.Movies = movies
+	e.Run.Movies = movies
 	return err
 }
 
@@ -106,38 +97,29 @@ func (e *MovieExtractor) ExtractedCount() int {
 	return len(e.Movies)
 }
 
-// Configuration used to resolve image paths
-var tmdbConfig *tmdb.Configuration
-
-// FillMovieMetadata calls TMDb Api to get metadata and fill our Movie
+// FillMovieMetadata asks the metadata source for metadata and fill our Movie
 // TODO: Add claquete?
 func (e *MovieExtractor) FillMovieMetadata(movie *models.Movie) error {
-	if tmdbConfig == nil {
-		e.Logger.Debugln("Configuration is missing. Getting configurations...")
-		config, err := e.TMDb.GetConfiguration()
-		if err != nil {
-			return err
-		}
-		tmdbConfig = config
-		e.Logger.Debugln("Configuration successfully retrieved!")
+	if e.Metadata == nil || e.Metadata.Name() == metadata.SourceNone {
+		e.Logger.Debugf("No metadata source configured. Skipping metadata for movie '%s'", movie.Title)
+		return metadata.ErrNoSource
 	}
 
 	y := time.Now().Year()
-	// Title is the query we will use to search for a movie in TMDB (or our own database later)
+	// Title is the query we will use to search for a movie in the metadata source
 	q := strings.Replace(strings.ToLower(movie.Title), "o filme", "", -1)
 	if strings.HasSuffix(q, "-") {
 		q = strings.TrimSpace(q[0 : len(q)-1])
 	}
 
-	// Search in TMDb
-	tmdbMovie, err := e.SearchTMDBMovie(movie, q, y)
+	result, err := e.SearchMovieMetadata(movie, q, y)
 	if err != nil {
 		switch err {
 		default:
 			return err
-		case errTmdbMovieNotFound, errTmdbMovieNotFound:
+		case errMetadataSearchMatch, errMetadataMovieNotFound:
 			// Check in previous year
-			tmdbMovie, err = e.SearchTMDBMovie(movie, q, y-1)
+			result, err = e.SearchMovieMetadata(movie, q, y-1)
 			if err != nil {
 				return err
 			}
@@ -147,126 +129,109 @@ func (e *MovieExtractor) FillMovieMetadata(movie *models.Movie) error {
 			// We may consider querying without year parameter to find these movies.
 		}
 	}
-	return e.ApplyTMDBMovieInfo(*tmdbMovie, movie)
+	return e.ApplyMovieMetadata(*result, movie)
 }
 
-// SearchTMDBMovie searches for a movie with the given year and query
-func (e *MovieExtractor) SearchTMDBMovie(movie *models.Movie, query string, year int) (*tmdb.MovieShort, error) {
-	var result *tmdb.MovieShort
-	var err error
+// SearchMovieMetadata searches for a movie with the given year and query
+func (e *MovieExtractor) SearchMovieMetadata(movie *models.Movie, query string, year int) (*metadata.SearchResult, error) {
+	var result *metadata.SearchResult
 
 	e.Logger.Infof("Searching metadata for movie '%s' with query '%s'", movie.Title, query)
-	results, err := e.TMDb.SearchMovie(query, map[string]string{
-		"language": "pt-BR",
-		"region":   "BR",
-		"year":     strconv.Itoa(year),
-	})
-	if err == nil {
-		if results.TotalResults > 0 {
-			slug := movieutil.GenerateSlug(query, false)
-			for _, movieShort := range results.Results {
-				// NOTE(diego): Ridiculous simple match algorithm below.
-				// We should get more info about movie from theater's page, for example, the cast and genres to
-				// improve this ridiculous match algorithm, *BUT* for now it works, mostly because we just care
-				// about current playing and upcoming movies.
-				movieShortSlug := movieutil.GenerateSlug(movieShort.Title, false)
-				// If title matches, we say that we have found the correct movie.
-				if slug == movieShortSlug {
-					result = &movieShort
-					break
-				} else {
-					// NOTE(diego): If our slug is at the index of the other slug the title may contain additional title
-					// and it is probably a match...
-					// TODO(diego): Change this if we get any wrong matches or report as pending so we can manually check
-					// later if it a match or not....
-					if strings.Index(slug, movieShortSlug) == 0 {
-						result = &movieShort
-						break
-					} else {
-						// Otherwise, try to match by using Damerau-Levensthein edit distance.
-						// If we get an edit distance greater than 4 for any word in the title,
-						// we report as not a match.
-						if levenshtein.ComputeDistance(slug, movieShortSlug) <= 4 {
-							// TODO: Report as pending so we can manually check later if it a match or not.
-							result = &movieShort
-							break
-						}
-					}
-				}
-			}
-
-			if result == nil || result.ID == 0 {
-				err = errTmdbSearchMatch
-			}
+	results, err := e.Metadata.Search(query, year)
+	if err != nil {
+		e.Logger.Errorln(err.Error())
+		return nil, err
+	}
+	if len(results) == 0 {
+		e.Logger.Warnf("Nothing was found with query '%s'!", query)
+		return nil, errMetadataMovieNotFound
+	}
 
-		} else {
-			e.Logger.Warnf("Nothing was found with query '%s'!", query)
-			err = errTmdbMovieNotFound
+	searched := *movie
+	searched.Title = query
+	var best float64
+	for i, r := range results {
+		candidate := models.Movie{
+			Title:         r.Title,
+			OriginalTitle: r.OriginalTitle,
+			ReleaseDate:   r.ReleaseDate,
+		}
+		score := matcher.Score(movie, &candidate).Score
+		// The query may be cleaner than the title, eg: without 'O Filme'.
+		if c := matcher.Score(&searched, &candidate); c.Score > score {
+			score = c.Score
+		}
+		if score >= e.Matcher.Threshold && score > best {
+			best = score
+			result = &results[i]
 		}
-	} else {
-		e.Logger.Errorln(err.Error())
 	}
 
-	return result, err
+	if result == nil || result.ID == 0 {
+		return nil, errMetadataSearchMatch
+	}
+	return result, nil
 }
 
-// ApplyTMDBMovieInfo applies movie information obtained from themoviedb to our scraped movie
-func (e *MovieExtractor) ApplyTMDBMovieInfo(movieShort tmdb.MovieShort, movie *models.Movie) error {
-	movieInfo, err := e.TMDb.GetMovieInfo(movieShort.ID, map[string]string{
-		"language":           "pt-BR",
-		"append_to_response": "videos,releases",
-	})
+// ApplyMovieMetadata applies movie information obtained from the metadata source to our scraped movie
+func (e *MovieExtractor) ApplyMovieMetadata(found metadata.SearchResult, movie *models.Movie) error {
+	details, err := e.Metadata.Details(found.ID)
 	if err != nil {
 		return err
 	}
 
 	e.Logger.Infoln("Updating movie metadata...")
 
-	// Update with metadata from TMDb
-	movie.TmdbID = movieInfo.ID
-	movie.ImdbID = movieInfo.ImdbID
-	movie.OriginalTitle = movieInfo.OriginalTitle
-	movie.Title = movieInfo.Title
-	if movie.Synopsis == "" && movieInfo.Overview != "" {
-		movie.Synopsis = movieInfo.Overview
+	movie.TmdbID = details.TmdbID
+	movie.ImdbID = details.ImdbID
+	movie.OriginalTitle = details.OriginalTitle
+	movie.Title = details.Title
+	if movie.Synopsis == "" && details.Overview != "" {
+		movie.Synopsis = details.Overview
 	}
-	if movieInfo.BackdropPath != "" {
+	if details.BackdropPath != "" {
 		// TODO: Ensure this image exists
-		movie.BackdropURL = tmdbConfig.Images.SecureBaseURL + "w1400_and_h450_bestv2" + movieInfo.BackdropPath
+		if url, err := e.Metadata.ImageURL(details.BackdropPath, "w1400_and_h450_bestv2"); err == nil {
+			movie.BackdropURL = url
+		}
 	}
-	if movieInfo.PosterPath == "" && movieShort.PosterPath != "" {
-		movieInfo.PosterPath = movieShort.PosterPath
+	if details.PosterPath == "" && found.PosterPath != "" {
+		details.PosterPath = found.PosterPath
 	}
-	if movieInfo.PosterPath != "" && movie.PosterURL == "" {
+	if details.PosterPath != "" && movie.PosterURL == "" {
 		// TODO: Ensure this image exists
-		movie.PosterURL = tmdbConfig.Images.SecureBaseURL + "w300_and_h450_bestv2" + movieInfo.PosterPath
-	}
-	if movieInfo.Runtime != 0 && movie.Runtime == 0 {
-		movie.Runtime = int(movieInfo.Runtime)
+		if url, err := e.Metadata.ImageURL(details.PosterPath, "w300_and_h450_bestv2"); err == nil {
+			movie.PosterURL = url
+		}
 	}
-	movie.Genres = make([]string, len(movieInfo.Genres))
-	for i, genre := range movieInfo.Genres {
-		movie.Genres[i] = genre.Name
+	if details.Runtime != 0 && movie.Runtime == 0 {
+		movie.Runtime = details.Runtime
 	}
+	movie.Genres = make([]string, len(details.Genres))
+	copy(movie.Genres, details.Genres)
 	sort.Strings(movie.Genres)
 
 	if movie.ReleaseDate == nil || movie.ReleaseDate.IsZero() {
-		for _, country := range movieInfo.Releases.Countries {
-			if country.Iso3166_1 == "BR" {
-				loc, _ := time.LoadLocation("America/Sao_Paulo")
-				t, _ := time.ParseInLocation("2006-01-02", country.ReleaseDate, loc)
-				movie.ReleaseDate = &t
+		releases, err := e.Metadata.ReleaseDates(found.ID)
+		if err != nil {
+			e.Logger.Warnf("Couldn't get release dates of movie '%s': %s", movie.Title, err.Error())
+		}
+		for _, r := range releases {
+			if r.Country == "BR" {
+				movie.ReleaseDate = r.Date
 				break
 			}
 		}
 	}
 
-	if movieInfo.Videos != nil {
-		for _, video := range movieInfo.Videos.Results {
-			if video.Type == "Trailer" && video.Site == "YouTube" {
-				movie.Trailer = video.Key
-				break
-			}
+	videos, err := e.Metadata.Videos(found.ID)
+	if err != nil {
+		e.Logger.Warnf("Couldn't get videos of movie '%s': %s", movie.Title, err.Error())
+	}
+	for _, video := range videos {
+		if video.Type == "Trailer" && video.Site == "YouTube" {
+			movie.Trailer = video.Key
+			break
 		}
 	}
 
@@ -286,7 +251,7 @@ func (e *MovieExtractor) UpsertMovie(movie *models.Movie) {
 	var result *models.Movie
 
 	// TODO: Improve those logging messages
-	found, result := FindMovieMatch(e.Data, movie)
+	result, candidates, found := e.Matcher.Match(movie)
 	if found {
 		// NOTE(diego):
 		// Only updating movies if the provider is VeloxTickets/Claquete/Cinemais because
@@ -311,6 +276,13 @@ func (e *MovieExtractor) UpsertMovie(movie *models.Movie) {
 
 		// Let's use whatever we found as the correct data for now on.
 		*movie = u
+	} else if len(candidates) > 0 {
+		// Not sure if it's one of the candidates, let an admin decide.
+		e.Logger.Warnf("Movie '%s' has uncertain matches. Reporting for review...", movie.Title)
+		err := e.Matcher.Report(movie, candidates)
+		if err != nil {
+			e.Logger.Error(err.Error())
+		}
 	} else {
 		e.Logger.Infof("Movie '%s' is not in database.", movie.Title)
 
@@ -327,83 +299,12 @@ func (e *MovieExtractor) UpsertMovie(movie *models.Movie) {
 	}
 }
 
-// FindMovieMatch ...
+// FindMovieMatch finds the movie in the database only if the match is
+// confident enough.
 func FindMovieMatch(data persistence.DataAccessLayer, movie *models.Movie) (bool, *models.Movie) {
-	var result *models.Movie
-	// Try to find it by Claquete ID
-	if movie.ClaqueteID != 0 {
-		result, _ := data.FindMovie(data.DefaultQuery().AddCondition("claqueteId", movie.ClaqueteID))
-		if result != nil {
-			return true, result
-		}
-	}
-
-	// Try to find it by TMDb ID
-	if movie.TmdbID != 0 {
-		result, _ := data.FindMovie(data.DefaultQuery().AddCondition("tmdbId", movie.TmdbID))
-		if result != nil {
-			return true, result
-		}
-	}
-
-	// Try to find it by IMDb ID
-	if movie.ImdbID != "" {
-		result, _ := data.FindMovie(data.DefaultQuery().AddCondition("imdbId", movie.ImdbID))
-		if result != nil {
-			return true, result
-		}
-	}
-
-	// Try to find it by slug with year
-	result, err := data.FindMovie(data.DefaultQuery().AddCondition("slug.year", movie.Slugs.Year))
-	if result != nil {
-		return true, result
-	}
-
-	// Try to find it by slug without year
-	result, err = data.FindMovie(data.DefaultQuery().AddCondition("slug.noDashes", movie.Slugs.NoDashes))
-	if result != nil {
-		return true, result
-	}
-
-	// Query the films that has the closest title sorted by matching score
-	query := data.DefaultQuery().
-		AddCondition("$text", bson.M{"$search": movie.Title}).
-		SetFields(bson.M{
-			"score": bson.M{"$meta": "textScore"},
-		}).
-		SetSort("$testScore:score")
-	possible, err := data.GetMovies(query)
-	if err != nil {
-		fmt.Println(err)
+	result, _, ok := matcher.New(data).Match(movie)
+	if !ok {
 		return false, nil
 	}
-	// TODO: Improve the match algorithm here if necessary
-	for _, m := range possible {
-		distance := levenshtein.ComputeDistance(m.Title, movie.Title)
-		if distance <= 2 {
-			result = &m
-			break
-		}
-	}
-	// NOTE: Two loops because we want to make sure we check every possible
-	// movie before checking if part of title exists.
-	for _, m := range possible {
-		// If possible result title exists in scraped movie and it's
-		// right in the beginning of string we treat as a match.
-		//
-		// NOTE: This can be wrong, but this code will only be executed if we don't
-		// have the Claquete ID or couldn't find the movie in TMDb.
-		//
-		// Was added to make possible to match movies missing optional text in title.
-		// eg: 'Lino - O Filme' missing '- O Filme' is still the same movie.
-		if strings.Index(movie.Title, m.Title) == 0 {
-			result = &m
-			break
-		}
-	}
-	if result != nil {
-		return true, result
-	}
-	return false, nil
+	return true, result
 }
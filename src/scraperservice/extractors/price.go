@@ -24,6 +24,7 @@ func NewPriceExtractor(data persistence.DataAccessLayer, p provider.Provider, s
 		Logger:   logrus.WithFields(logrus.Fields{"extractor": "Price"}),
 		Run:      s,
 		Provider: p,
+		Prices:   s.Prices,
 	}
 	return result
 }
@@ -35,6 +36,7 @@ func (e *PriceExtractor) Execute() error {
 		return err
 	}
 	e.Prices = result
+	e.Run.Prices = result
 	return nil
 }
 
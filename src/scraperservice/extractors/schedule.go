@@ -9,6 +9,7 @@ import (
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/scheduleutil"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/matcher"
 	"github.com/dsbezerra/amenic-lambda/src/scraperservice/provider"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -19,6 +20,7 @@ type (
 		Provider provider.Provider
 		Run      *models.ScraperRun
 		Sessions []models.Session
+		DryRun   bool // DryRun doesn't report uncertain matches for review
 	}
 )
 
@@ -28,6 +30,7 @@ func NewScheduleExtractor(data persistence.DataAccessLayer, p provider.Provider,
 		Data:     data,
 		Run:      s,
 		Provider: p,
+		Sessions: s.Sessions,
 	}
 	return result
 }
@@ -39,21 +42,43 @@ func (e *ScheduleExtractor) Execute() error {
 		return err
 	}
 
-	movies := LookupMovies(e.Data, result)
-	m := map[string]models.Movie{}
-	for _, movie := range movies {
-		m[movie.Slugs.NoDashes] = movie
-	}
-
+	movies := LookupMovies(e.Data, result, e.DryRun)
+	unmatched := make([]models.UnmatchedMovie, 0)
+	index := map[string]int{}
 	for i := range result {
-		v, ok := m[result[i].MovieSlugs.NoDashes]
+		key := result[i].MovieSlugs.NoDashes
+		// Matching replaces the slugs, keep the scraped one.
+		result[i].ScrapedSlug = key
+		movie := result[i].Movie
+		v, ok := movies[key]
 		if ok {
 			result[i].MovieID = v.ID
 			result[i].MovieSlugs = v.Slugs
+			movie = &v
+		} else if key != "" {
+			// Keep track of sessions without movie so an admin can bind them.
+			j, seen := index[key]
+			if !seen {
+				title := key
+				if result[i].Movie != nil && result[i].Movie.Title != "" {
+					title = result[i].Movie.Title
+				}
+				j = len(unmatched)
+				index[key] = j
+				unmatched = append(unmatched, models.UnmatchedMovie{
+					Title: title,
+					Slug:  key,
+				})
+			}
+			unmatched[j].Sessions++
 		}
+		result[i].Type = models.ClassifySession(&result[i], movie)
 		result[i].Movie = nil
+		result[i].Key = result[i].NaturalKey()
 	}
+	e.Run.Unmatched = unmatched
 	e.Sessions = result
+	e.Run.Sessions = result
 	return nil
 }
 
@@ -62,22 +87,7 @@ func (e *ScheduleExtractor) Complete() {
 
 	switch e.Run.ResultCode {
 	case scraperutil.RunResultSuccess:
-		now := time.Now()
-		start := scheduleutil.GetWeekPeriod(&now).Start
-		query := e.Data.DefaultQuery().AddCondition("$and", []bson.D{
-			bson.D{
-				{Key: "theaterId", Value: e.Run.Scraper.TheaterID},
-				{Key: "startTime", Value: bson.D{
-					{Key: "$gte", Value: start},
-				}},
-			},
-		})
-		_, err := e.Data.DeleteSessions(query)
-		if err != nil {
-			// TODO: Handle
-			log.Fatal(err)
-		}
-		err = e.Data.InsertSessions(e.Sessions...)
+		err := e.MergeSessions()
 		if err != nil {
 			// TODO: Handle
 			log.Fatal(err)
@@ -89,6 +99,56 @@ func (e *ScheduleExtractor) Complete() {
 	}
 }
 
+// MergeSessions upserts the extracted sessions, merging them with the ones
+// other providers reported for the theater. Sessions this provider no longer
+// reports are removed once no provider reports them, which also replaces
+// sessions stored with keys of a previous format.
+func (e *ScheduleExtractor) MergeSessions() error {
+	provider := e.Run.Scraper.Provider
+	keys := make([]string, len(e.Sessions))
+	for i := range e.Sessions {
+		e.Sessions[i].Key = e.Sessions[i].NaturalKey()
+		keys[i] = e.Sessions[i].Key
+	}
+
+	_, err := e.Data.UpsertSessions(provider, e.Sessions...)
+	if err != nil {
+		return err
+	}
+
+	start := WeekStart(e.Run, time.Now())
+	theater := func() persistence.Query {
+		return e.Data.DefaultQuery().
+			AddCondition("theaterId", e.Run.Scraper.TheaterID).
+			AddCondition("startTime", bson.M{"$gte": start})
+	}
+
+	_, err = e.Data.UpdateSessions(theater().
+		AddCondition("providers", provider).
+		AddCondition("key", bson.M{"$nin": keys}),
+		bson.M{"$pull": bson.M{"providers": provider}})
+	if err != nil {
+		return err
+	}
+
+	// Sessions inserted before sessions had a key have no providers either.
+	_, err = e.Data.DeleteSessions(theater().
+		AddCondition("providers.0", bson.M{"$exists": false}))
+	return err
+}
+
+// WeekStart returns the start of the screening week whose sessions the run
+// replaces, in the time zone of the theater.
+func WeekStart(run *models.ScraperRun, now time.Time) time.Time {
+	loc := timeutil.DefaultLocation()
+	if run.Scraper.Theater != nil && run.Scraper.Theater.City != nil {
+		loc = run.Scraper.Theater.City.Location()
+	} else if len(run.Sessions) > 0 {
+		loc = run.Sessions[0].Location()
+	}
+	return scheduleutil.GetWeekPeriodIn(now, loc).Start
+}
+
 // ExtractedHash TODO
 func (e *ScheduleExtractor) ExtractedHash() string {
 	return GetExtractedHash(e.Sessions)
@@ -99,19 +159,22 @@ func (e *ScheduleExtractor) ExtractedCount() int {
 	return len(e.Sessions)
 }
 
-// LookupMovies ...
+// PairSlugMovie ...
 type PairSlugMovie struct {
 	Slugs models.Slugs
 	Movie models.Movie
 }
 
-func LookupMovies(data persistence.DataAccessLayer, sessions []models.Session) []models.Movie {
+// LookupMovies finds the movies of the given sessions. The result is indexed
+// by the scraped movie slug without dashes. Uncertain matches are reported for
+// review unless it's a dry run.
+func LookupMovies(data persistence.DataAccessLayer, sessions []models.Session, dryRun bool) map[string]models.Movie {
 	defer timeutil.TimeTrack(time.Now(), "LookupMovies")
 
 	claquete := []int{}
 	slugs := []string{}
 
-	movies := make([]models.Movie, 0)
+	result := map[string]models.Movie{}
 
 	seen := map[string]PairSlugMovie{}
 	for _, s := range sessions {
@@ -135,42 +198,54 @@ func LookupMovies(data persistence.DataAccessLayer, sessions []models.Session) [
 		}
 	}
 
+	byClaquete := map[int]models.Movie{}
 	if len(claquete) > 0 {
 		// @Refactor support other databases.
 		m, err := data.GetMovies(data.DefaultQuery().AddCondition("claqueteId", bson.M{"$in": claquete}))
 		if err == nil {
-			movies = append(movies, m...)
+			for _, movie := range m {
+				byClaquete[movie.ClaqueteID] = movie
+			}
 		}
 	}
 
+	bySlug := map[string]models.Movie{}
 	if len(slugs) > 0 {
 		// @Refactor support other databases.
 		m, err := data.GetMovies(data.DefaultQuery().AddCondition("slugs.noDashes", bson.M{"$in": slugs}))
 		if err == nil {
-			movies = append(movies, m...)
+			for _, movie := range m {
+				bySlug[movie.Slugs.NoDashes] = movie
+			}
 		}
 	}
 
-	// @Improve this check
-	if len(movies) == len(seen) {
-		return movies
-	}
-
-	// We still need to find movies
-	f := map[string]models.Movie{}
-	for _, m := range movies {
-		f[m.Slugs.NoDashes] = m
-	}
-
+	m := matcher.New(data)
 	for k, p := range seen {
-		_, ok := f[k]
-		if !ok {
-			found, movie := FindMovieMatch(data, &p.Movie)
-			if found {
-				movies = append(movies, *movie)
+		if movie, ok := byClaquete[p.Movie.ClaqueteID]; ok && p.Movie.ClaqueteID != 0 {
+			result[k] = movie
+			continue
+		}
+		if movie, ok := bySlug[k]; ok {
+			result[k] = movie
+			continue
+		}
+
+		// We still need to find this movie. Aliases are indexed by the
+		// scraped slug, so make sure the movie has it.
+		movie := p.Movie
+		movie.Slugs = p.Slugs
+		match, candidates, found := m.Match(&movie)
+		if found {
+			result[k] = *match
+		} else if len(candidates) > 0 && !dryRun {
+			// Not sure if it's one of the candidates, let an admin decide.
+			// The sessions are kept as unmatched until then.
+			if err := m.Report(&movie, candidates); err != nil {
+				log.Printf("failed to report match of '%s': %v", movie.Title, err)
 			}
 		}
 	}
 
-	return movies
+	return result
 }
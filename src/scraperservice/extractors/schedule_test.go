@@ -0,0 +1,134 @@
+package extractors
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/mongolayer"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/movieutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	testConnection = "mongodb://localhost/amenic-test"
+)
+
+func newMockDataAccessLayer() persistence.DataAccessLayer {
+	data, err := mongolayer.NewMongoDAL(testConnection)
+	if err != nil {
+		log.Fatal(err)
+	}
+	data.Setup()
+	return data
+}
+
+func newScheduleExtractor(data persistence.DataAccessLayer, theaterID primitive.ObjectID, provider string, sessions ...models.Session) *ScheduleExtractor {
+	run := &models.ScraperRun{
+		Scraper: &models.Scraper{
+			TheaterID: theaterID,
+			Type:      scraperutil.TypeSchedule,
+			Provider:  provider,
+		},
+		ResultCode: scraperutil.RunResultSuccess,
+		Sessions:   sessions,
+	}
+	return NewScheduleExtractor(data, nil, run)
+}
+
+func TestMergeSessions(t *testing.T) {
+	data := newMockDataAccessLayer()
+	defer data.Close()
+
+	theaterID := primitive.NewObjectID()
+	query := func() persistence.Query {
+		return data.DefaultQuery().AddCondition("theaterId", theaterID)
+	}
+
+	start := time.Now().UTC().Truncate(time.Minute).Add(time.Hour)
+	newSession := func(room uint, slug string) models.Session {
+		return models.Session{
+			TheaterID:   theaterID,
+			Room:        room,
+			StartTime:   &start,
+			MovieSlugs:  models.Slugs{NoDashes: slug},
+			ScrapedSlug: slug,
+		}
+	}
+	joker := newSession(1, "coringa")
+	frozen := newSession(2, "frozen2")
+
+	// Both providers matched the movie of the first session, the second one
+	// isn't matched yet.
+	matched := joker
+	matched.MovieID = primitive.NewObjectID()
+	matched.MovieSlugs = models.Slugs{NoDashes: "coringa2019"}
+	other := matched
+	other.ScrapedSlug = "joker"
+	err := newScheduleExtractor(data, theaterID, "cinemais", matched, frozen).MergeSessions()
+	assert.NoError(t, err)
+	err = newScheduleExtractor(data, theaterID, "ibicinemas", other, frozen).MergeSessions()
+	assert.NoError(t, err)
+
+	sessions, err := data.GetSessions(query())
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	for _, s := range sessions {
+		assert.ElementsMatch(t, []string{"cinemais", "ibicinemas"}, s.Providers)
+	}
+
+	// Sessions a provider no longer reports are kept for the other one...
+	err = newScheduleExtractor(data, theaterID, "cinemais", matched).MergeSessions()
+	assert.NoError(t, err)
+	sessions, err = data.GetSessions(query().AddCondition("key", frozen.NaturalKey()))
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, []string{"ibicinemas"}, sessions[0].Providers)
+	}
+
+	// ...until no provider reports them.
+	err = newScheduleExtractor(data, theaterID, "ibicinemas", other).MergeSessions()
+	assert.NoError(t, err)
+	sessions, err = data.GetSessions(query())
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, matched.NaturalKey(), sessions[0].Key)
+		assert.Equal(t, matched.MovieID, sessions[0].MovieID)
+		assert.ElementsMatch(t, []string{"cinemais", "ibicinemas"}, sessions[0].Providers)
+	}
+
+	_, err = data.DeleteSessions(query())
+	assert.NoError(t, err)
+}
+
+func TestLookupMoviesDryRun(t *testing.T) {
+	data := newMockDataAccessLayer()
+	defer data.Close()
+
+	// The scraped movie is only a candidate of the stored one.
+	title := "Lookup " + primitive.NewObjectID().Hex()
+	movie := models.Movie{ID: primitive.NewObjectID(), Title: title + " 2"}
+	movieutil.FillSlugs(&movie)
+	assert.NoError(t, data.InsertMovie(movie))
+	defer data.DeleteMovie(movie.ID.Hex())
+
+	scraped := &models.Movie{Title: title}
+	movieutil.FillSlugs(scraped)
+	sessions := []models.Session{{MovieSlugs: scraped.Slugs, Movie: scraped}}
+	query := data.DefaultQuery().AddCondition("key", scraped.Slugs.NoDashes)
+
+	// Dry runs don't report it for review.
+	assert.Empty(t, LookupMovies(data, sessions, true))
+	_, err := data.FindPendingMatch(query)
+	assert.Error(t, err)
+
+	assert.Empty(t, LookupMovies(data, sessions, false))
+	match, err := data.FindPendingMatch(query)
+	if assert.NoError(t, err) {
+		assert.Equal(t, movie.ID, match.Candidates[0].MovieID)
+	}
+}
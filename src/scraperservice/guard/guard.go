@@ -0,0 +1,145 @@
+package guard
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scheduleutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+)
+
+// Check validates the data extracted by the given run against the scraper's
+// sanity rules and returns the reason of every rule that was broken.
+//
+// An empty result means the run is safe to be persisted.
+func Check(run *models.ScraperRun, now time.Time) []string {
+	if run == nil || run.Scraper == nil {
+		return nil
+	}
+
+	rules := scraperutil.GetRules(run.Scraper)
+	result := make([]string, 0)
+
+	count := run.ExtractedCount
+	if rules.MinCount > 0 && count < rules.MinCount {
+		result = append(result, fmt.Sprintf("extracted %d items, minimum is %d", count, rules.MinCount))
+	}
+
+	if rules.MaxDropPercent > 0 {
+		last := run.Scraper.LastRunDoc
+		if last != nil && last.ExtractedCount > 0 && scraperutil.IsSuccessful(last.ResultCode) {
+			drop := float64(last.ExtractedCount-count) / float64(last.ExtractedCount) * 100
+			if drop > rules.MaxDropPercent {
+				result = append(result, fmt.Sprintf("extracted count dropped %.1f%% (from %d to %d), maximum is %.1f%%",
+					drop, last.ExtractedCount, count, rules.MaxDropPercent))
+			}
+		}
+	}
+
+	for _, field := range rules.RequiredFields {
+		missing := countMissing(run, field)
+		if missing > 0 {
+			result = append(result, fmt.Sprintf("%d items are missing required field '%s'", missing, field))
+		}
+	}
+
+	if rules.MaxOutsideWeekPercent > 0 && len(run.Sessions) > 0 {
+		outside := countOutsideWeek(run.Sessions, now)
+		percent := float64(outside) / float64(len(run.Sessions)) * 100
+		if percent > rules.MaxOutsideWeekPercent {
+			result = append(result, fmt.Sprintf("%.1f%% of sessions are outside the current week, maximum is %.1f%%",
+				percent, rules.MaxOutsideWeekPercent))
+		}
+	}
+
+	return result
+}
+
+func countMissing(run *models.ScraperRun, field string) int {
+	result := 0
+	for _, s := range run.Sessions {
+		if isSessionFieldMissing(&s, field) {
+			result++
+		}
+	}
+	for _, p := range run.Prices {
+		if isPriceFieldMissing(&p, field) {
+			result++
+		}
+	}
+	for _, m := range run.Movies {
+		if isMovieFieldMissing(&m, field) {
+			result++
+		}
+	}
+	return result
+}
+
+func isSessionFieldMissing(s *models.Session, field string) bool {
+	switch field {
+	case "theaterId":
+		return s.TheaterID.IsZero()
+	case "movieId":
+		return s.MovieID.IsZero()
+	case "movieSlugs":
+		return s.MovieSlugs.IsEmpty()
+	case "startTime":
+		return s.StartTime == nil || s.StartTime.IsZero()
+	case "room":
+		return s.Room == 0
+	case "format":
+		return s.Format == ""
+	case "version":
+		return s.Version == ""
+	}
+	return false
+}
+
+func isPriceFieldMissing(p *models.Price, field string) bool {
+	switch field {
+	case "theaterId":
+		return p.TheaterID.IsZero()
+	case "label":
+		return p.Label == ""
+	case "full":
+		return p.Full <= 0
+	case "half":
+		return p.Half <= 0
+	case "weekdays":
+		return len(p.Weekdays) == 0
+	}
+	return false
+}
+
+func isMovieFieldMissing(m *models.Movie, field string) bool {
+	switch field {
+	case "title":
+		return m.Title == ""
+	case "releaseDate":
+		return m.ReleaseDate == nil || m.ReleaseDate.IsZero()
+	case "poster":
+		return m.PosterURL == ""
+	case "synopsis":
+		return m.Synopsis == ""
+	}
+	return false
+}
+
+// countOutsideWeek counts how many sessions start outside the screening week
+// of now. Weeks are computed in the time zone of each session.
+func countOutsideWeek(sessions []models.Session, now time.Time) int {
+	result := 0
+	for _, s := range sessions {
+		if s.StartTime == nil {
+			continue
+		}
+		period := scheduleutil.GetWeekPeriodIn(now, s.Location())
+		// Period end is the start of the last day of the week.
+		end := period.End.AddDate(0, 0, 1)
+		if s.StartTime.Before(period.Start) || !s.StartTime.Before(end) {
+			result++
+		}
+	}
+	return result
+}
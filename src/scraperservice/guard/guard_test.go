@@ -0,0 +1,80 @@
+package guard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testNow is a friday, in the middle of a screening week.
+var testNow = time.Date(2019, time.November, 1, 18, 0, 0, 0, time.UTC)
+
+func newScheduleRun(now time.Time, count int) *models.ScraperRun {
+	run := &models.ScraperRun{
+		Scraper: &models.Scraper{Type: scraperutil.TypeSchedule},
+	}
+	for i := 0; i < count; i++ {
+		start := now.Add(time.Hour)
+		run.Sessions = append(run.Sessions, models.Session{
+			TheaterID:  primitive.NewObjectID(),
+			MovieSlugs: models.Slugs{NoDashes: "coringa"},
+			StartTime:  &start,
+		})
+	}
+	run.ExtractedCount = len(run.Sessions)
+	return run
+}
+
+func TestCheckPasses(t *testing.T) {
+	now := testNow
+	run := newScheduleRun(now, 10)
+	run.Scraper.LastRunDoc = &models.ScraperRun{
+		ResultCode:     scraperutil.RunResultSuccess,
+		ExtractedCount: 12,
+	}
+	assert.Empty(t, Check(run, now))
+}
+
+func TestCheckDrop(t *testing.T) {
+	now := testNow
+	run := newScheduleRun(now, 3)
+	run.Scraper.LastRunDoc = &models.ScraperRun{
+		ResultCode:     scraperutil.RunResultSuccess,
+		ExtractedCount: 300,
+	}
+	assert.Len(t, Check(run, now), 1)
+
+	// Quarantined runs are never used as reference.
+	run.Scraper.LastRunDoc.ResultCode = scraperutil.RunResultQuarantined
+	assert.Empty(t, Check(run, now))
+}
+
+func TestCheckMinCountAndRequiredFields(t *testing.T) {
+	now := testNow
+	run := newScheduleRun(now, 0)
+	// Empty runs are handled before the guard, rules only set a minimum.
+	assert.Empty(t, Check(run, now))
+
+	run = newScheduleRun(now, 2)
+	run.Scraper.Rules = &models.ScraperRules{MinCount: 5}
+	assert.Len(t, Check(run, now), 1)
+
+	run = newScheduleRun(now, 2)
+	run.Sessions[0].StartTime = nil
+	assert.Len(t, Check(run, now), 1)
+}
+
+func TestCheckOutsideWeek(t *testing.T) {
+	now := testNow
+	run := newScheduleRun(now, 4)
+	future := now.AddDate(0, 0, 30)
+	run.Sessions[0].StartTime = &future
+	assert.Len(t, Check(run, now), 1)
+
+	run.Scraper.Rules = &models.ScraperRules{MaxOutsideWeekPercent: 50}
+	assert.Empty(t, Check(run, now))
+}
@@ -0,0 +1,225 @@
+package health
+
+import (
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// RecentRuns is the number of runs used to calculate the health of a scraper.
+const RecentRuns = 20
+
+type (
+	// ScraperHealth summarizes the recent runs of a scraper.
+	ScraperHealth struct {
+		ScraperID         string     `json:"scraper_id"`
+		TheaterID         string     `json:"theater_id"`
+		Type              string     `json:"type"`
+		Provider          string     `json:"provider"`
+		LastSuccess       *time.Time `json:"last_success,omitempty"`        // LastSuccess is the complete time of the last successful run
+		LastResultCode    string     `json:"last_result_code,omitempty"`    // LastResultCode is the result code of the most recent run
+		Runs              int        `json:"runs"`                          // Runs is the number of recent runs considered
+		SuccessRatio      float64    `json:"success_ratio"`                 // SuccessRatio is the ratio of successful recent runs, from 0 to 1
+		AverageDuration   float64    `json:"average_duration"`              // AverageDuration is the average duration of recent runs in seconds
+		ExtractedCounts   []int      `json:"extracted_counts"`              // ExtractedCounts of recent successful runs, from oldest to newest
+		CountChange       float64    `json:"count_change"`                  // CountChange is the percentage change of the newest count relative to the average of the others
+		FreshnessSLA      float64    `json:"freshness_sla"`                 // FreshnessSLA is the freshness SLA of the scraper type in hours
+		Stale             bool       `json:"stale"`                         // Stale indicates the data is older than the freshness SLA
+		StaleSinceSeconds float64    `json:"stale_since_seconds,omitempty"` // StaleSinceSeconds is how long the data is stale
+	}
+
+	// Summary lists scrapers and theaters that need attention.
+	Summary struct {
+		Date            string          `json:"date"`
+		GeneratedAt     time.Time       `json:"generated_at"`
+		CheckedScrapers int             `json:"checked_scrapers"`
+		StaleScrapers   int             `json:"stale_scrapers"`
+		FailingScrapers int             `json:"failing_scrapers"`  // FailingScrapers is the number of scrapers whose last run wasn't successful
+		LastResultCodes map[string]int  `json:"last_result_codes"` // LastResultCodes counts scrapers by the result code of their last run
+		CheckedTheaters int             `json:"checked_theaters"`
+		EmptySchedules  []EmptySchedule `json:"empty_schedules"` // EmptySchedules lists theaters without any session today
+	}
+
+	// EmptySchedule is a theater without any session today.
+	EmptySchedule struct {
+		TheaterID string `json:"theater_id"`
+		Name      string `json:"name"`
+	}
+)
+
+// GetScraperHealth retrieves the recent runs of the given scraper and calculates its health.
+func GetScraperHealth(data persistence.DataAccessLayer, scraper models.Scraper, now time.Time) (*ScraperHealth, error) {
+	runs, err := data.GetScraperRuns(data.DefaultQuery().
+		AddCondition("scraper_id", scraper.ID).
+		SetSort("-start_time").
+		SetLimit(RecentRuns))
+	if err != nil {
+		return nil, err
+	}
+
+	h := Compute(scraper, runs, now)
+
+	// The last success may be older than the recent runs.
+	if h.LastSuccess == nil && len(runs) == RecentRuns {
+		runs, err = data.GetScraperRuns(data.DefaultQuery().
+			AddCondition("scraper_id", scraper.ID).
+			AddCondition("result_code", bson.M{"$in": []string{
+				scraperutil.RunResultSuccess,
+				scraperutil.RunResultNotModified,
+			}}).
+			SetSort("-start_time").
+			SetLimit(1))
+		if err == nil && len(runs) > 0 {
+			h.LastSuccess = runs[0].CompleteTime
+			computeStaleness(h, now)
+		}
+	}
+
+	return h, nil
+}
+
+// Compute calculates the health of a scraper from its recent runs, sorted
+// from newest to oldest.
+func Compute(scraper models.Scraper, runs []models.ScraperRun, now time.Time) *ScraperHealth {
+	result := &ScraperHealth{
+		ScraperID:       scraper.ID.Hex(),
+		TheaterID:       scraper.TheaterID.Hex(),
+		Type:            scraper.Type,
+		Provider:        scraper.Provider,
+		Runs:            len(runs),
+		ExtractedCounts: make([]int, 0),
+		FreshnessSLA:    scraperutil.FreshnessSLA(scraper.Type).Hours(),
+	}
+
+	if len(runs) > 0 {
+		result.LastResultCode = runs[0].ResultCode
+	}
+
+	var success, timed int
+	var duration float64
+	for i := len(runs) - 1; i >= 0; i-- {
+		r := runs[i]
+		if r.StartTime != nil && r.CompleteTime != nil {
+			duration += r.CompleteTime.Sub(*r.StartTime).Seconds()
+			timed++
+		}
+		if scraperutil.IsSuccessful(r.ResultCode) {
+			success++
+			result.ExtractedCounts = append(result.ExtractedCounts, r.ExtractedCount)
+			if result.LastSuccess == nil || (r.CompleteTime != nil && r.CompleteTime.After(*result.LastSuccess)) {
+				result.LastSuccess = r.CompleteTime
+			}
+		}
+	}
+
+	if len(runs) > 0 {
+		result.SuccessRatio = float64(success) / float64(len(runs))
+	}
+	if timed > 0 {
+		result.AverageDuration = duration / float64(timed)
+	}
+	result.CountChange = countChange(result.ExtractedCounts)
+	computeStaleness(result, now)
+	return result
+}
+
+func computeStaleness(h *ScraperHealth, now time.Time) {
+	sla := time.Duration(h.FreshnessSLA * float64(time.Hour))
+	if h.LastSuccess == nil {
+		h.Stale = true
+		return
+	}
+	age := now.Sub(*h.LastSuccess)
+	h.Stale = age > sla
+	if h.Stale {
+		h.StaleSinceSeconds = (age - sla).Seconds()
+	}
+}
+
+// countChange calculates the percentage change of the last count relative
+// to the average of the previous ones.
+func countChange(counts []int) float64 {
+	n := len(counts)
+	if n < 2 {
+		return 0
+	}
+	sum := 0
+	for _, c := range counts[:n-1] {
+		sum += c
+	}
+	avg := float64(sum) / float64(n-1)
+	if avg == 0 {
+		return 0
+	}
+	return (float64(counts[n-1]) - avg) / avg * 100
+}
+
+// GetSummary flags stale scrapers and theaters whose schedule for today is
+// empty. Today is the day in the time zone of each theater.
+func GetSummary(data persistence.DataAccessLayer, now time.Time) (*Summary, error) {
+	scrapers, err := data.GetScrapers(data.DefaultQuery().SetLimit(-1))
+	if err != nil {
+		return nil, err
+	}
+
+	today := now.In(timeutil.DefaultLocation())
+	result := &Summary{
+		Date:            timeutil.TimeToSimpleDateString(&today),
+		EmptySchedules:  make([]EmptySchedule, 0),
+		LastResultCodes: make(map[string]int),
+		GeneratedAt:     now,
+		CheckedScrapers: len(scrapers),
+	}
+
+	for _, s := range scrapers {
+		h, err := GetScraperHealth(data, s, now)
+		if err != nil {
+			return nil, err
+		}
+		if h.Stale {
+			result.StaleScrapers++
+		}
+		if h.LastResultCode != "" {
+			result.LastResultCodes[h.LastResultCode]++
+			if !scraperutil.IsSuccessful(h.LastResultCode) {
+				result.FailingScrapers++
+			}
+		}
+	}
+
+	theaters, err := data.GetTheaters(data.DefaultQuery().
+		AddCondition("hidden", false).
+		AddInclude("city").
+		SetLimit(-1))
+	if err != nil {
+		return nil, err
+	}
+	result.CheckedTheaters = len(theaters)
+
+	for _, t := range theaters {
+		count, err := data.CountSessions(data.DefaultQuery().
+			AddCondition("theaterId", t.ID).
+			AddCondition("date", theaterDate(&t, now)))
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			result.EmptySchedules = append(result.EmptySchedules, EmptySchedule{
+				TheaterID: t.ID.Hex(),
+				Name:      t.Name,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// theaterDate returns the day of now in the time zone of the theater, as
+// stored in the date of its sessions.
+func theaterDate(t *models.Theater, now time.Time) int {
+	return timeutil.DateIn(now, t.City.Location())
+}
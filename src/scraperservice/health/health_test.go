@@ -0,0 +1,62 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRun(code string, start time.Time, seconds, count int) models.ScraperRun {
+	end := start.Add(time.Duration(seconds) * time.Second)
+	return models.ScraperRun{
+		ResultCode:     code,
+		StartTime:      &start,
+		CompleteTime:   &end,
+		ExtractedCount: count,
+	}
+}
+
+func TestCompute(t *testing.T) {
+	now := time.Date(2019, time.November, 1, 18, 0, 0, 0, time.UTC)
+	scraper := models.Scraper{Type: scraperutil.TypeSchedule}
+
+	// Newest first.
+	runs := []models.ScraperRun{
+		newRun(scraperutil.RunResultTimeout, now.Add(-1*time.Hour), 30, 0),
+		newRun(scraperutil.RunResultSuccess, now.Add(-2*time.Hour), 10, 150),
+		newRun(scraperutil.RunResultNotModified, now.Add(-26*time.Hour), 20, 100),
+	}
+
+	h := Compute(scraper, runs, now)
+	assert.Equal(t, scraperutil.RunResultTimeout, h.LastResultCode)
+	assert.Equal(t, 3, h.Runs)
+	assert.InDelta(t, 2.0/3.0, h.SuccessRatio, 0.001)
+	assert.InDelta(t, 20, h.AverageDuration, 0.001)
+	assert.Equal(t, []int{100, 150}, h.ExtractedCounts)
+	assert.InDelta(t, 50, h.CountChange, 0.001)
+	assert.False(t, h.Stale)
+
+	h = Compute(scraper, runs[2:], now)
+	assert.True(t, h.Stale)
+
+	h = Compute(scraper, nil, now)
+	assert.True(t, h.Stale)
+	assert.Nil(t, h.LastSuccess)
+}
+
+func TestTheaterDate(t *testing.T) {
+	// 01:00 UTC is still the previous day in Brazil.
+	now := time.Date(2019, time.November, 2, 1, 0, 0, 0, time.UTC)
+
+	theater := models.Theater{City: &models.City{TimeZone: "America/Sao_Paulo"}}
+	assert.Equal(t, 20191101, theaterDate(&theater, now))
+
+	theater.City.TimeZone = "UTC"
+	assert.Equal(t, 20191102, theaterDate(&theater, now))
+
+	// Theaters without city use the default time zone.
+	assert.Equal(t, 20191101, theaterDate(&models.Theater{}, now))
+}
@@ -3,12 +3,14 @@ package listener
 import (
 	"log"
 	"strconv"
+	"time"
 
 	"github.com/dsbezerra/amenic-lambda/src/contracts"
 	"github.com/dsbezerra/amenic-lambda/src/lib/messagequeue"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/scraperservice/queue"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/scheduler"
 	"github.com/sirupsen/logrus"
 )
 
@@ -18,6 +20,7 @@ type EventProcessor struct {
 	EventEmitter  messagequeue.EventEmitter
 	Data          persistence.DataAccessLayer
 	Log           *logrus.Entry
+	Location      *time.Location // Location used to check run windows
 }
 
 // ProcessEvents ...
@@ -72,9 +75,18 @@ func (p *EventProcessor) handleCommandDispatched(e *contracts.EventCommandDispat
 		}
 
 		if args["scraper_id"] != "" {
-			queue.AddWork(queue.WorkRequest{
+			scraper, err := p.Data.GetScraper(args["scraper_id"], p.Data.DefaultQuery())
+			if err != nil {
+				p.Log.Error(err.Error())
+				return
+			}
+			if !p.shouldRun(scraper) {
+				return
+			}
+			p.addWork(queue.WorkRequest{
 				ScraperID:     args["scraper_id"],
 				IgnoreLastRun: ignoreLastRun,
+				Priority:      queue.PriorityCron,
 			})
 			return
 		}
@@ -104,9 +116,14 @@ func (p *EventProcessor) handleCommandDispatched(e *contracts.EventCommandDispat
 				return
 			}
 			for _, s := range scrapers {
-				queue.AddWork(queue.WorkRequest{
+				// Scrapers with their own cron specs are run by the scheduler.
+				if len(s.Cron) > 0 || !p.shouldRun(&s) {
+					continue
+				}
+				p.addWork(queue.WorkRequest{
 					ScraperID:     s.ID.Hex(),
 					IgnoreLastRun: ignoreLastRun,
+					Priority:      queue.PriorityCron,
 				})
 			}
 		}
@@ -116,3 +133,23 @@ func (p *EventProcessor) handleCommandDispatched(e *contracts.EventCommandDispat
 		p.Log.Infof("handler for event %s was not found", e.Name)
 	}
 }
+
+// shouldRun checks whether a scheduled run of the scraper is allowed now.
+func (p *EventProcessor) shouldRun(s *models.Scraper) bool {
+	if !s.IsEnabled() {
+		p.Log.Infof("skipped scraper %s: disabled", s.ID.Hex())
+		return false
+	}
+	if !scheduler.InRunWindow(s.Windows, time.Now().In(p.Location)) {
+		p.Log.Infof("skipped scraper %s: outside of run windows", s.ID.Hex())
+		return false
+	}
+	return true
+}
+
+func (p *EventProcessor) addWork(wr queue.WorkRequest) {
+	err := queue.AddWork(wr)
+	if err != nil {
+		p.Log.Errorf("couldn't queue scraper %s: %s", wr.ScraperID, err.Error())
+	}
+}
@@ -16,8 +16,10 @@ import (
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/mongolayer"
 	"github.com/dsbezerra/amenic-lambda/src/scraperservice/listener"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/metadata"
 	"github.com/dsbezerra/amenic-lambda/src/scraperservice/queue"
 	"github.com/dsbezerra/amenic-lambda/src/scraperservice/rest"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/scheduler"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
@@ -53,6 +55,7 @@ func main() {
 		ctx.Log.Fatal(err)
 	}
 	ctx.Config = settings
+	metadata.SetDefault(metadata.New(settings))
 
 	tasks, err := config.LoadTasks()
 	if err != nil {
@@ -89,12 +92,15 @@ func main() {
 	// Ensure our tasks are saved in database.
 	(data.(*mongolayer.MongoDAL)).EnsureTasksExists(tasks)
 
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+
 	// Start event processor.
 	p := listener.EventProcessor{
 		Data:          data,
 		Log:           ctx.Log,
 		EventListener: eventListener,
 		EventEmitter:  eventEmitter,
+		Location:      loc,
 	}
 	go p.ProcessEvents()
 
@@ -102,7 +108,6 @@ func main() {
 	sigCh := make(chan os.Signal)
 	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
 
-	loc, _ := time.LoadLocation("America/Sao_Paulo")
 	c := cron.NewWithLocation(loc)
 
 	// Setup possible cron jobs
@@ -141,20 +146,25 @@ func main() {
 		}
 	}
 
+	// Scrapers with their own cron specs are scheduled separately.
+	sched := scheduler.New(data, loc, ctx.Log)
+
 	go func() {
 		router := ctx.buildRouter()
-		rest.ServeAPI(router, data, eventEmitter)
+		rest.ServeAPI(router, data, eventEmitter, sched)
 		router.Run(settings.RESTEndpoint)
 	}()
 
-	// TODO: Get the number of workers from .env
-	queue.SetupWorkerQueue(data, eventEmitter, 4)
+	q := queue.SetupWorkerQueue(data, eventEmitter, settings.ScraperWorkers, settings.ScraperHostConcurrency)
+	sched.Start()
 
 	// Wait for a signal
 	sig := <-sigCh
 	ctx.Log.WithField("signal", sig).Info("Signal received. Shutting down.")
 
 	c.Stop()
+	sched.Stop()
+	q.Drain(30 * time.Second)
 }
 
 // BuildRouter ...
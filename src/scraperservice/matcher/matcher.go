@@ -0,0 +1,148 @@
+package matcher
+
+import (
+	"sort"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/movieutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// DefaultThreshold is the minimum score of a match to be used without review.
+	DefaultThreshold = 0.8
+	// MinScore is the minimum score of a movie to be considered a candidate.
+	MinScore = 0.3
+	// maxTextResults limits how many movies are compared from the text search.
+	maxTextResults = 10
+)
+
+// Matcher finds the movies in the database that correspond to scraped movies.
+type Matcher struct {
+	Data      persistence.DataAccessLayer
+	Threshold float64
+}
+
+// New creates a matcher with the default threshold.
+func New(data persistence.DataAccessLayer) *Matcher {
+	return &Matcher{
+		Data:      data,
+		Threshold: DefaultThreshold,
+	}
+}
+
+// Match finds the best candidate for the given movie. It returns every
+// candidate sorted by score and whether the best one is good enough to be used.
+//
+// Titles bound to a movie by an admin are always matched to that movie.
+func (m *Matcher) Match(movie *models.Movie) (*models.Movie, []models.MatchCandidate, bool) {
+	if movie.Slugs.NoDashes == "" {
+		movieutil.FillSlugs(movie)
+	}
+
+	if alias := m.findAlias(movie); alias != nil {
+		return alias, nil, true
+	}
+
+	found := m.findCandidates(movie)
+	candidates := make([]models.MatchCandidate, 0, len(found))
+	byID := make(map[primitive.ObjectID]*models.Movie, len(found))
+	for i := range found {
+		c := Score(movie, &found[i])
+		if c.Score < MinScore {
+			continue
+		}
+		candidates = append(candidates, c)
+		byID[c.MovieID] = &found[i]
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	if len(candidates) == 0 {
+		return nil, candidates, false
+	}
+	best := candidates[0]
+	return byID[best.MovieID], candidates, best.Score >= m.Threshold
+}
+
+// Report saves the movie and its candidates for an admin to review.
+func (m *Matcher) Report(movie *models.Movie, candidates []models.MatchCandidate) error {
+	return m.Data.SavePendingMatch(models.PendingMatch{
+		Key:        movie.Slugs.NoDashes,
+		Movie:      *movie,
+		Candidates: candidates,
+		Status:     models.PendingMatchPending,
+	})
+}
+
+// findAlias retrieves the movie an admin bound to the title of the given one.
+func (m *Matcher) findAlias(movie *models.Movie) *models.Movie {
+	if movie.Slugs.NoDashes == "" {
+		return nil
+	}
+	alias, err := m.Data.FindMovieAlias(m.Data.DefaultQuery().
+		AddCondition("alias", movie.Slugs.NoDashes))
+	if err != nil {
+		return nil
+	}
+	result, err := m.Data.GetMovie(alias.MovieID.Hex(), m.Data.DefaultQuery())
+	if err != nil {
+		return nil
+	}
+	return result
+}
+
+// findCandidates retrieves movies that may be the given one by external IDs,
+// slugs and title text search.
+func (m *Matcher) findCandidates(movie *models.Movie) []models.Movie {
+	result := make([]models.Movie, 0)
+	seen := map[primitive.ObjectID]bool{}
+	add := func(movies ...models.Movie) {
+		for _, movie := range movies {
+			if !seen[movie.ID] {
+				seen[movie.ID] = true
+				result = append(result, movie)
+			}
+		}
+	}
+	find := func(key string, value interface{}) {
+		found, err := m.Data.FindMovie(m.Data.DefaultQuery().AddCondition(key, value))
+		if err == nil && found != nil {
+			add(*found)
+		}
+	}
+
+	if movie.ClaqueteID != 0 {
+		find("claqueteId", movie.ClaqueteID)
+	}
+	if movie.TmdbID != 0 {
+		find("tmdbId", movie.TmdbID)
+	}
+	if movie.ImdbID != "" {
+		find("imdbId", movie.ImdbID)
+	}
+	if movie.Slugs.Year != "" {
+		find("slugs.year", movie.Slugs.Year)
+	}
+	if movie.Slugs.NoDashes != "" {
+		find("slugs.noDashes", movie.Slugs.NoDashes)
+	}
+
+	if movie.Title != "" {
+		possible, err := m.Data.GetMovies(m.Data.DefaultQuery().
+			AddCondition("$text", bson.M{"$search": movie.Title}).
+			SetFields(bson.M{
+				"score": bson.M{"$meta": "textScore"},
+			}).
+			SetSort("$textScore:score").
+			SetLimit(maxTextResults))
+		if err == nil {
+			add(possible...)
+		}
+	}
+
+	return result
+}
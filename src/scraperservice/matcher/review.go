@@ -0,0 +1,124 @@
+package matcher
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrMatchNotPending is returned when reviewing a match that was already resolved.
+	ErrMatchNotPending = errors.New("match is not pending review")
+	// ErrNoCandidates is returned when confirming a match without candidates.
+	ErrNoCandidates = errors.New("match has no candidates")
+)
+
+// Confirm resolves the pending match to its best candidate.
+func Confirm(data persistence.DataAccessLayer, id string) (*models.PendingMatch, error) {
+	match, err := getPendingMatch(data, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(match.Candidates) == 0 {
+		return nil, ErrNoCandidates
+	}
+	return resolve(data, match, match.Candidates[0].MovieID.Hex(), models.PendingMatchConfirmed)
+}
+
+// Reassign resolves the pending match to the movie with the given ID.
+func Reassign(data persistence.DataAccessLayer, id, movieID string) (*models.PendingMatch, error) {
+	match, err := getPendingMatch(data, id)
+	if err != nil {
+		return nil, err
+	}
+	return resolve(data, match, movieID, models.PendingMatchReassigned)
+}
+
+// Create inserts the scraped movie as a new movie and resolves the pending match to it.
+func Create(data persistence.DataAccessLayer, id string) (*models.PendingMatch, error) {
+	match, err := getPendingMatch(data, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	movie := match.Movie
+	movie.ID = primitive.NewObjectID()
+	movie.CreatedAt = &now
+	err = data.InsertMovie(movie)
+	if err != nil {
+		return nil, err
+	}
+
+	return finish(data, match, movie.ID, models.PendingMatchCreated)
+}
+
+// resolve assigns the scraped movie to an existing one. External IDs only the
+// scraped movie has are copied, so next runs match it without review.
+func resolve(data persistence.DataAccessLayer, match *models.PendingMatch, movieID, status string) (*models.PendingMatch, error) {
+	movie, err := data.GetMovie(movieID, data.DefaultQuery())
+	if err != nil {
+		return nil, err
+	}
+
+	update := false
+	if movie.ClaqueteID == 0 && match.Movie.ClaqueteID != 0 {
+		movie.ClaqueteID = match.Movie.ClaqueteID
+		update = true
+	}
+	if movie.TmdbID == 0 && match.Movie.TmdbID != 0 {
+		movie.TmdbID = match.Movie.TmdbID
+		update = true
+	}
+	if movie.ImdbID == "" && match.Movie.ImdbID != "" {
+		movie.ImdbID = match.Movie.ImdbID
+		update = true
+	}
+	if update {
+		now := time.Now()
+		movie.UpdatedAt = &now
+		_, err = data.UpdateMovie(movie.ID.Hex(), *movie)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return finish(data, match, movie.ID, status)
+}
+
+// finish resolves the match and remembers the scraped title as an alias of
+// the movie, so next runs match it automatically.
+func finish(data persistence.DataAccessLayer, match *models.PendingMatch, movieID primitive.ObjectID, status string) (*models.PendingMatch, error) {
+	err := data.SaveMovieAlias(models.MovieAlias{
+		Alias:   match.Key,
+		Title:   match.Movie.Title,
+		MovieID: movieID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	match.Status = status
+	match.MovieID = movieID
+	match.ResolvedAt = &now
+	_, err = data.UpdatePendingMatch(match.ID.Hex(), *match)
+	if err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+func getPendingMatch(data persistence.DataAccessLayer, id string) (*models.PendingMatch, error) {
+	match, err := data.GetPendingMatch(id, data.DefaultQuery())
+	if err != nil {
+		return nil, err
+	}
+	if match.Status != models.PendingMatchPending {
+		return nil, ErrMatchNotPending
+	}
+	return match, nil
+}
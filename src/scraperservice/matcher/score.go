@@ -0,0 +1,217 @@
+package matcher
+
+import (
+	"strings"
+	"time"
+
+	"github.com/agnivade/levenshtein"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/movieutil"
+)
+
+// Signals compared between a scraped movie and a candidate.
+const (
+	SignalExternalID    = "external_id"
+	SignalTitle         = "title"
+	SignalOriginalTitle = "original_title"
+	SignalYear          = "year"
+	SignalRuntime       = "runtime"
+	SignalCast          = "cast"
+	SignalDistributor   = "distributor"
+)
+
+// weights of each signal in the final score. Signals missing in any of the
+// movies don't count.
+var weights = map[string]float64{
+	SignalTitle:         3,
+	SignalOriginalTitle: 2,
+	SignalYear:          1.5,
+	SignalRuntime:       1,
+	SignalCast:          1.5,
+	SignalDistributor:   0.5,
+}
+
+// prefixSimilarity is the title similarity given when a title starts with the
+// other, eg: 'Frozen' and 'Frozen 2'. It's not enough to be a match alone,
+// other signals must agree.
+const prefixSimilarity = 0.75
+
+// subtitleSimilarity is the title similarity given when titles differ only by
+// a generic subtitle, eg: 'Lino' and 'Lino - O Filme'. It's enough to be a
+// match alone.
+const subtitleSimilarity = 0.9
+
+// genericSubtitles are slugs of subtitles theaters append to the title that
+// don't tell movies apart.
+var genericSubtitles = []string{"ofilme", "themovie"}
+
+// Score compares the scraped movie with a candidate and returns how confident
+// we are that they are the same movie.
+func Score(movie, candidate *models.Movie) models.MatchCandidate {
+	result := models.MatchCandidate{
+		MovieID: candidate.ID,
+		Title:   candidate.Title,
+		Signals: make(map[string]float64),
+	}
+
+	// External IDs are decisive.
+	if same, ok := compareExternalIDs(movie, candidate); ok {
+		if same {
+			result.Signals[SignalExternalID] = 1
+			result.Score = 1
+		} else {
+			result.Signals[SignalExternalID] = 0
+		}
+		return result
+	}
+
+	title := titleSimilarity(movie.Title, candidate.Title)
+	if candidate.OriginalTitle != "" {
+		// Some theaters show the original title only.
+		if s := titleSimilarity(movie.Title, candidate.OriginalTitle); s > title {
+			title = s
+		}
+	}
+	result.Signals[SignalTitle] = title
+
+	if movie.OriginalTitle != "" && candidate.OriginalTitle != "" {
+		result.Signals[SignalOriginalTitle] = titleSimilarity(movie.OriginalTitle, candidate.OriginalTitle)
+	}
+
+	if hasDate(movie.ReleaseDate) && hasDate(candidate.ReleaseDate) {
+		switch diff := abs(movie.ReleaseDate.Year() - candidate.ReleaseDate.Year()); {
+		case diff == 0:
+			result.Signals[SignalYear] = 1
+		case diff == 1:
+			// Brazilian releases may happen in the year after the original one.
+			result.Signals[SignalYear] = 0.5
+		default:
+			result.Signals[SignalYear] = 0
+		}
+	}
+
+	if movie.Runtime > 0 && candidate.Runtime > 0 {
+		switch diff := abs(movie.Runtime - candidate.Runtime); {
+		case diff <= 3:
+			result.Signals[SignalRuntime] = 1
+		case diff <= 10:
+			result.Signals[SignalRuntime] = 0.5
+		default:
+			result.Signals[SignalRuntime] = 0
+		}
+	}
+
+	if len(movie.Cast) > 0 && len(candidate.Cast) > 0 {
+		result.Signals[SignalCast] = castOverlap(movie.Cast, candidate.Cast)
+	}
+
+	if movie.Distributor != "" && candidate.Distributor != "" {
+		result.Signals[SignalDistributor] = titleSimilarity(movie.Distributor, candidate.Distributor)
+	}
+
+	var sum, total float64
+	for signal, value := range result.Signals {
+		sum += value * weights[signal]
+		total += weights[signal]
+	}
+	if total > 0 {
+		result.Score = sum / total
+	}
+	return result
+}
+
+// compareExternalIDs checks the IDs both movies have. The second result is
+// false if there's no ID to compare.
+func compareExternalIDs(a, b *models.Movie) (bool, bool) {
+	compared := false
+	if a.ClaqueteID != 0 && b.ClaqueteID != 0 {
+		if a.ClaqueteID != b.ClaqueteID {
+			return false, true
+		}
+		compared = true
+	}
+	if a.TmdbID != 0 && b.TmdbID != 0 {
+		if a.TmdbID != b.TmdbID {
+			return false, true
+		}
+		compared = true
+	}
+	if a.ImdbID != "" && b.ImdbID != "" {
+		if a.ImdbID != b.ImdbID {
+			return false, true
+		}
+		compared = true
+	}
+	return compared, compared
+}
+
+// titleSimilarity compares the slugs of both titles and returns a value from
+// 0 (different) to 1 (same).
+func titleSimilarity(a, b string) float64 {
+	sa := movieutil.GenerateSlug(a, false)
+	sb := movieutil.GenerateSlug(b, false)
+	if sa == "" || sb == "" {
+		return 0
+	}
+	if sa == sb {
+		return 1
+	}
+
+	n := len(sa)
+	if len(sb) > n {
+		n = len(sb)
+	}
+	if trimGenericSubtitle(sa) == trimGenericSubtitle(sb) {
+		return subtitleSimilarity
+	}
+	// Titles with extra words may be the same movie or a sequel.
+	if strings.HasPrefix(sa, sb) || strings.HasPrefix(sb, sa) {
+		return prefixSimilarity
+	}
+	return 1 - float64(levenshtein.ComputeDistance(sa, sb))/float64(n)
+}
+
+// trimGenericSubtitle removes a generic subtitle from the end of a title slug.
+func trimGenericSubtitle(slug string) string {
+	for _, subtitle := range genericSubtitles {
+		if trimmed := strings.TrimSuffix(slug, subtitle); trimmed != "" {
+			slug = trimmed
+		}
+	}
+	return slug
+}
+
+// castOverlap is the ratio of names of the smallest cast found in the other.
+func castOverlap(a, b []string) float64 {
+	names := make(map[string]bool, len(b))
+	for _, name := range b {
+		names[movieutil.GenerateSlug(name, false)] = true
+	}
+
+	found := 0
+	for _, name := range a {
+		if names[movieutil.GenerateSlug(name, false)] {
+			found++
+		}
+	}
+
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	if found > n {
+		found = n
+	}
+	return float64(found) / float64(n)
+}
+
+func hasDate(t *time.Time) bool {
+	return t != nil && !t.IsZero()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,83 @@
+package matcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int) *time.Time {
+	t := time.Date(year, time.November, 1, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestScoreExternalIDs(t *testing.T) {
+	c := Score(&models.Movie{Title: "Coringa", ClaqueteID: 10},
+		&models.Movie{Title: "Joker", ClaqueteID: 10})
+	assert.Equal(t, 1.0, c.Score)
+
+	c = Score(&models.Movie{Title: "Coringa", TmdbID: 1},
+		&models.Movie{Title: "Coringa", TmdbID: 2})
+	assert.Equal(t, 0.0, c.Score)
+}
+
+func TestScoreTitle(t *testing.T) {
+	c := Score(&models.Movie{Title: "Coringa"}, &models.Movie{Title: "Coringa"})
+	assert.Equal(t, 1.0, c.Score)
+
+	// Original title is used when the theater shows it.
+	c = Score(&models.Movie{Title: "Joker"}, &models.Movie{Title: "Coringa", OriginalTitle: "Joker"})
+	assert.Equal(t, 1.0, c.Score)
+
+	// Prefixes alone aren't enough.
+	c = Score(&models.Movie{Title: "Frozen 2"}, &models.Movie{Title: "Frozen"})
+	assert.True(t, c.Score < DefaultThreshold)
+
+	c = Score(&models.Movie{Title: "Lino - O Filme", ReleaseDate: date(2017), Runtime: 94},
+		&models.Movie{Title: "Lino", ReleaseDate: date(2017), Runtime: 93})
+	assert.True(t, c.Score >= DefaultThreshold)
+
+	// Generic subtitles are enough without other signals...
+	c = Score(&models.Movie{Title: "Lino - O Filme"}, &models.Movie{Title: "Lino"})
+	assert.True(t, c.Score >= DefaultThreshold)
+
+	c = Score(&models.Movie{Title: "Pokémon: The Movie"}, &models.Movie{Title: "Pokémon"})
+	assert.True(t, c.Score >= DefaultThreshold)
+
+	// ...but other subtitles aren't.
+	c = Score(&models.Movie{Title: "Star Wars: A Ascensão Skywalker"}, &models.Movie{Title: "Star Wars"})
+	assert.True(t, c.Score < DefaultThreshold)
+}
+
+func TestScoreSignals(t *testing.T) {
+	movie := &models.Movie{
+		Title:       "Doutor Sono",
+		ReleaseDate: date(2019),
+		Runtime:     152,
+		Cast:        []string{"Ewan McGregor", "Rebecca Ferguson"},
+		Distributor: "Warner",
+	}
+	same := &models.Movie{
+		Title:       "Doutor Sono",
+		ReleaseDate: date(2019),
+		Runtime:     151,
+		Cast:        []string{"Rebecca Ferguson", "Ewan McGregor", "Kyliegh Curran"},
+		Distributor: "Warner",
+	}
+	other := &models.Movie{
+		Title:       "Doutor Sono",
+		ReleaseDate: date(1990),
+		Runtime:     90,
+		Cast:        []string{"Someone Else"},
+	}
+
+	c := Score(movie, same)
+	assert.Equal(t, 1.0, c.Score)
+	assert.Equal(t, 1.0, c.Signals[SignalCast])
+
+	c = Score(movie, other)
+	assert.True(t, c.Score < DefaultThreshold)
+	assert.Equal(t, 0.0, c.Signals[SignalYear])
+}
@@ -0,0 +1,237 @@
+package matcher
+
+import (
+	"sort"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	// UnmatchedGroup is a scraped title whose sessions in a theater have no movie.
+	UnmatchedGroup struct {
+		Title     string     `json:"title"`             // Title as shown by the theater
+		Slug      string     `json:"slug"`              // Slug is the title slug without dashes
+		TheaterID string     `json:"theater_id"`        // TheaterID is the theater showing the title
+		Theater   string     `json:"theater"`           // Theater is the name of the theater
+		Sessions  int        `json:"sessions"`          // Sessions is the number of sessions without movie
+		RunID     string     `json:"run_id"`            // RunID is the run that found the title
+		SeenAt    *time.Time `json:"seen_at,omitempty"` // SeenAt is the time the run started
+	}
+
+	// BindResult is the result of binding a title to a movie.
+	BindResult struct {
+		Alias    models.MovieAlias `json:"alias"`    // Alias created for the title
+		Sessions int64             `json:"sessions"` // Sessions is the number of sessions linked to the movie
+	}
+)
+
+// GetUnmatched lists titles without movie found by the last successful run of
+// every schedule scraper, grouped by title and theater. Failed runs extract
+// nothing, so they would hide the titles. Titles already bound to a movie are
+// skipped.
+func GetUnmatched(data persistence.DataAccessLayer) ([]UnmatchedGroup, error) {
+	result := make([]UnmatchedGroup, 0)
+
+	scrapers, err := data.GetScrapers(data.DefaultQuery().
+		AddCondition("type", scraperutil.TypeSchedule).
+		SetLimit(-1))
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]models.ScraperRun, 0, len(scrapers))
+	theaterIDs := make([]primitive.ObjectID, 0, len(scrapers))
+	for _, s := range scrapers {
+		run, err := lastSuccessfulRun(data, s.ID)
+		if err != nil {
+			return nil, err
+		}
+		if run != nil && len(run.Unmatched) > 0 {
+			runs = append(runs, *run)
+			theaterIDs = append(theaterIDs, s.TheaterID)
+		}
+	}
+	if len(runs) == 0 {
+		return result, nil
+	}
+
+	theaters, err := data.GetTheaters(data.DefaultQuery().
+		AddCondition("_id", bson.M{"$in": theaterIDs}).
+		SetLimit(-1))
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[primitive.ObjectID]string, len(theaters))
+	for _, t := range theaters {
+		names[t.ID] = t.Name
+	}
+
+	theaterOf := make(map[primitive.ObjectID]primitive.ObjectID, len(scrapers))
+	slugs := make([]string, 0)
+	for _, s := range scrapers {
+		theaterOf[s.ID] = s.TheaterID
+	}
+	for _, run := range runs {
+		for _, u := range run.Unmatched {
+			slugs = append(slugs, u.Slug)
+		}
+	}
+
+	// Runs before a title was bound still list it.
+	bound := map[string]bool{}
+	if len(slugs) > 0 {
+		aliases, err := data.GetMovieAliases(data.DefaultQuery().
+			AddCondition("alias", bson.M{"$in": slugs}).
+			SetLimit(-1))
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range aliases {
+			bound[a.Alias] = true
+		}
+	}
+
+	for _, run := range runs {
+		theaterID := theaterOf[run.ScraperID]
+		for _, u := range run.Unmatched {
+			if bound[u.Slug] {
+				continue
+			}
+			result = append(result, UnmatchedGroup{
+				Title:     u.Title,
+				Slug:      u.Slug,
+				TheaterID: theaterID.Hex(),
+				Theater:   names[theaterID],
+				Sessions:  u.Sessions,
+				RunID:     run.ID.Hex(),
+				SeenAt:    run.StartTime,
+			})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Title != result[j].Title {
+			return result[i].Title < result[j].Title
+		}
+		return result[i].Theater < result[j].Theater
+	})
+	return result, nil
+}
+
+// lastSuccessfulRun retrieves the most recent run of the scraper whose data
+// is up to date, if any.
+func lastSuccessfulRun(data persistence.DataAccessLayer, scraperID primitive.ObjectID) (*models.ScraperRun, error) {
+	runs, err := data.GetScraperRuns(data.DefaultQuery().
+		AddCondition("scraper_id", scraperID).
+		AddCondition("result_code", bson.M{"$in": []string{
+			scraperutil.RunResultSuccess,
+			scraperutil.RunResultNotModified,
+		}}).
+		SetSort("-start_time").
+		SetLimit(1))
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+// Bind remembers the title as an alias of the movie, so next runs match it
+// automatically, and links the sessions of the title that have no movie.
+func Bind(data persistence.DataAccessLayer, slug, title, movieID string) (*BindResult, error) {
+	movie, err := data.GetMovie(movieID, data.DefaultQuery())
+	if err != nil {
+		return nil, err
+	}
+
+	err = data.SaveMovieAlias(models.MovieAlias{
+		Alias:   slug,
+		Title:   title,
+		MovieID: movie.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	alias, err := data.FindMovieAlias(data.DefaultQuery().AddCondition("alias", slug))
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := data.GetSessions(data.DefaultQuery().
+		AddCondition("movieSlugs.noDashes", slug).
+		AddCondition("movieId", bson.M{"$exists": false}).
+		SetLimit(-1))
+	if err != nil {
+		return nil, err
+	}
+
+	// Bound sessions are typed again with the release of the movie.
+	var count int64
+	for t, ids := range groupByType(sessions, movie) {
+		n, err := data.UpdateSessions(data.DefaultQuery().
+			AddCondition("_id", bson.M{"$in": ids}),
+			bson.M{"$set": bson.M{
+				"movieId":     movie.ID,
+				"movieSlugs":  movie.Slugs,
+				"scrapedSlug": slug,
+				"type":        t,
+			}})
+		if err != nil {
+			return nil, err
+		}
+		count += n
+	}
+
+	for _, s := range sessions {
+		s.MovieID = movie.ID
+		if err := rekeySession(data, s); err != nil {
+			return nil, err
+		}
+	}
+
+	return &BindResult{Alias: *alias, Sessions: count}, nil
+}
+
+// rekeySession moves the session to the natural key of its movie. If another
+// provider already reported the session with the movie, the session is merged
+// into that one.
+func rekeySession(data persistence.DataAccessLayer, s models.Session) error {
+	key := s.NaturalKey()
+	existing, err := data.GetSessions(data.DefaultQuery().
+		AddCondition("key", key).
+		SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		_, err = data.UpdateSessions(data.DefaultQuery().
+			AddCondition("_id", s.ID),
+			bson.M{"$set": bson.M{"key": key}})
+		return err
+	}
+
+	if len(s.Providers) > 0 {
+		_, err = data.UpdateSessions(data.DefaultQuery().
+			AddCondition("_id", existing[0].ID),
+			bson.M{"$addToSet": bson.M{"providers": bson.M{"$each": s.Providers}}})
+		if err != nil {
+			return err
+		}
+	}
+	return data.DeleteSession(s.ID.Hex())
+}
+
+// groupByType groups the IDs of the sessions by their type once they are
+// bound to the movie.
+func groupByType(sessions []models.Session, movie *models.Movie) map[string][]primitive.ObjectID {
+	result := map[string][]primitive.ObjectID{}
+	for i := range sessions {
+		t := models.ClassifySession(&sessions[i], movie)
+		result[t] = append(result[t], sessions[i].ID)
+	}
+	return result
+}
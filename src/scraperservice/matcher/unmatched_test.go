@@ -0,0 +1,89 @@
+package matcher
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/mongolayer"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGroupByType(t *testing.T) {
+	release := time.Date(2019, time.November, 7, 0, 0, 0, 0, time.UTC)
+	movie := &models.Movie{Title: "Frozen 2", ReleaseDate: &release}
+
+	newSession := func(start time.Time) models.Session {
+		return models.Session{
+			ID:        primitive.NewObjectID(),
+			TimeZone:  "America/Sao_Paulo",
+			StartTime: &start,
+			// Unmatched sessions were typed without release.
+			Type: models.SessionTypeRegular,
+		}
+	}
+	preview := newSession(time.Date(2019, time.November, 3, 18, 0, 0, 0, time.UTC))
+	premiere := newSession(time.Date(2019, time.November, 8, 18, 0, 0, 0, time.UTC))
+	regular := newSession(time.Date(2019, time.November, 20, 18, 0, 0, 0, time.UTC))
+
+	result := groupByType([]models.Session{preview, premiere, regular}, movie)
+	assert.Equal(t, []primitive.ObjectID{preview.ID}, result[models.SessionTypePreview])
+	assert.Equal(t, []primitive.ObjectID{premiere.ID}, result[models.SessionTypePremiere])
+	assert.Equal(t, []primitive.ObjectID{regular.ID}, result[models.SessionTypeRegular])
+}
+
+func newMockDataAccessLayer() persistence.DataAccessLayer {
+	data, err := mongolayer.NewMongoDAL("mongodb://localhost/amenic-test")
+	if err != nil {
+		log.Fatal(err)
+	}
+	data.Setup()
+	return data
+}
+
+func TestGetUnmatched(t *testing.T) {
+	data := newMockDataAccessLayer()
+	defer data.Close()
+
+	scraper := models.Scraper{
+		ID:        primitive.NewObjectID(),
+		TheaterID: primitive.NewObjectID(),
+		Type:      scraperutil.TypeSchedule,
+	}
+	assert.NoError(t, data.InsertScraper(scraper))
+	defer data.DeleteScraper(scraper.ID.Hex())
+
+	slug := "unmatched" + primitive.NewObjectID().Hex()
+	newRun := func(resultCode string, start time.Time, unmatched ...models.UnmatchedMovie) models.ScraperRun {
+		return models.ScraperRun{
+			ID:         primitive.NewObjectID(),
+			ScraperID:  scraper.ID,
+			Scraper:    &scraper,
+			ResultCode: resultCode,
+			StartTime:  &start,
+			Unmatched:  unmatched,
+		}
+	}
+	now := time.Now().UTC()
+	success := newRun(scraperutil.RunResultSuccess, now.Add(-time.Hour), models.UnmatchedMovie{Title: "Unmatched", Slug: slug, Sessions: 2})
+	failed := newRun(scraperutil.RunResultHTTPError, now)
+	assert.NoError(t, data.InsertScraperRun(success))
+	// The failed run is the last one of the scraper.
+	assert.NoError(t, data.InsertScraperRun(failed))
+
+	result, err := GetUnmatched(data)
+	assert.NoError(t, err)
+	found := false
+	for _, g := range result {
+		if g.Slug == slug {
+			found = true
+			assert.Equal(t, success.ID.Hex(), g.RunID)
+			assert.Equal(t, 2, g.Sessions)
+		}
+	}
+	assert.True(t, found)
+}
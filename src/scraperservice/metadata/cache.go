@@ -0,0 +1,252 @@
+package metadata
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultCacheTTL is how long cached responses are used before asking the source again.
+const DefaultCacheTTL = 7 * 24 * time.Hour
+
+type (
+	// Cache wraps a source and keeps its responses on disk. Stale responses
+	// are still used if the source fails.
+	Cache struct {
+		Source MetadataSource
+		Dir    string
+		TTL    time.Duration
+
+		mu sync.Mutex
+	}
+
+	// CacheEntry describes a cached response.
+	CacheEntry struct {
+		Key       string    `json:"key"`        // Key identifies the request, eg: details/550
+		FetchedAt time.Time `json:"fetched_at"` // FetchedAt is the time the response was retrieved from the source
+		Age       float64   `json:"age"`        // Age of the response in seconds
+		Stale     bool      `json:"stale"`      // Stale indicates the response is older than the TTL
+	}
+
+	// CacheStats summarizes the cached responses.
+	CacheStats struct {
+		Source  string       `json:"source"`
+		Dir     string       `json:"dir"`
+		TTL     float64      `json:"ttl"`     // TTL in seconds
+		Total   int          `json:"total"`   // Total number of cached responses
+		Stale   int          `json:"stale"`   // Stale is the number of responses older than the TTL
+		Oldest  *time.Time   `json:"oldest"`  // Oldest is the fetch time of the oldest response
+		Entries []CacheEntry `json:"entries"` // Entries sorted from oldest to newest
+	}
+
+	cacheFile struct {
+		Key       string          `json:"key"`
+		FetchedAt time.Time       `json:"fetched_at"`
+		Data      json.RawMessage `json:"data"`
+	}
+)
+
+// NewCache creates a cache for the given source in dir.
+func NewCache(source MetadataSource, dir string, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Cache{
+		Source: source,
+		Dir:    dir,
+		TTL:    ttl,
+	}
+}
+
+// Name ...
+func (c *Cache) Name() string {
+	return c.Source.Name()
+}
+
+// Search ...
+func (c *Cache) Search(query string, year int) ([]SearchResult, error) {
+	var result []SearchResult
+	key := fmt.Sprintf("search/%s/%d", strings.ToLower(query), year)
+	err := c.get(key, &result, func() (interface{}, error) {
+		return c.Source.Search(query, year)
+	})
+	return result, err
+}
+
+// Details ...
+func (c *Cache) Details(id int) (*Details, error) {
+	var result *Details
+	err := c.get(DetailsKey(id), &result, func() (interface{}, error) {
+		return c.Source.Details(id)
+	})
+	return result, err
+}
+
+// Videos ...
+func (c *Cache) Videos(id int) ([]Video, error) {
+	var result []Video
+	err := c.get(fmt.Sprintf("videos/%d", id), &result, func() (interface{}, error) {
+		return c.Source.Videos(id)
+	})
+	return result, err
+}
+
+// ReleaseDates ...
+func (c *Cache) ReleaseDates(id int) ([]ReleaseDate, error) {
+	var result []ReleaseDate
+	err := c.get(fmt.Sprintf("release_dates/%d", id), &result, func() (interface{}, error) {
+		return c.Source.ReleaseDates(id)
+	})
+	return result, err
+}
+
+// ImageURL isn't cached since sources resolve it without a request per image.
+func (c *Cache) ImageURL(path, size string) (string, error) {
+	return c.Source.ImageURL(path, size)
+}
+
+// DetailsKey is the cache key of the details of a movie.
+func DetailsKey(id int) string {
+	return fmt.Sprintf("details/%d", id)
+}
+
+// Entry retrieves the cache entry of the given key.
+func (c *Cache) Entry(key string) (*CacheEntry, error) {
+	f, err := c.read(key)
+	if err != nil {
+		return nil, err
+	}
+	e := c.entry(f, time.Now())
+	return &e, nil
+}
+
+// Stats lists every cached response and how stale it is.
+func (c *Cache) Stats() (*CacheStats, error) {
+	result := &CacheStats{
+		Source:  c.Source.Name(),
+		Dir:     c.Dir,
+		TTL:     c.TTL.Seconds(),
+		Entries: make([]CacheEntry, 0),
+	}
+
+	files, err := ioutil.ReadDir(c.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, info := range files {
+		if info.IsDir() || filepath.Ext(info.Name()) != ".json" {
+			continue
+		}
+		f, err := c.readFile(filepath.Join(c.Dir, info.Name()))
+		if err != nil {
+			continue
+		}
+		e := c.entry(f, now)
+		result.Entries = append(result.Entries, e)
+		if e.Stale {
+			result.Stale++
+		}
+		if result.Oldest == nil || e.FetchedAt.Before(*result.Oldest) {
+			t := e.FetchedAt
+			result.Oldest = &t
+		}
+	}
+	result.Total = len(result.Entries)
+	sortEntries(result.Entries)
+	return result, nil
+}
+
+// get decodes the cached response of key into out, asking the source when
+// there's no fresh response.
+func (c *Cache) get(key string, out interface{}, fetch func() (interface{}, error)) error {
+	cached, err := c.read(key)
+	if err == nil && time.Since(cached.FetchedAt) < c.TTL {
+		return json.Unmarshal(cached.Data, out)
+	}
+
+	value, err := fetch()
+	if err != nil {
+		if cached != nil && err != ErrNotFound {
+			// Better stale than nothing.
+			return json.Unmarshal(cached.Data, out)
+		}
+		return err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	// The response is still good if it can't be cached.
+	if err := c.write(cacheFile{Key: key, FetchedAt: time.Now().UTC(), Data: data}); err != nil {
+		logrus.Warnf("couldn't cache metadata response %s: %s", key, err)
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (c *Cache) entry(f *cacheFile, now time.Time) CacheEntry {
+	age := now.Sub(f.FetchedAt)
+	return CacheEntry{
+		Key:       f.Key,
+		FetchedAt: f.FetchedAt,
+		Age:       age.Seconds(),
+		Stale:     age >= c.TTL,
+	}
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, fmt.Sprintf("%x.json", sha1.Sum([]byte(c.Source.Name()+"/"+key))))
+}
+
+func (c *Cache) read(key string) (*cacheFile, error) {
+	return c.readFile(c.path(key))
+}
+
+func (c *Cache) readFile(name string) (*cacheFile, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var result cacheFile
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Cache) write(f cacheFile) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path(f.Key), b, 0644)
+}
+
+func sortEntries(entries []CacheEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].FetchedAt.Before(entries[j].FetchedAt)
+	})
+}
@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSource counts the requests and fails when told to.
+type fakeSource struct {
+	Noop
+	requests int
+	fail     bool
+}
+
+func (s *fakeSource) Name() string { return "fake" }
+
+func (s *fakeSource) Details(id int) (*Details, error) {
+	s.requests++
+	if s.fail {
+		return nil, errors.New("offline")
+	}
+	return &Details{TmdbID: id, Title: "Coringa", Runtime: 122}, nil
+}
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metadata")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	source := &fakeSource{}
+	cache := NewCache(source, dir, time.Hour)
+
+	d, err := cache.Details(475557)
+	assert.NoError(t, err)
+	assert.Equal(t, "Coringa", d.Title)
+
+	// Fresh responses don't hit the source.
+	d, err = cache.Details(475557)
+	assert.NoError(t, err)
+	assert.Equal(t, 122, d.Runtime)
+	assert.Equal(t, 1, source.requests)
+
+	entry, err := cache.Entry(DetailsKey(475557))
+	assert.NoError(t, err)
+	assert.False(t, entry.Stale)
+
+	// Stale responses are used when the source fails.
+	cache.TTL = 0
+	source.fail = true
+	d, err = cache.Details(475557)
+	assert.NoError(t, err)
+	assert.Equal(t, "Coringa", d.Title)
+	assert.Equal(t, 2, source.requests)
+
+	stats, err := cache.Stats()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.Total)
+	assert.Equal(t, 1, stats.Stale)
+
+	_, err = cache.Details(1)
+	assert.Error(t, err)
+}
+
+func TestCacheWriteError(t *testing.T) {
+	f, err := ioutil.TempFile("", "metadata")
+	assert.NoError(t, err)
+	f.Close()
+	defer os.Remove(f.Name())
+
+	// The cache dir is a file, so responses can't be written.
+	source := &fakeSource{}
+	cache := NewCache(source, f.Name(), time.Hour)
+	d, err := cache.Details(475557)
+	assert.NoError(t, err)
+	assert.Equal(t, "Coringa", d.Title)
+}
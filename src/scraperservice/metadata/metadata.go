@@ -0,0 +1,150 @@
+package metadata
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SourceTMDb uses The Movie Database API.
+	SourceTMDb = "tmdb"
+	// SourceNone disables metadata, useful for offline runs.
+	SourceNone = "none"
+)
+
+var (
+	// ErrNoSource is returned when there's no metadata source configured.
+	ErrNoSource = errors.New("no metadata source configured")
+	// ErrNotFound is returned when the source doesn't have the requested movie.
+	ErrNotFound = errors.New("metadata not found")
+)
+
+type (
+	// MetadataSource provides movie metadata from an external service.
+	MetadataSource interface {
+		// Name is the identifier of the source.
+		Name() string
+
+		// Search finds movies by title released in the given year.
+		Search(query string, year int) ([]SearchResult, error)
+
+		// Details retrieves the details of the movie with the given source ID.
+		Details(id int) (*Details, error)
+
+		// Videos retrieves trailers, teasers and other videos of a movie.
+		Videos(id int) ([]Video, error)
+
+		// ReleaseDates retrieves the release date of a movie in each country.
+		ReleaseDates(id int) ([]ReleaseDate, error)
+
+		// ImageURL converts an image path returned by the source to a URL
+		// with the given size.
+		ImageURL(path, size string) (string, error)
+	}
+
+	// SearchResult is a movie found by Search.
+	SearchResult struct {
+		ID            int        `json:"id"`                     // ID of the movie in the source
+		Title         string     `json:"title"`                  // Title in the configured language
+		OriginalTitle string     `json:"original_title"`         // OriginalTitle of the movie
+		ReleaseDate   *time.Time `json:"release_date,omitempty"` // ReleaseDate is the primary release date
+		PosterPath    string     `json:"poster_path"`            // PosterPath is used with ImageURL
+	}
+
+	// Details is the metadata of a movie.
+	Details struct {
+		TmdbID        int      `json:"tmdb_id,omitempty"`
+		ImdbID        string   `json:"imdb_id,omitempty"`
+		Title         string   `json:"title"`
+		OriginalTitle string   `json:"original_title"`
+		Overview      string   `json:"overview"`
+		Runtime       int      `json:"runtime"`
+		Genres        []string `json:"genres"`
+		PosterPath    string   `json:"poster_path"`   // PosterPath is used with ImageURL
+		BackdropPath  string   `json:"backdrop_path"` // BackdropPath is used with ImageURL
+	}
+
+	// Video is a video of a movie hosted in an external site.
+	Video struct {
+		Key  string `json:"key"`  // Key is the video identifier in the site
+		Site string `json:"site"` // Site hosting the video, eg: YouTube
+		Type string `json:"type"` // Type of the video, eg: Trailer
+	}
+
+	// ReleaseDate is the release date of a movie in a country.
+	ReleaseDate struct {
+		Country string     `json:"country"` // Country is the ISO 3166-1 code
+		Date    *time.Time `json:"date"`
+	}
+)
+
+var (
+	defaultSource MetadataSource = Noop{}
+	defaultMu     sync.RWMutex
+)
+
+// Default returns the source used by extractors.
+func Default() MetadataSource {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultSource
+}
+
+// SetDefault changes the source used by extractors.
+func SetDefault(source MetadataSource) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if source == nil {
+		source = Noop{}
+	}
+	defaultSource = source
+}
+
+// New creates the source described by the given configuration. Runs without
+// a configured source don't fail, they just don't get metadata.
+func New(settings *config.ServiceConfig) MetadataSource {
+	var result MetadataSource
+	switch settings.MetadataSource {
+	case SourceNone:
+		return Noop{}
+	case SourceTMDb, "":
+		if settings.TMDbAPIKey == "" {
+			logrus.Warn("TMDB_API_KEY is missing, movies won't have metadata")
+			return Noop{}
+		}
+		result = NewTMDb(settings.TMDbAPIKey, settings.MetadataLanguage, settings.MetadataRegion, settings.MetadataTimeZone)
+	default:
+		logrus.Warnf("unknown metadata source '%s', movies won't have metadata", settings.MetadataSource)
+		return Noop{}
+	}
+
+	if settings.MetadataCacheDir != "" {
+		result = NewCache(result, settings.MetadataCacheDir, settings.MetadataCacheTTL)
+	}
+	return result
+}
+
+// Noop is a source without any metadata.
+type Noop struct{}
+
+// Name ...
+func (Noop) Name() string { return SourceNone }
+
+// Search ...
+func (Noop) Search(query string, year int) ([]SearchResult, error) { return nil, ErrNoSource }
+
+// Details ...
+func (Noop) Details(id int) (*Details, error) { return nil, ErrNoSource }
+
+// Videos ...
+func (Noop) Videos(id int) ([]Video, error) { return nil, ErrNoSource }
+
+// ReleaseDates ...
+func (Noop) ReleaseDates(id int) ([]ReleaseDate, error) { return nil, ErrNoSource }
+
+// ImageURL ...
+func (Noop) ImageURL(path, size string) (string, error) { return "", ErrNoSource }
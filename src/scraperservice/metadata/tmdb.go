@@ -0,0 +1,156 @@
+package metadata
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
+	tmdb "github.com/ryanbradynd05/go-tmdb"
+)
+
+// TMDb is a source backed by The Movie Database API.
+type TMDb struct {
+	Language string         // Language of titles and overviews
+	Region   string         // Region used to filter searches
+	Location *time.Location // Location used to parse release dates
+
+	api *tmdb.TMDb
+
+	mu     sync.Mutex
+	config *tmdb.Configuration // config is used to resolve image paths
+}
+
+// NewTMDb creates a TMDb source for the given language and region. Release
+// dates are parsed in the given time zone, see timeutil.LoadLocation.
+func NewTMDb(apiKey, language, region, timeZone string) *TMDb {
+	return &TMDb{
+		Language: language,
+		Region:   region,
+		Location: timeutil.LoadLocation(timeZone),
+		api:      tmdb.Init(tmdb.Config{APIKey: apiKey}),
+	}
+}
+
+// Name ...
+func (s *TMDb) Name() string {
+	return SourceTMDb
+}
+
+// Search ...
+func (s *TMDb) Search(query string, year int) ([]SearchResult, error) {
+	options := map[string]string{
+		"language": s.Language,
+		"region":   s.Region,
+	}
+	if year > 0 {
+		options["year"] = strconv.Itoa(year)
+	}
+	results, err := s.api.SearchMovie(query, options)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]SearchResult, len(results.Results))
+	for i, r := range results.Results {
+		result[i] = SearchResult{
+			ID:            r.ID,
+			Title:         r.Title,
+			OriginalTitle: r.OriginalTitle,
+			ReleaseDate:   s.parseDate(r.ReleaseDate),
+			PosterPath:    r.PosterPath,
+		}
+	}
+	return result, nil
+}
+
+// Details ...
+func (s *TMDb) Details(id int) (*Details, error) {
+	info, err := s.api.GetMovieInfo(id, map[string]string{"language": s.Language})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Details{
+		TmdbID:        info.ID,
+		ImdbID:        info.ImdbID,
+		Title:         info.Title,
+		OriginalTitle: info.OriginalTitle,
+		Overview:      info.Overview,
+		Runtime:       int(info.Runtime),
+		Genres:        make([]string, len(info.Genres)),
+		PosterPath:    info.PosterPath,
+		BackdropPath:  info.BackdropPath,
+	}
+	for i, genre := range info.Genres {
+		result.Genres[i] = genre.Name
+	}
+	sort.Strings(result.Genres)
+	return result, nil
+}
+
+// Videos ...
+func (s *TMDb) Videos(id int) ([]Video, error) {
+	videos, err := s.api.GetMovieVideos(id, map[string]string{"language": s.Language})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Video, len(videos.Results))
+	for i, v := range videos.Results {
+		result[i] = Video{
+			Key:  v.Key,
+			Site: v.Site,
+			Type: v.Type,
+		}
+	}
+	return result, nil
+}
+
+// ReleaseDates ...
+func (s *TMDb) ReleaseDates(id int) ([]ReleaseDate, error) {
+	releases, err := s.api.GetMovieReleases(id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ReleaseDate, 0, len(releases.Countries))
+	for _, c := range releases.Countries {
+		date := s.parseDate(c.ReleaseDate)
+		if date == nil {
+			continue
+		}
+		result = append(result, ReleaseDate{
+			Country: c.Iso3166_1,
+			Date:    date,
+		})
+	}
+	return result, nil
+}
+
+// ImageURL ...
+func (s *TMDb) ImageURL(path, size string) (string, error) {
+	if path == "" {
+		return "", ErrNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.config == nil {
+		config, err := s.api.GetConfiguration()
+		if err != nil {
+			return "", err
+		}
+		s.config = config
+	}
+	return s.config.Images.SecureBaseURL + size + path, nil
+}
+
+func (s *TMDb) parseDate(value string) *time.Time {
+	t, err := time.ParseInLocation("2006-01-02", value, s.Location)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
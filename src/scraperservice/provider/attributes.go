@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"strings"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+)
+
+// attributeMappings maps the values each provider uses for formats, versions
+// and rooms to the attribute catalog. Values mapped to an empty string are
+// known but don't describe anything special, eg: standard seats.
+var attributeMappings = map[string]map[string]string{
+	ProviderCinemais: {
+		"2d":                   models.Attribute2D,
+		"3d":                   models.Attribute3D,
+		"dubbed":               models.AttributeDubbed,
+		"dublado":              models.AttributeDubbed,
+		"subbed":               models.AttributeSubtitled,
+		"subtitled":            models.AttributeSubtitled,
+		"legendado":            models.AttributeSubtitled,
+		"national":             models.AttributeNational,
+		"nacional":             models.AttributeNational,
+		"magic d":              models.AttributeMagicD,
+		"poltrona vip":         models.AttributeVIP,
+		"vip":                  models.AttributeVIP,
+		"poltrona tradicional": "",
+		"imax":                 models.AttributeIMAX,
+		"d-box":                models.AttributeDBOX,
+		"dbox":                 models.AttributeDBOX,
+		"audiodescrição":       models.AttributeAudioDescription,
+		"audiodescricao":       models.AttributeAudioDescription,
+		"libras":               models.AttributeLibras,
+		"legenda descritiva":   models.AttributeClosedCaption,
+	},
+	ProviderIbicinemas: {
+		"2d":        models.Attribute2D,
+		"3d":        models.Attribute3D,
+		"dubbed":    models.AttributeDubbed,
+		"dublado":   models.AttributeDubbed,
+		"subbed":    models.AttributeSubtitled,
+		"subtitled": models.AttributeSubtitled,
+		"legendado": models.AttributeSubtitled,
+		"national":  models.AttributeNational,
+		"nacional":  models.AttributeNational,
+	},
+}
+
+// MapAttributes converts the values of the given provider to attributes of
+// the catalog. Unknown values are dropped.
+func MapAttributes(provider string, values ...string) []string {
+	mapping := attributeMappings[provider]
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		id, ok := mapping[strings.ToLower(strings.TrimSpace(v))]
+		if ok && id != "" {
+			result = append(result, id)
+		}
+	}
+	return models.SortAttributes(result)
+}
+
+// sessionAttributes adds the attributes of the theater room of the session
+// to the ones the provider reported. Providers don't report the room kind of
+// sessions, only of prices.
+func sessionAttributes(theater *models.Theater, room uint, attrs []string) []string {
+	if theater == nil {
+		return attrs
+	}
+	return models.SortAttributes(append(attrs, theater.GetRoomAttributes(room)...))
+}
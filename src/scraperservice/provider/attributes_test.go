@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapAttributes(t *testing.T) {
+	attrs := MapAttributes(ProviderCinemais, "Poltrona VIP", "subbed", "3D", "Magic D", "Poltrona Tradicional")
+	assert.Equal(t, []string{models.Attribute3D, models.AttributeSubtitled, models.AttributeVIP, models.AttributeMagicD}, attrs)
+
+	attrs = MapAttributes(ProviderIbicinemas, "2D", "dubbed", "unknown")
+	assert.Equal(t, []string{models.Attribute2D, models.AttributeDubbed}, attrs)
+
+	assert.Empty(t, MapAttributes("unknown", "2D"))
+}
+
+func TestSessionAttributes(t *testing.T) {
+	theater := &models.Theater{
+		Rooms: []models.TheaterRoom{
+			{Number: 3, Attributes: []string{models.AttributeVIP, models.AttributeMagicD}},
+		},
+	}
+	reported := MapAttributes(ProviderCinemais, "3D", "subbed")
+
+	attrs := sessionAttributes(theater, 3, reported)
+	assert.Equal(t, []string{models.Attribute3D, models.AttributeSubtitled, models.AttributeVIP, models.AttributeMagicD}, attrs)
+
+	attrs = sessionAttributes(theater, 1, reported)
+	assert.Equal(t, []string{models.Attribute3D, models.AttributeSubtitled}, attrs)
+}
@@ -1,14 +1,13 @@
 package provider
 
 import (
-	"fmt"
-	"strconv"
 	"sync"
 	"time"
 
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/movieutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
 	"github.com/dsbezerra/cinemais"
 )
 
@@ -55,7 +54,7 @@ func (c *Cinemais) Init(data persistence.DataAccessLayer) error {
 		AddInclude("city")
 	theater, err := data.FindTheater(query)
 	if err != nil {
-		return err
+		return theaterError(err)
 	}
 	c.t = theater
 	return nil
@@ -148,6 +147,7 @@ func (c *Cinemais) mapPrices(prices []cinemais.Price) []models.Price {
 
 func (c *Cinemais) mapPrice(price cinemais.Price) models.Price {
 	timestamp := time.Now()
+	attrs := MapAttributes(ProviderCinemais, price.Attributes...)
 	return models.Price{
 		TheaterID:         c.t.ID,
 		Label:             price.Label,
@@ -158,8 +158,8 @@ func (c *Cinemais) mapPrice(price cinemais.Price) models.Price {
 		ExceptPreviews:    price.ExceptPreviews,
 		IncludingHolidays: price.IncludingHolidays,
 		IncludingPreviews: price.IncludingPreviews,
-		Attributes:        price.Attributes,
-		Weight:            getWeightForAttributes(price.Attributes),
+		Attributes:        attrs,
+		Weight:            models.GetAttributesWeight(attrs),
 		CreatedAt:         &timestamp,
 	}
 }
@@ -170,8 +170,10 @@ func (c *Cinemais) mapSession(s cinemais.Session) models.Session {
 	if c.t.City != nil {
 		tz = c.t.City.TimeZone
 	}
-	date, _ := strconv.Atoi(fmt.Sprintf("%d%02d%02d", s.StartTime.Year(), int(s.StartTime.Month()), s.StartTime.Day()))
+	// The day of the session is the one in the theater city, whatever the
+	// zone the provider reported the start time in.
 	utc := s.StartTime.UTC()
+	date := timeutil.DateIn(utc, c.t.City.Location())
 	return models.Session{
 		TheaterID:   c.t.ID,
 		Movie:       &m,
@@ -183,6 +185,7 @@ func (c *Cinemais) mapSession(s cinemais.Session) models.Session {
 		Room:        s.Room,
 		Version:     s.Version,
 		Format:      s.Format,
+		Attributes:  sessionAttributes(c.t, s.Room, MapAttributes(ProviderCinemais, s.Format, s.Version)),
 	}
 }
 
@@ -193,30 +196,3 @@ func (c *Cinemais) mapSessions(sessions []cinemais.Session) []models.Session {
 	}
 	return result
 }
-
-func getWeightForAttributes(attrs []string) uint {
-	equal := func(a, b []string) bool {
-		if len(a) != len(b) {
-			return false
-		}
-		for i, v := range a {
-			if v != b[i] {
-				return false
-			}
-		}
-		return true
-	}
-
-	if equal(attrs, []string{"2D"}) {
-		return 1
-	} else if equal(attrs, []string{"3D"}) {
-		return 2
-	} else if equal(attrs, []string{"2D", "Magic D", "Poltrona Tradicional"}) {
-		return 3
-	} else if equal(attrs, []string{"3D", "Magic D", "Poltrona Tradicional"}) {
-		return 4
-	} else if equal(attrs, []string{"2D", "3D", "Magic D", "Poltrona VIP"}) {
-		return 5
-	}
-	return 0
-}
@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrorKind classifies errors returned by providers.
+type ErrorKind string
+
+const (
+	// ErrorTimeout indicates the provider didn't respond in time.
+	ErrorTimeout ErrorKind = "timeout"
+	// ErrorNetwork indicates the provider couldn't be reached.
+	ErrorNetwork ErrorKind = "network"
+	// ErrorHTTPStatus indicates the provider responded with an unexpected HTTP status.
+	ErrorHTTPStatus ErrorKind = "http_status"
+	// ErrorParse indicates the provider's response couldn't be parsed.
+	ErrorParse ErrorKind = "parse"
+	// ErrorTheaterNotFound indicates the theater of the provider couldn't be found.
+	ErrorTheaterNotFound ErrorKind = "theater_not_found"
+	// ErrorUnknown is used for everything else.
+	ErrorUnknown ErrorKind = "unknown"
+)
+
+// ErrUnknownProvider is returned when no provider is registered with the given name.
+var ErrUnknownProvider = errors.New("couldn't run scraper for the specified provider")
+
+// Error is a classified provider error.
+type Error struct {
+	Kind       ErrorKind
+	StatusCode int // StatusCode is only set for ErrorHTTPStatus
+	Err        error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Kind == ErrorHTTPStatus {
+		return fmt.Sprintf("%s (status %d): %v", e.Kind, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+}
+
+// Unwrap returns the original error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ResultCode maps the error to the ScraperRun result code.
+func (e *Error) ResultCode() string {
+	switch e.Kind {
+	case ErrorTimeout:
+		return scraperutil.RunResultTimeout
+	case ErrorNetwork:
+		return scraperutil.RunResultNetworkError
+	case ErrorHTTPStatus:
+		return scraperutil.RunResultHTTPError
+	case ErrorParse:
+		return scraperutil.RunResultParseError
+	case ErrorTheaterNotFound:
+		return scraperutil.RunResultTheaterNotFound
+	}
+	return scraperutil.RunResultError
+}
+
+// NewHTTPStatusError creates an error for an unexpected HTTP status.
+func NewHTTPStatusError(statusCode int) *Error {
+	return &Error{
+		Kind:       ErrorHTTPStatus,
+		StatusCode: statusCode,
+		Err:        errors.New("unexpected response status"),
+	}
+}
+
+// theaterError converts errors of the lookup of the provider's theater. A
+// missing theater is a classified error, anything else is returned as is.
+func theaterError(err error) error {
+	if err == mongo.ErrNoDocuments {
+		return &Error{Kind: ErrorTheaterNotFound, Err: err}
+	}
+	return err
+}
+
+// Classify converts any error returned by a provider to an *Error. Providers
+// return *Error for the failures they detect themselves, the others are
+// classified by their type.
+func Classify(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var perr *Error
+	if errors.As(err, &perr) {
+		return perr
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Kind: ErrorTimeout, Err: err}
+	}
+
+	var nerr net.Error
+	if errors.As(err, &nerr) && nerr.Timeout() {
+		return &Error{Kind: ErrorTimeout, Err: err}
+	}
+
+	var uerr *url.Error
+	var operr *net.OpError
+	if errors.As(err, &uerr) || errors.As(err, &operr) {
+		return &Error{Kind: ErrorNetwork, Err: err}
+	}
+
+	var serr *json.SyntaxError
+	var terr *json.UnmarshalTypeError
+	var numerr *strconv.NumError
+	if errors.As(err, &serr) || errors.As(err, &terr) || errors.As(err, &numerr) {
+		return &Error{Kind: ErrorParse, Err: err}
+	}
+
+	return &Error{Kind: ErrorUnknown, Err: err}
+}
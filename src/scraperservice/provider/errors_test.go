@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestClassify(t *testing.T) {
+	assert.Nil(t, Classify(nil))
+
+	err := Classify(fmt.Errorf("get schedule: %w", context.DeadlineExceeded))
+	assert.Equal(t, ErrorTimeout, err.Kind)
+	assert.Equal(t, scraperutil.RunResultTimeout, err.ResultCode())
+
+	// Only missing theaters of providers are classified as such.
+	err = Classify(theaterError(mongo.ErrNoDocuments))
+	assert.Equal(t, ErrorTheaterNotFound, err.Kind)
+	assert.Equal(t, scraperutil.RunResultTheaterNotFound, err.ResultCode())
+	assert.Equal(t, ErrorUnknown, Classify(fmt.Errorf("get movie: %w", mongo.ErrNoDocuments)).Kind)
+
+	_, perr := strconv.Atoi("abc")
+	err = Classify(perr)
+	assert.Equal(t, ErrorParse, err.Kind)
+
+	err = Classify(fmt.Errorf("get schedule: %w", NewHTTPStatusError(503)))
+	assert.Equal(t, ErrorHTTPStatus, err.Kind)
+	assert.Equal(t, 503, err.StatusCode)
+
+	// Messages aren't parsed.
+	assert.Equal(t, ErrorUnknown, Classify(errors.New("unexpected status code: 503")).Kind)
+
+	err = Classify(errors.New("something else"))
+	assert.Equal(t, scraperutil.RunResultError, err.ResultCode())
+
+	// Already classified errors are kept as is.
+	assert.Equal(t, ErrorHTTPStatus, Classify(NewHTTPStatusError(404)).Kind)
+}
+
+func TestClassifyHTTPStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// Other hosts get their responses as is.
+	res, err := http.Get(server.URL)
+	if assert.NoError(t, err) {
+		res.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	}
+
+	u, _ := url.Parse(server.URL)
+	providerHosts = append(providerHosts, u.Hostname())
+	defer func() { providerHosts = providerHosts[:len(providerHosts)-1] }()
+
+	_, err = http.Get(server.URL + "/programacao")
+	perr := Classify(err)
+	assert.Equal(t, ErrorHTTPStatus, perr.Kind)
+	assert.Equal(t, http.StatusServiceUnavailable, perr.StatusCode)
+	assert.Equal(t, scraperutil.RunResultHTTPError, perr.ResultCode())
+
+	assert.True(t, isProviderHost("www.cinemais.com.br"))
+	assert.False(t, isProviderHost("notcinemais.com.br"))
+}
@@ -1,8 +1,6 @@
 package provider
 
 import (
-	"fmt"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -10,6 +8,7 @@ import (
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/movieutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/timeutil"
 	"github.com/dsbezerra/ibicinemas"
 	"github.com/sirupsen/logrus"
 )
@@ -37,7 +36,7 @@ func (i *Ibicinemas) Init(data persistence.DataAccessLayer) error {
 		AddInclude("city")
 	theater, err := data.FindTheater(query)
 	if err != nil {
-		return err
+		return theaterError(err)
 	}
 	i.t = theater
 	return nil
@@ -74,19 +73,16 @@ func (i *Ibicinemas) GetPrices() ([]models.Price, error) {
 	// Mapping prices result to amenic Price model
 	result := make([]models.Price, 0)
 	for _, p := range prices {
-		var weight uint
 		var label string
 		var attrs []string
 
 		switch p.Projection {
 		case ibicinemas.Projection2D:
 			label = "Projeção 2D"
-			attrs = []string{"2D"}
-			weight = 1
+			attrs = []string{models.Attribute2D}
 		case ibicinemas.Projection3D:
 			label = "Projeção 3D"
-			attrs = []string{"3D"}
-			weight = 2
+			attrs = []string{models.Attribute3D}
 		default:
 			// TODO: logging
 			continue
@@ -119,7 +115,7 @@ func (i *Ibicinemas) GetPrices() ([]models.Price, error) {
 				IncludingHolidays: includingHolidays,
 				IncludingPreviews: includingPreviews,
 				Attributes:        attrs,
-				Weight:            weight,
+				Weight:            models.GetAttributesWeight(attrs),
 				CreatedAt:         &timestamp,
 			})
 		}
@@ -174,8 +170,10 @@ func (i *Ibicinemas) mapSession(s ibicinemas.Session) models.Session {
 	if i.t.City != nil {
 		tz = i.t.City.TimeZone
 	}
-	date, _ := strconv.Atoi(fmt.Sprintf("%d%02d%02d", s.StartTime.Year(), int(s.StartTime.Month()), s.StartTime.Day()))
+	// The day of the session is the one in the theater city, whatever the
+	// zone the provider reported the start time in.
 	utc := s.StartTime.UTC()
+	date := timeutil.DateIn(utc, i.t.City.Location())
 	return models.Session{
 		TheaterID:   i.t.ID,
 		Movie:       &m,
@@ -187,6 +185,7 @@ func (i *Ibicinemas) mapSession(s ibicinemas.Session) models.Session {
 		Room:        uint(s.Room),
 		Version:     s.Version,
 		Format:      s.Format,
+		Attributes:  sessionAttributes(i.t, uint(s.Room), MapAttributes(ProviderIbicinemas, s.Format, s.Version)),
 	}
 }
 
@@ -3,15 +3,29 @@ package provider
 import (
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
 )
 
 const (
 	// ProviderCinemais ...
-	ProviderCinemais = "cinemais"
+	ProviderCinemais = scraperutil.ProviderCinemais
 	// ProviderIbicinemas ...
-	ProviderIbicinemas = "ibicinemas"
+	ProviderIbicinemas = scraperutil.ProviderIbicinemas
 )
 
+// Hosts maps each provider to the host its data is scraped from. Providers
+// are registered in scraperutil, so services validating scrapers don't
+// depend on their implementation.
+var Hosts = scraperutil.Providers
+
+// HostOf returns the host of the given provider, falling back to its name.
+func HostOf(name string) string {
+	if host, ok := Hosts[name]; ok {
+		return host
+	}
+	return name
+}
+
 // Provider ...
 type Provider interface {
 	Init(data persistence.DataAccessLayer) error
@@ -22,10 +36,10 @@ type Provider interface {
 	GetPrices() ([]models.Price, error)
 }
 
-// NewProvider ...
-func NewProvider(data persistence.DataAccessLayer, name string, id string) Provider {
+// NewProvider creates and initializes the provider with the given name.
+// Initialization errors are returned already classified.
+func NewProvider(data persistence.DataAccessLayer, name string, id string) (Provider, error) {
 	var p Provider
-	var err error
 
 	switch name {
 	case ProviderCinemais:
@@ -33,11 +47,12 @@ func NewProvider(data persistence.DataAccessLayer, name string, id string) Provi
 	case ProviderIbicinemas:
 		p = NewIbicinemas()
 	}
-	if p != nil {
-		err = p.Init(data)
+	if p == nil {
+		return nil, ErrUnknownProvider
 	}
+	err := p.Init(data)
 	if err != nil {
-		return nil
+		return nil, Classify(err)
 	}
-	return p
+	return p, nil
 }
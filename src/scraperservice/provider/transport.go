@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"net/http"
+	"strings"
+)
+
+// providerHosts are the sites the provider clients request. Their clients
+// don't expose the HTTP status of failed requests, so responses of these
+// hosts with an error status are returned as ErrorHTTPStatus errors.
+var providerHosts = []string{"cinemais.com.br", "ibicinemas.com.br"}
+
+// statusTransport fails requests to provider hosts answered with an error
+// status. Redirects are left to the client.
+type statusTransport struct {
+	base http.RoundTripper
+}
+
+func init() {
+	// The provider clients use the default transport.
+	http.DefaultTransport = &statusTransport{base: http.DefaultTransport}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil || res.StatusCode < http.StatusBadRequest || !isProviderHost(req.URL.Hostname()) {
+		return res, err
+	}
+	res.Body.Close()
+	return nil, NewHTTPStatusError(res.StatusCode)
+}
+
+func isProviderHost(host string) bool {
+	for _, h := range providerHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,31 +1,139 @@
 package queue
 
 import (
+	"sync"
+	"time"
+
 	"github.com/dsbezerra/amenic-lambda/src/lib/messagequeue"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/sirupsen/logrus"
 )
 
-// WorkerQueue ...
-var WorkerQueue chan chan WorkRequest
+// pollInterval is how often idle workers check the database for jobs that
+// were delayed or queued by another process.
+const pollInterval = 5 * time.Second
+
+// Queue is a durable scraper work queue backed by the database.
+//
+// Jobs are deduplicated by scraper, claimed by priority and limited by
+// provider host so we don't hammer a single website.
+type Queue struct {
+	data      persistence.DataAccessLayer
+	emitter   messagequeue.EventEmitter
+	log       *logrus.Entry
+	hostLimit int
+
+	mu       sync.Mutex
+	hosts    map[string]int  // running jobs per provider host
+	scrapers map[string]bool // scrapers currently running
+
+	wake chan struct{}
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
 
-// SetupWorkerQueue ...
-func SetupWorkerQueue(data persistence.DataAccessLayer, eventEmitter messagequeue.EventEmitter, nworkers int) {
-	WorkerQueue = make(chan chan WorkRequest, nworkers)
+// defaultQueue is used by AddWork.
+var defaultQueue *Queue
+
+// SetupWorkerQueue creates the queue, recovers jobs interrupted by a previous
+// shutdown and starts nworkers workers.
+func SetupWorkerQueue(data persistence.DataAccessLayer, eventEmitter messagequeue.EventEmitter, nworkers, hostLimit int) *Queue {
+	q := &Queue{
+		data:      data,
+		emitter:   eventEmitter,
+		log:       logrus.WithField("queue", "scraper"),
+		hostLimit: hostLimit,
+		hosts:     make(map[string]int),
+		scrapers:  make(map[string]bool),
+		wake:      make(chan struct{}, nworkers),
+		quit:      make(chan struct{}),
+	}
+
+	n, err := data.ResetScraperJobs()
+	if err != nil {
+		q.log.Errorf("couldn't reset interrupted jobs: %s", err.Error())
+	} else if n > 0 {
+		q.log.Infof("%d interrupted jobs were queued again", n)
+	}
 
 	for i := 0; i < nworkers; i++ {
-		worker := NewWorker(data, eventEmitter, i+1, WorkerQueue)
+		worker := NewWorker(q, i+1)
 		worker.Start()
 	}
 
+	defaultQueue = q
+	return q
+}
+
+// Drain stops workers from claiming new jobs and waits for running jobs to
+// finish, up to the given timeout. Pending jobs stay in the database.
+func (q *Queue) Drain(timeout time.Duration) {
+	close(q.quit)
+
+	done := make(chan struct{})
 	go func() {
-		for {
-			select {
-			case work := <-WorkQueue:
-				go func() {
-					worker := <-WorkerQueue
-					worker <- work
-				}()
-			}
-		}
+		q.wg.Wait()
+		close(done)
 	}()
+
+	select {
+	case <-done:
+		q.log.Info("Queue drained.")
+	case <-time.After(timeout):
+		q.log.Warn("Timeout reached while draining queue. Running jobs will be queued again on next start.")
+	}
+}
+
+// notify wakes up an idle worker, if any.
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// claim returns the next job a worker can run, respecting host limits.
+func (q *Queue) claim() *models.ScraperJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	excludeHosts := make([]string, 0)
+	for host, n := range q.hosts {
+		if n >= q.hostLimit {
+			excludeHosts = append(excludeHosts, host)
+		}
+	}
+	excludeScrapers := make([]string, 0, len(q.scrapers))
+	for id := range q.scrapers {
+		excludeScrapers = append(excludeScrapers, id)
+	}
+
+	job, err := q.data.ClaimScraperJob(excludeHosts, excludeScrapers)
+	if err != nil || job == nil {
+		return nil
+	}
+
+	q.hosts[job.Host]++
+	q.scrapers[job.ScraperID.Hex()] = true
+	return job
+}
+
+// release frees the host and scraper slots of the given job.
+func (q *Queue) release(job *models.ScraperJob) {
+	q.mu.Lock()
+	q.hosts[job.Host]--
+	if q.hosts[job.Host] <= 0 {
+		delete(q.hosts, job.Host)
+	}
+	delete(q.scrapers, job.ScraperID.Hex())
+	q.mu.Unlock()
+
+	err := q.data.DeleteScraperJob(job.ID.Hex())
+	if err != nil {
+		q.log.Errorf("couldn't delete job %s: %s", job.ID.Hex(), err.Error())
+	}
+
+	// Another job may be waiting for this host.
+	q.notify()
 }
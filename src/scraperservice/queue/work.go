@@ -2,24 +2,69 @@ package queue
 
 import (
 	"errors"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/provider"
 )
 
-var WorkQueue = make(chan WorkRequest, 100)
+const (
+	// PriorityCron is used by scheduled runs.
+	PriorityCron = 0
+	// PriorityManual is used by runs requested by an admin, so they go ahead of scheduled ones.
+	PriorityManual = 10
+)
+
+var (
+	// ErrInvalidScraperID is returned when queueing work without a scraper.
+	ErrInvalidScraperID = errors.New("invalid scraper id")
+	// ErrQueueNotReady is returned when queueing work before SetupWorkerQueue.
+	ErrQueueNotReady = errors.New("work queue is not ready")
+)
 
 type WorkRequest struct {
 	ScraperID     string
 	IgnoreLastRun bool
+	Priority      int        // Priority of the work, higher runs first
+	Attempt       int        // Attempt is the attempt number of a retry
+	RetryOf       string     // RetryOf is the ID of the run being retried
+	NotBefore     *time.Time // NotBefore delays the work until the given time
 }
 
+// AddWork adds the given work to the default queue.
 func AddWork(wr WorkRequest) error {
+	if defaultQueue == nil {
+		return ErrQueueNotReady
+	}
+	return defaultQueue.Add(wr)
+}
+
+// Add persists the given work in the queue. Work for a scraper that is
+// already pending is merged into the existing job.
+func (q *Queue) Add(wr WorkRequest) error {
 	if wr.ScraperID == "" {
-		// TODO: Improve
-		return errors.New("invalid scraper id")
+		return ErrInvalidScraperID
+	}
+
+	scraper, err := q.data.GetScraper(wr.ScraperID, q.data.DefaultQuery())
+	if err != nil {
+		return err
 	}
 
-	if WorkQueue != nil {
-		WorkQueue <- wr
+	err = q.data.EnqueueScraperJob(models.ScraperJob{
+		ScraperID:     scraper.ID,
+		Host:          provider.HostOf(scraper.Provider),
+		Status:        models.ScraperJobPending,
+		Priority:      wr.Priority,
+		IgnoreLastRun: wr.IgnoreLastRun,
+		Attempt:       wr.Attempt,
+		RetryOf:       wr.RetryOf,
+		NotBefore:     wr.NotBefore,
+	})
+	if err != nil {
+		return err
 	}
 
+	q.notify()
 	return nil
 }
@@ -1,61 +1,111 @@
 package queue
 
 import (
+	"time"
+
 	"github.com/dsbezerra/amenic-lambda/src/contracts"
-	"github.com/dsbezerra/amenic-lambda/src/lib/messagequeue"
-	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
 	"github.com/dsbezerra/amenic-lambda/src/scraperservice/task"
+	"github.com/sirupsen/logrus"
 )
 
-func NewWorker(data persistence.DataAccessLayer, eventEmitter messagequeue.EventEmitter, id int, workerQueue chan chan WorkRequest) Worker {
+func NewWorker(q *Queue, id int) Worker {
 	worker := Worker{
-		Data:         data,
-		EventEmitter: eventEmitter,
-		ID:           id,
-		Work:         make(chan WorkRequest),
-		WorkerQueue:  workerQueue,
-		QuitChan:     make(chan bool)}
+		ID:    id,
+		Queue: q,
+		Log:   q.log.WithField("worker", id),
+	}
 
 	return worker
 }
 
 type Worker struct {
-	Data         persistence.DataAccessLayer
-	EventEmitter messagequeue.EventEmitter
-	ID           int
-	Work         chan WorkRequest
-	WorkerQueue  chan chan WorkRequest
-	QuitChan     chan bool
+	ID    int
+	Queue *Queue
+	Log   *logrus.Entry
 }
 
+// Start runs jobs until the queue is drained.
 func (w *Worker) Start() {
+	q := w.Queue
+	q.wg.Add(1)
 	go func() {
+		defer q.wg.Done()
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
 		for {
-			w.WorkerQueue <- w.Work
 			select {
-			case work := <-w.Work:
-				opts := task.ScraperOptions{
-					ScraperID:     work.ScraperID,
-					IgnoreLastRun: work.IgnoreLastRun,
-				}
-				run, err := task.StartScraper(w.Data, opts)
-				if err != nil {
-					// p.Log.Errorln(err.Error())
-					return
-				}
-				w.EventEmitter.Emit(&contracts.EventScraperFinished{
-					Type:      run.Scraper.Type,
-					ScraperID: opts.ScraperID,
-				})
-			case <-w.QuitChan:
+			case <-q.quit:
+				return
+			default:
+			}
+
+			job := q.claim()
+			if job != nil {
+				w.run(job)
+				continue
+			}
+
+			select {
+			case <-q.quit:
 				return
+			case <-q.wake:
+			case <-ticker.C:
 			}
 		}
 	}()
 }
 
-func (w *Worker) Stop() {
-	go func() {
-		w.QuitChan <- true
-	}()
+func (w *Worker) run(job *models.ScraperJob) {
+	opts := task.ScraperOptions{
+		ScraperID:     job.ScraperID.Hex(),
+		IgnoreLastRun: job.IgnoreLastRun,
+		Attempt:       job.Attempt,
+		RetryOf:       job.RetryOf,
+	}
+	run, err := task.StartScraper(w.Queue.data, opts)
+
+	// The job must be gone before queueing a retry, otherwise the retry
+	// would be merged into it.
+	w.Queue.release(job)
+
+	if err != nil {
+		w.Log.Errorf("scraper %s failed: %s", opts.ScraperID, err.Error())
+		return
+	}
+	w.Queue.emitter.Emit(&contracts.EventScraperFinished{
+		Type:      run.Scraper.Type,
+		ScraperID: opts.ScraperID,
+	})
+	w.scheduleRetry(job, run)
+}
+
+// scheduleRetry queues the work again with the backoff delay if the run
+// failed and the scraper's retry policy allows it.
+func (w *Worker) scheduleRetry(job *models.ScraperJob, run *models.ScraperRun) {
+	policy := scraperutil.GetRetryPolicy(run.Scraper)
+	if !scraperutil.ShouldRetry(policy, run) {
+		return
+	}
+
+	// Retries always point to the first run.
+	original := run.RetryOf
+	if original.IsZero() {
+		original = run.ID
+	}
+
+	notBefore := time.Now().UTC().Add(scraperutil.RetryDelay(policy, run.Attempt))
+	err := w.Queue.Add(WorkRequest{
+		ScraperID:     job.ScraperID.Hex(),
+		IgnoreLastRun: job.IgnoreLastRun,
+		Priority:      job.Priority,
+		Attempt:       run.Attempt + 1,
+		RetryOf:       original.Hex(),
+		NotBefore:     &notBefore,
+	})
+	if err != nil {
+		w.Log.Errorf("couldn't queue retry for scraper %s: %s", job.ScraperID.Hex(), err.Error())
+	}
 }
@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/matcher"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MatchService ...
+type MatchService struct {
+	data persistence.DataAccessLayer
+}
+
+// ServeMatches ...
+func (rs *Service) ServeMatches(r *gin.Engine) {
+	s := &MatchService{rs.data}
+	matches := r.Group("/matches", rest.AdminAuth(rs.data))
+	matches.GET("", s.GetAll)
+	matches.GET("/match/:id", s.Get)
+	matches.POST("/match/:id/confirm", s.Confirm)
+	matches.POST("/match/:id/reassign", s.Reassign)
+	matches.POST("/match/:id/create", s.Create)
+	matches.GET("/unmatched", s.GetUnmatched)
+	matches.POST("/unmatched/bind", s.Bind)
+	matches.GET("/aliases", s.GetAliases)
+	matches.DELETE("/alias/:id", s.DeleteAlias)
+}
+
+// GetAll lists matches waiting for review, or with the status given in the query.
+func (s *MatchService) GetAll(c *gin.Context) {
+	status := c.DefaultQuery("status", models.PendingMatchPending)
+	matches, err := s.data.GetPendingMatches(s.data.DefaultQuery().
+		AddCondition("status", status).
+		SetSort("-updated_at").
+		SetLimit(-1))
+	apiutil.SendSuccessOrError(c, matches, err)
+}
+
+// Get ...
+func (s *MatchService) Get(c *gin.Context) {
+	match, err := s.data.GetPendingMatch(c.Param("id"), s.data.DefaultQuery())
+	apiutil.SendSuccessOrError(c, match, err)
+}
+
+// Confirm resolves the match to its best candidate.
+func (s *MatchService) Confirm(c *gin.Context) {
+	match, err := matcher.Confirm(s.data, c.Param("id"))
+	s.sendReviewResult(c, match, err)
+}
+
+// Reassign resolves the match to the movie given in the body.
+func (s *MatchService) Reassign(c *gin.Context) {
+	var body struct {
+		MovieID string `json:"movie_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	match, err := matcher.Reassign(s.data, c.Param("id"), body.MovieID)
+	s.sendReviewResult(c, match, err)
+}
+
+// Create inserts the scraped movie as a new movie.
+func (s *MatchService) Create(c *gin.Context) {
+	match, err := matcher.Create(s.data, c.Param("id"))
+	s.sendReviewResult(c, match, err)
+}
+
+func (s *MatchService) sendReviewResult(c *gin.Context, match *models.PendingMatch, err error) {
+	if err == matcher.ErrMatchNotPending || err == matcher.ErrNoCandidates {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	apiutil.SendSuccessOrError(c, match, err)
+}
+
+// GetUnmatched lists titles whose sessions have no movie, grouped by title and theater.
+func (s *MatchService) GetUnmatched(c *gin.Context) {
+	groups, err := matcher.GetUnmatched(s.data)
+	apiutil.SendSuccessOrError(c, groups, err)
+}
+
+// Bind binds a title to an existing movie.
+func (s *MatchService) Bind(c *gin.Context) {
+	var body struct {
+		Slug    string `json:"slug" binding:"required"`
+		Title   string `json:"title"`
+		MovieID string `json:"movie_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	result, err := matcher.Bind(s.data, body.Slug, body.Title, body.MovieID)
+	apiutil.SendSuccessOrError(c, result, err)
+}
+
+// GetAliases lists titles bound to movies.
+func (s *MatchService) GetAliases(c *gin.Context) {
+	query := s.data.DefaultQuery().SetLimit(-1)
+	if movieID := c.Query("movie_id"); movieID != "" {
+		ID, err := primitive.ObjectIDFromHex(movieID)
+		if err != nil {
+			apiutil.SendBadRequest(c)
+			return
+		}
+		query.AddCondition("movie_id", ID)
+	}
+	aliases, err := s.data.GetMovieAliases(query)
+	apiutil.SendSuccessOrError(c, aliases, err)
+}
+
+// DeleteAlias removes an alias, so the title goes through the matcher again.
+func (s *MatchService) DeleteAlias(c *gin.Context) {
+	err := s.data.DeleteMovieAlias(c.Param("id"))
+	apiutil.SendSuccessOrError(c, 1, err)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/dsbezerra/amenic-lambda/src/lib/messagequeue"
 	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/scheduler"
 	"github.com/gin-gonic/gin"
 )
 
@@ -11,15 +12,19 @@ import (
 type Service struct {
 	data    persistence.DataAccessLayer
 	emitter messagequeue.EventEmitter
+	sched   *scheduler.Scheduler
 }
 
 // ServeAPI ...
-func ServeAPI(r *gin.Engine, data persistence.DataAccessLayer, emitter messagequeue.EventEmitter) {
-	s := &Service{data, emitter}
+func ServeAPI(r *gin.Engine, data persistence.DataAccessLayer, emitter messagequeue.EventEmitter, sched *scheduler.Scheduler) {
+	s := &Service{data, emitter, sched}
 
 	// Apply default middlewares
 	r.Use(middlewares.BaseParseQuery())
 
 	// ScraperService routes.
 	s.ServeScrapers(r)
+
+	// MatchService routes.
+	s.ServeMatches(r)
 }
@@ -1,11 +1,22 @@
 package rest
 
 import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/contracts"
 	"github.com/dsbezerra/amenic-lambda/src/lib/messagequeue"
 	"github.com/dsbezerra/amenic-lambda/src/lib/middlewares/rest"
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/apiutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/health"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/metadata"
 	"github.com/dsbezerra/amenic-lambda/src/scraperservice/queue"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/scheduler"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/task"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,14 +24,36 @@ import (
 type ScraperService struct {
 	data    persistence.DataAccessLayer
 	emitter messagequeue.EventEmitter
+	sched   *scheduler.Scheduler
+}
+
+// scheduleBody is the editable schedule of a scraper.
+type scheduleBody struct {
+	Cron    []string           `json:"cron"`
+	Enabled *bool              `json:"enabled"`
+	Windows []models.RunWindow `json:"windows"`
 }
 
 // ServeScrapers ...
 func (rs *Service) ServeScrapers(r *gin.Engine) {
-	s := &ScraperService{rs.data, rs.emitter}
+	s := &ScraperService{rs.data, rs.emitter, rs.sched}
 	scrapers := r.Group("/scrapers", rest.AdminAuth(rs.data))
 	scrapers.GET("", s.GetAll)
-	scrapers.POST("/scraper/:id/run", s.RunScraper)
+	scrapers.GET("/health", s.GetHealth)
+	scrapers.GET("/health/summary", s.GetHealthSummary)
+	scrapers.GET("/scraper/:id/health", s.GetScraperHealth)
+	scrapers.POST("/scraper/:id/preview", s.PreviewScraper)
+	scrapers.GET("/scraper/:id/schedule", s.GetSchedule)
+	scrapers.PUT("/scraper/:id/schedule", s.UpdateSchedule)
+	scrapers.GET("/queue", s.GetQueue)
+	scrapers.GET("/quarantine", s.GetQuarantinedRuns)
+	scrapers.POST("/run/:id/approve", s.ApproveRun)
+	scrapers.POST("/run/:id/discard", s.DiscardRun)
+	scrapers.GET("/metadata/cache", s.GetMetadataCache)
+
+	// Running a scraper only needs the scrapers:run scope.
+	runners := r.Group("/scrapers", rest.ScopeAuth(rs.data, models.ScopeScrapersRun))
+	runners.POST("/scraper/:id/run", s.RunScraper)
 }
 
 // GetAll ...
@@ -29,16 +62,187 @@ func (s *ScraperService) GetAll(c *gin.Context) {
 	apiutil.SendSuccessOrError(c, scrapers, err)
 }
 
+// GetHealth retrieves the health of every scraper.
+func (s *ScraperService) GetHealth(c *gin.Context) {
+	scrapers, err := s.data.GetScrapers(s.data.DefaultQuery().SetLimit(-1))
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	result := make([]health.ScraperHealth, 0, len(scrapers))
+	for _, scraper := range scrapers {
+		h, err := health.GetScraperHealth(s.data, scraper, now)
+		if err != nil {
+			apiutil.HandleError(c, err)
+			return
+		}
+		if c.Query("stale") == "true" && !h.Stale {
+			continue
+		}
+		result = append(result, *h)
+	}
+	apiutil.SendSuccess(c, result)
+}
+
+// GetScraperHealth retrieves the health of a single scraper.
+func (s *ScraperService) GetScraperHealth(c *gin.Context) {
+	scraper, err := s.data.GetScraper(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.HandleError(c, err)
+		return
+	}
+	h, err := health.GetScraperHealth(s.data, *scraper, time.Now().UTC())
+	apiutil.SendSuccessOrError(c, h, err)
+}
+
+// GetHealthSummary flags stale scrapers and theaters with an empty schedule for today.
+func (s *ScraperService) GetHealthSummary(c *gin.Context) {
+	summary, err := health.GetSummary(s.data, time.Now().UTC())
+	apiutil.SendSuccessOrError(c, summary, err)
+}
+
 // RunScraper ...
 func (s *ScraperService) RunScraper(c *gin.Context) {
 	err := queue.AddWork(queue.WorkRequest{
 		ScraperID: c.Param("id"),
+		Priority:  queue.PriorityManual,
 	})
 	apiutil.SendSuccessOrError(c, "Scraper run emitted.", err)
 }
 
+// PreviewScraper runs a scraper without persisting anything and returns
+// what would change in the database.
+func (s *ScraperService) PreviewScraper(c *gin.Context) {
+	preview, err := task.PreviewScraper(s.data, task.ScraperOptions{
+		ScraperID:     c.Param("id"),
+		IgnoreLastRun: c.Query("ignore_last_run") == "true",
+	})
+	apiutil.SendSuccessOrError(c, preview, err)
+}
+
+// GetSchedule retrieves the cron specs, enabled flag and run windows of a scraper.
+func (s *ScraperService) GetSchedule(c *gin.Context) {
+	scraper, err := s.data.GetScraper(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.SendNotFound(c)
+		return
+	}
+	apiutil.SendSuccess(c, scheduleBody{
+		Cron:    scraper.Cron,
+		Enabled: scraper.Enabled,
+		Windows: scraper.Windows,
+	})
+}
+
+// UpdateSchedule replaces the schedule of a scraper and registers it again.
+func (s *ScraperService) UpdateSchedule(c *gin.Context) {
+	body := scheduleBody{}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+
+	scraper, err := s.data.GetScraper(c.Param("id"), s.data.DefaultQuery())
+	if err != nil {
+		apiutil.SendNotFound(c)
+		return
+	}
+	scraper.Cron = body.Cron
+	scraper.Enabled = body.Enabled
+	scraper.Windows = body.Windows
+	if err := scheduler.Validate(*scraper); err != nil {
+		apiutil.SendBadRequest(c)
+		return
+	}
+
+	_, err = s.data.UpdateScraper(scraper.ID.Hex(), *scraper)
+	if err == nil && s.sched != nil {
+		err = s.sched.Register(*scraper)
+	}
+	apiutil.SendSuccessOrError(c, scraper, err)
+}
+
+// GetQueue lists jobs waiting in or being run by the work queue.
+func (s *ScraperService) GetQueue(c *gin.Context) {
+	query := s.data.DefaultQuery().
+		SetSort("-priority", "created_at").
+		SetLimit(-1)
+	jobs, err := s.data.GetScraperJobs(query)
+	apiutil.SendSuccessOrError(c, jobs, err)
+}
+
+// GetQuarantinedRuns lists runs waiting for review.
+func (s *ScraperService) GetQuarantinedRuns(c *gin.Context) {
+	query := s.data.DefaultQuery().
+		AddCondition("quarantine.status", scraperutil.QuarantinePending).
+		SetSort("-start_time").
+		SetLimit(-1)
+	runs, err := s.data.GetScraperRuns(query)
+	apiutil.SendSuccessOrError(c, runs, err)
+}
+
+// ApproveRun persists the data of a quarantined run.
+func (s *ScraperService) ApproveRun(c *gin.Context) {
+	run, err := task.ApproveRun(s.data, c.Param("id"))
+	if err == task.ErrRunNotQuarantined {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	if err == nil {
+		s.emitter.Emit(&contracts.EventScraperFinished{
+			Type:      run.Scraper.Type,
+			ScraperID: run.ScraperID.Hex(),
+		})
+	}
+	apiutil.SendSuccessOrError(c, run, err)
+}
+
+// DiscardRun discards the data of a quarantined run.
+func (s *ScraperService) DiscardRun(c *gin.Context) {
+	run, err := task.DiscardRun(s.data, c.Param("id"))
+	if err == task.ErrRunNotQuarantined {
+		apiutil.SendBadRequest(c)
+		return
+	}
+	apiutil.SendSuccessOrError(c, run, err)
+}
+
 // BuildScraperQuery builds movie query from request query string
 func BuildScraperQuery(data persistence.DataAccessLayer, c *gin.Context) persistence.Query {
 	query := c.MustGet("query_options").(map[string]string)
 	return data.BuildScraperQuery(query)
 }
+
+// GetMetadataCache shows how stale the cached movie metadata is. Use
+// ?tmdb_id= to check the details of a single movie.
+func (s *ScraperService) GetMetadataCache(c *gin.Context) {
+	source := metadata.Default()
+	cache, ok := source.(*metadata.Cache)
+	if !ok {
+		apiutil.SendSuccess(c, metadata.CacheStats{
+			Source:  source.Name(),
+			Entries: make([]metadata.CacheEntry, 0),
+		})
+		return
+	}
+
+	if v := c.Query("tmdb_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			apiutil.SendBadRequest(c)
+			return
+		}
+		entry, err := cache.Entry(metadata.DetailsKey(id))
+		if os.IsNotExist(err) {
+			apiutil.SendNotFound(c)
+			return
+		}
+		apiutil.SendSuccessOrError(c, entry, err)
+		return
+	}
+
+	stats, err := cache.Stats()
+	apiutil.SendSuccessOrError(c, stats, err)
+}
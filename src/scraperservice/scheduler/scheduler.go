@@ -0,0 +1,188 @@
+package scheduler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/queue"
+	"github.com/robfig/cron"
+	"github.com/sirupsen/logrus"
+)
+
+// ReloadInterval is how often scrapers are reloaded from the database to pick
+// up changes made by other services.
+const ReloadInterval = 5 * time.Minute
+
+// Scheduler runs scrapers that have their own cron specs.
+type Scheduler struct {
+	data persistence.DataAccessLayer
+	loc  *time.Location
+	log  *logrus.Entry
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	quit    chan struct{}
+}
+
+type entry struct {
+	key  string // key identifies the schedule, so unchanged scrapers aren't registered again
+	cron *cron.Cron
+}
+
+// New creates a scheduler that runs cron specs in the given location.
+func New(data persistence.DataAccessLayer, loc *time.Location, log *logrus.Entry) *Scheduler {
+	return &Scheduler{
+		data:    data,
+		loc:     loc,
+		log:     log.WithField("component", "scheduler"),
+		entries: make(map[string]*entry),
+		quit:    make(chan struct{}),
+	}
+}
+
+// Start loads every scraper and keeps reloading them until Stop is called.
+func (s *Scheduler) Start() {
+	if err := s.Load(); err != nil {
+		s.log.Errorf("couldn't load scrapers: %s", err.Error())
+	}
+
+	go func() {
+		ticker := time.NewTicker(ReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.quit:
+				return
+			case <-ticker.C:
+				if err := s.Load(); err != nil {
+					s.log.Errorf("couldn't reload scrapers: %s", err.Error())
+				}
+			}
+		}
+	}()
+}
+
+// Stop unregisters every scraper.
+func (s *Scheduler) Stop() {
+	close(s.quit)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, e := range s.entries {
+		e.cron.Stop()
+		delete(s.entries, id)
+	}
+}
+
+// Load registers every scraper with a schedule and unregisters the ones that
+// no longer exist.
+func (s *Scheduler) Load() error {
+	scrapers, err := s.data.GetScrapers(s.data.DefaultQuery().SetLimit(-1))
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(scrapers))
+	for _, scraper := range scrapers {
+		seen[scraper.ID.Hex()] = true
+		if err := s.Register(scraper); err != nil {
+			s.log.Errorf("couldn't register scraper %s: %s", scraper.ID.Hex(), err.Error())
+		}
+	}
+
+	s.mu.Lock()
+	ids := make([]string, 0)
+	for id := range s.entries {
+		if !seen[id] {
+			ids = append(ids, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, id := range ids {
+		s.Unregister(id)
+	}
+	return nil
+}
+
+// Sync reloads the scraper with the given ID and updates its schedule.
+func (s *Scheduler) Sync(id string) error {
+	scraper, err := s.data.GetScraper(id, s.data.DefaultQuery())
+	if err != nil {
+		s.Unregister(id)
+		return err
+	}
+	return s.Register(*scraper)
+}
+
+// Register schedules the scraper with its cron specs, replacing the previous
+// schedule. Disabled scrapers and scrapers without cron specs are unregistered.
+func (s *Scheduler) Register(scraper models.Scraper) error {
+	id := scraper.ID.Hex()
+	if !scraper.IsEnabled() || len(scraper.Cron) == 0 {
+		s.Unregister(id)
+		return nil
+	}
+
+	key := fmt.Sprintf("%v %v", scraper.Cron, scraper.Windows)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[id]; ok && e.key == key {
+		return nil
+	}
+
+	c := cron.NewWithLocation(s.loc)
+	for _, spec := range scraper.Cron {
+		schedule, err := cron.Parse(spec)
+		if err != nil {
+			return err
+		}
+		windows := scraper.Windows
+		c.Schedule(schedule, cron.FuncJob(func() {
+			s.dispatch(id, windows)
+		}))
+	}
+
+	if e, ok := s.entries[id]; ok {
+		e.cron.Stop()
+	}
+	s.entries[id] = &entry{key: key, cron: c}
+	c.Start()
+
+	s.log.Infof("scraper %s scheduled with %v", id, scraper.Cron)
+	return nil
+}
+
+// Unregister removes the schedule of the scraper with the given ID.
+func (s *Scheduler) Unregister(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return
+	}
+	e.cron.Stop()
+	delete(s.entries, id)
+	s.log.Infof("scraper %s unscheduled", id)
+}
+
+func (s *Scheduler) dispatch(id string, windows []models.RunWindow) {
+	if !InRunWindow(windows, time.Now().In(s.loc)) {
+		s.log.Infof("skipped scraper %s: outside of run windows", id)
+		return
+	}
+
+	err := queue.AddWork(queue.WorkRequest{
+		ScraperID: id,
+		Priority:  queue.PriorityCron,
+	})
+	if err != nil {
+		s.log.Errorf("couldn't queue scraper %s: %s", id, err.Error())
+	}
+}
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+)
+
+// ErrInvalidWindow is returned when a run window has a malformed start or end.
+var ErrInvalidWindow = scraperutil.ErrInvalidWindow
+
+// InRunWindow checks whether t is inside any of the given windows. Scrapers
+// without windows can run any time.
+func InRunWindow(windows []models.RunWindow, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	yesterday := t.AddDate(0, 0, -1).Weekday()
+	for _, w := range windows {
+		start, err := scraperutil.ParseClock(w.Start)
+		if err != nil {
+			continue
+		}
+		end, err := scraperutil.ParseClock(w.End)
+		if err != nil {
+			continue
+		}
+
+		if start <= end {
+			if hasWeekday(w.Weekdays, t.Weekday()) && minute >= start && minute < end {
+				return true
+			}
+			continue
+		}
+
+		// The window ends in the next day.
+		if hasWeekday(w.Weekdays, t.Weekday()) && minute >= start {
+			return true
+		}
+		if hasWeekday(w.Weekdays, yesterday) && minute < end {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the cron specs and run windows of the given scraper.
+func Validate(scraper models.Scraper) error {
+	return scraperutil.ValidateSchedule(scraper)
+}
+
+func hasWeekday(weekdays []time.Weekday, day time.Weekday) bool {
+	if len(weekdays) == 0 {
+		return true
+	}
+	for _, w := range weekdays {
+		if w == day {
+			return true
+		}
+	}
+	return false
+}
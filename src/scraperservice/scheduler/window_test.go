@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInRunWindow(t *testing.T) {
+	// 2019-10-31 is a Thursday.
+	at := func(days, hour, minute int) time.Time {
+		return time.Date(2019, time.October, 31+days, hour, minute, 0, 0, time.UTC)
+	}
+
+	assert.True(t, InRunWindow(nil, at(0, 3, 0)))
+
+	windows := []models.RunWindow{
+		{Start: "06:00", End: "09:30"},
+	}
+	assert.True(t, InRunWindow(windows, at(0, 6, 0)))
+	assert.True(t, InRunWindow(windows, at(0, 9, 29)))
+	assert.False(t, InRunWindow(windows, at(0, 9, 30)))
+	assert.False(t, InRunWindow(windows, at(0, 5, 59)))
+
+	// Overnight window only on Thursdays.
+	windows = []models.RunWindow{
+		{Weekdays: []time.Weekday{time.Thursday}, Start: "22:00", End: "02:00"},
+	}
+	assert.True(t, InRunWindow(windows, at(0, 23, 0)))
+	assert.True(t, InRunWindow(windows, at(1, 1, 0)))
+	assert.False(t, InRunWindow(windows, at(1, 23, 0)))
+	assert.False(t, InRunWindow(windows, at(2, 1, 0)))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(models.Scraper{
+		Cron:    []string{"0 0 7 * * *"},
+		Windows: []models.RunWindow{{Start: "06:00", End: "23:59"}},
+	}))
+	assert.Error(t, Validate(models.Scraper{Cron: []string{"every day"}}))
+	assert.Equal(t, ErrInvalidWindow, Validate(models.Scraper{
+		Windows: []models.RunWindow{{Start: "6h", End: "23:59"}},
+	}))
+}
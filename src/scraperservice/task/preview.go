@@ -0,0 +1,217 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence"
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/movieutil"
+	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/extractors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type (
+	// Preview is the result of a dry run.
+	Preview struct {
+		Run      *models.ScraperRun `json:"run"`
+		Movies   []models.Movie     `json:"movies,omitempty"`
+		Sessions []models.Session   `json:"sessions,omitempty"`
+		Prices   []models.Price     `json:"prices,omitempty"`
+		Diff     *PreviewDiff       `json:"diff"`
+	}
+
+	// PreviewDiff compares the extracted data with the data currently in the database.
+	PreviewDiff struct {
+		Added     []string `json:"added"`     // Added lists items that aren't in the database
+		Removed   []string `json:"removed"`   // Removed lists items that would be removed from the database
+		Changed   []string `json:"changed"`   // Changed lists items that would be updated
+		Unchanged int      `json:"unchanged"` // Unchanged is the number of items that are already up to date
+	}
+)
+
+// PreviewScraper runs the scraper without touching the database and returns the
+// extracted data along with what would change if the run was persisted.
+func PreviewScraper(data persistence.DataAccessLayer, opts ScraperOptions) (*Preview, error) {
+	opts.DryRun = true
+	run, err := StartScraper(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Preview{Run: run}
+	if run.Error != "" {
+		return result, nil
+	}
+
+	switch run.Scraper.Type {
+	case scraperutil.TypeNowPlaying, scraperutil.TypeUpcoming:
+		result.Movies = run.Movies
+		result.Diff = diffMovies(data, run.Movies)
+	case scraperutil.TypeSchedule:
+		result.Sessions = run.Sessions
+		result.Diff, err = diffSessions(data, run)
+	case scraperutil.TypePrices:
+		result.Prices = run.Prices
+		result.Diff, err = diffPrices(data, run)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// diffMovies matches the extracted movies against the database without
+// updating it. Matched movies get the ID of the existing movie.
+func diffMovies(data persistence.DataAccessLayer, movies []models.Movie) *PreviewDiff {
+	result := newPreviewDiff()
+	for i := range movies {
+		m := &movies[i]
+		if m.Title == "" {
+			continue
+		}
+		movieutil.FillSlugs(m)
+		found, existing := extractors.FindMovieMatch(data, m)
+		if !found {
+			result.Added = append(result.Added, m.Title)
+			continue
+		}
+		m.ID = existing.ID
+		if update, _ := movieutil.ShouldUpdate(existing, m); update {
+			result.Changed = append(result.Changed, m.Title)
+		} else {
+			result.Unchanged++
+		}
+	}
+	return result
+}
+
+// diffSessions compares the extracted sessions with the ones of the theater
+// the same way the schedule extractor merges them, see MergeSessions.
+func diffSessions(data persistence.DataAccessLayer, run *models.ScraperRun) (*PreviewDiff, error) {
+	start := extractors.WeekStart(run, time.Now())
+	current, err := data.GetSessions(data.DefaultQuery().
+		AddCondition("theaterId", run.Scraper.TheaterID).
+		AddCondition("startTime", bson.M{"$gte": start}).
+		SetLimit(-1))
+	if err != nil {
+		return nil, err
+	}
+	return diffSessionsWith(current, run.Sessions, run.Scraper.Provider), nil
+}
+
+// diffSessionsWith compares the extracted sessions of the provider with the
+// current ones. Sessions other providers still report aren't removed.
+func diffSessionsWith(current, extracted []models.Session, provider string) *PreviewDiff {
+	existing := map[string]models.Session{}
+	for _, s := range current {
+		existing[s.NaturalKey()] = s
+	}
+
+	before := map[string]string{}
+	after := map[string]string{}
+	for _, s := range extracted {
+		key := s.NaturalKey()
+		if old, ok := existing[key]; ok {
+			before[key] = sessionValue(old)
+			// Upserts keep the movie and type providers don't report.
+			if s.MovieID.IsZero() {
+				s.MovieID = old.MovieID
+			}
+			if s.Type == "" {
+				s.Type = old.Type
+			}
+		}
+		after[key] = sessionValue(s)
+	}
+	for key, s := range existing {
+		if _, ok := after[key]; !ok && isOnlyReportedBy(s, provider) {
+			before[key] = sessionValue(s)
+		}
+	}
+	return diff(before, after)
+}
+
+// isOnlyReportedBy checks whether the session is removed once the provider
+// stops reporting it. Sessions without providers are removed by any.
+func isOnlyReportedBy(s models.Session, provider string) bool {
+	for _, p := range s.Providers {
+		if p != provider {
+			return false
+		}
+	}
+	return true
+}
+
+// diffPrices compares the extracted prices with the ones of the theater.
+func diffPrices(data persistence.DataAccessLayer, run *models.ScraperRun) (*PreviewDiff, error) {
+	current, err := data.GetPrices(data.DefaultQuery().
+		AddCondition("theaterId", run.Scraper.TheaterID).
+		SetLimit(-1))
+	if err != nil {
+		return nil, err
+	}
+
+	before := map[string]string{}
+	for _, p := range current {
+		before[priceKey(p)] = priceValue(p)
+	}
+	after := map[string]string{}
+	for _, p := range run.Prices {
+		after[priceKey(p)] = priceValue(p)
+	}
+	return diff(before, after), nil
+}
+
+// diff compares two sets of items indexed by key. Items with the same key
+// but a different value are considered changed.
+func diff(before, after map[string]string) *PreviewDiff {
+	result := newPreviewDiff()
+	for key, value := range after {
+		old, ok := before[key]
+		if !ok {
+			result.Added = append(result.Added, key)
+		} else if old != value {
+			result.Changed = append(result.Changed, key)
+		} else {
+			result.Unchanged++
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			result.Removed = append(result.Removed, key)
+		}
+	}
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+	sort.Strings(result.Changed)
+	return result
+}
+
+func newPreviewDiff() *PreviewDiff {
+	return &PreviewDiff{
+		Added:   make([]string, 0),
+		Removed: make([]string, 0),
+		Changed: make([]string, 0),
+	}
+}
+
+// sessionValue has the fields of a session a provider updates.
+func sessionValue(s models.Session) string {
+	movie := ""
+	if !s.MovieID.IsZero() {
+		movie = s.MovieID.Hex()
+	}
+	return fmt.Sprintf("%s %s %s %s", movie, s.Format, s.Version, s.Type)
+}
+
+func priceKey(p models.Price) string {
+	return fmt.Sprintf("%s %v", p.Label, p.Weekdays)
+}
+
+func priceValue(p models.Price) string {
+	return fmt.Sprintf("%.2f/%.2f", p.Full, p.Half)
+}
@@ -0,0 +1,64 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDiff(t *testing.T) {
+	before := map[string]string{
+		"a": "1",
+		"b": "2",
+		"c": "3",
+	}
+	after := map[string]string{
+		"b": "2",
+		"c": "4",
+		"d": "5",
+	}
+
+	d := diff(before, after)
+	assert.Equal(t, []string{"d"}, d.Added)
+	assert.Equal(t, []string{"a"}, d.Removed)
+	assert.Equal(t, []string{"c"}, d.Changed)
+	assert.Equal(t, 1, d.Unchanged)
+}
+
+func TestDiffSessions(t *testing.T) {
+	start := time.Date(2019, time.November, 1, 21, 0, 0, 0, time.UTC)
+	newSession := func(room uint, slug string, providers ...string) models.Session {
+		return models.Session{
+			Room:        room,
+			StartTime:   &start,
+			MovieSlugs:  models.Slugs{NoDashes: slug},
+			ScrapedSlug: slug,
+			Format:      models.Format2D,
+			Providers:   providers,
+		}
+	}
+
+	// The stored session is reported by both providers.
+	shared := newSession(1, "coringa", "cinemais", "ibicinemas")
+	shared.MovieID = primitive.NewObjectID()
+	mine := newSession(2, "frozen2", "cinemais")
+	theirs := newSession(3, "malevola", "ibicinemas")
+	changed := newSession(4, "exterminador", "cinemais")
+	current := []models.Session{shared, mine, theirs, changed}
+
+	sharedAgain := newSession(1, "coringa")
+	sharedAgain.MovieID = shared.MovieID
+	changedAgain := newSession(4, "exterminador")
+	changedAgain.Type = models.SessionTypePreview
+	added := newSession(5, "doutorsono")
+	extracted := []models.Session{sharedAgain, changedAgain, added}
+
+	d := diffSessionsWith(current, extracted, "cinemais")
+	assert.Equal(t, []string{added.NaturalKey()}, d.Added)
+	assert.Equal(t, []string{mine.NaturalKey()}, d.Removed)
+	assert.Equal(t, []string{changed.NaturalKey()}, d.Changed)
+	assert.Equal(t, 1, d.Unchanged)
+}
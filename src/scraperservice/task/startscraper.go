@@ -9,15 +9,23 @@ import (
 	"github.com/dsbezerra/amenic-lambda/src/lib/persistence/models"
 	"github.com/dsbezerra/amenic-lambda/src/lib/util/scraperutil"
 	"github.com/dsbezerra/amenic-lambda/src/scraperservice/extractors"
+	"github.com/dsbezerra/amenic-lambda/src/scraperservice/guard"
 	"github.com/dsbezerra/amenic-lambda/src/scraperservice/provider"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// ErrRunNotQuarantined is returned when reviewing a run that isn't waiting for review.
+var ErrRunNotQuarantined = errors.New("run is not pending review")
+
 type ScraperOptions struct {
 	ScraperID     string `json:"scraperId"`
 	TheaterID     string `json:"theaterId"`
 	Type          string `json:"type"`
 	Provider      string `json:"provider"`
 	IgnoreLastRun bool   `json:"ignore_last_run"`
+	Attempt       int    `json:"attempt"`  // Attempt is the attempt number, defaults to 1
+	RetryOf       string `json:"retry_of"` // RetryOf is the ID of the original run when retrying
+	DryRun        bool   `json:"dry_run"`  // DryRun runs the scraper without persisting its data or the run
 }
 
 // StartScraper ...
@@ -27,19 +35,26 @@ func StartScraper(data persistence.DataAccessLayer, opts ScraperOptions) (*model
 		return nil, err
 	}
 
+	if opts.Attempt > 1 {
+		run.Attempt = opts.Attempt
+	}
+	if opts.RetryOf != "" {
+		run.RetryOf, _ = primitive.ObjectIDFromHex(opts.RetryOf)
+	}
+
 	scraper := run.Scraper
-	p := provider.NewProvider(data, scraper.Provider, scraper.Theater.InternalID)
-	if p == nil {
-		return nil, errors.New("couldn't run scraper for the specified provider")
+	p, err := provider.NewProvider(data, scraper.Provider, scraper.Theater.InternalID)
+	if err != nil {
+		failRun(run, err)
+		return run, insertRun(data, run, opts)
 	}
 	e := extractors.NewExtractor(data, p, run)
+	if s, ok := e.(*extractors.ScheduleExtractor); ok {
+		s.DryRun = opts.DryRun
+	}
 	err = e.Execute()
 	if err != nil {
-		// Update scraper run with error
-		t := time.Now().UTC()
-		run.CompleteTime = &t
-		run.Error = err.Error()
-		// TODO: Parse error to determine result code
+		failRun(run, err)
 	} else {
 		scraper := run.Scraper
 		run.ExtractedHash = e.ExtractedHash()
@@ -51,13 +66,52 @@ func StartScraper(data persistence.DataAccessLayer, opts ScraperOptions) (*model
 		} else if run.ExtractedCount == 0 {
 			run.ResultCode = scraperutil.RunResultNotFound
 			// TODO: Notify via email that we've found nothing?
+		} else if reasons := guard.Check(run, t); len(reasons) > 0 {
+			// Keep existing data untouched until an admin reviews this run.
+			run.ResultCode = scraperutil.RunResultQuarantined
+			run.Quarantine = &models.RunQuarantine{
+				Status:   scraperutil.QuarantinePending,
+				Reasons:  reasons,
+				Movies:   run.Movies,
+				Sessions: run.Sessions,
+				Prices:   run.Prices,
+			}
 		} else {
 			run.ResultCode = scraperutil.RunResultSuccess
 		}
-		e.Complete()
+		// Empty runs keep existing data untouched too.
+		if run.ResultCode != scraperutil.RunResultQuarantined && run.ResultCode != scraperutil.RunResultNotFound && !opts.DryRun {
+			e.Complete()
+			if run.ResultCode == scraperutil.RunResultSuccess {
+				bumpDataVersion(data)
+			}
+		}
+	}
+
+	return run, insertRun(data, run, opts)
+}
+
+// insertRun persists the run unless it's a dry run.
+func insertRun(data persistence.DataAccessLayer, run *models.ScraperRun, opts ScraperOptions) error {
+	if opts.DryRun {
+		return nil
+	}
+	return data.InsertScraperRun(*run)
+}
+
+// bumpDataVersion tells API clients that their cached responses are stale.
+func bumpDataVersion(data persistence.DataAccessLayer) {
+	if _, err := data.BumpDataVersion(); err != nil {
+		log.Printf("couldn't bump data version: %s", err.Error())
 	}
+}
 
-	return run, data.InsertScraperRun(*run)
+// failRun updates the scraper run with the classified error.
+func failRun(run *models.ScraperRun, err error) {
+	perr := provider.Classify(err)
+	run.Finish()
+	run.Error = perr.Error()
+	run.ResultCode = perr.ResultCode()
 }
 
 // InitScraper ...
@@ -85,7 +139,8 @@ func InitScraper(data persistence.DataAccessLayer, options ScraperOptions) (*mod
 		theaterID = options.TheaterID
 	}
 
-	theater, err = data.GetTheater(theaterID, data.DefaultQuery())
+	// The city has the time zone of the theater sessions.
+	theater, err = data.GetTheater(theaterID, data.DefaultQuery().AddInclude("city"))
 	if err != nil {
 		return nil, err
 	}
@@ -116,3 +171,68 @@ func InitScraper(data persistence.DataAccessLayer, options ScraperOptions) (*mod
 
 	return r, err
 }
+
+// ApproveRun persists the data of a quarantined run as if it was successful.
+func ApproveRun(data persistence.DataAccessLayer, id string) (*models.ScraperRun, error) {
+	run, err := getQuarantinedRun(data, id)
+	if err != nil {
+		return nil, err
+	}
+
+	scraper, err := data.GetScraper(run.ScraperID.Hex(), data.DefaultQuery())
+	if err != nil {
+		return nil, err
+	}
+	run.Scraper = scraper
+	run.Movies = run.Quarantine.Movies
+	run.Sessions = run.Quarantine.Sessions
+	run.Prices = run.Quarantine.Prices
+
+	// Extractors only persist data of successful runs. Complete doesn't use
+	// the provider, so none is needed to persist the stored data.
+	run.ResultCode = scraperutil.RunResultSuccess
+	e := extractors.NewExtractor(data, nil, run)
+	if e == nil {
+		return nil, errors.New("couldn't find an extractor for the scraper type")
+	}
+	e.Complete()
+	bumpDataVersion(data)
+
+	t := time.Now().UTC()
+	run.Quarantine.Status = scraperutil.QuarantineApproved
+	run.Quarantine.ReviewedAt = &t
+	_, err = data.UpdateScraperRun(id, *run)
+	if err != nil {
+		return nil, err
+	}
+
+	// Approved runs become the reference for the next ones.
+	scraper.LastRun = run.ID
+	_, err = data.UpdateScraper(scraper.ID.Hex(), *scraper)
+	return run, err
+}
+
+// DiscardRun marks a quarantined run as discarded, keeping the existing data.
+func DiscardRun(data persistence.DataAccessLayer, id string) (*models.ScraperRun, error) {
+	run, err := getQuarantinedRun(data, id)
+	if err != nil {
+		return nil, err
+	}
+
+	t := time.Now().UTC()
+	run.Quarantine.Status = scraperutil.QuarantineDiscarded
+	run.Quarantine.ReviewedAt = &t
+	_, err = data.UpdateScraperRun(id, *run)
+	return run, err
+}
+
+func getQuarantinedRun(data persistence.DataAccessLayer, id string) (*models.ScraperRun, error) {
+	run, err := data.GetScraperRun(id, data.DefaultQuery())
+	if err != nil {
+		return nil, err
+	}
+	if run.Quarantine == nil || run.Quarantine.Status != scraperutil.QuarantinePending {
+		return nil, ErrRunNotQuarantined
+	}
+	return run, nil
+}